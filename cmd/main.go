@@ -2,20 +2,45 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"go-base/internal/app/router"
+	"go-base/internal/app/service"
+	externalAccount "go-base/internal/app/service/external/account"
 	"go-base/internal/pkg/aws/s3"
 	"go-base/internal/pkg/aws/sqs"
+	"go-base/internal/pkg/cache"
 	"go-base/internal/pkg/config"
 	"go-base/internal/pkg/database"
+	"go-base/internal/pkg/dedupe"
+	"go-base/internal/pkg/health"
 	"go-base/internal/pkg/http/client"
 	"go-base/internal/pkg/logger"
+	"go-base/internal/pkg/postgres"
+	"go-base/internal/pkg/queue"
+	"go-base/internal/pkg/worker"
+
+	"github.com/nats-io/nats.go"
 )
 
+var multipartCleanupWorker *worker.MultipartCleanupWorker
+
+// sqsWorkers are started with the server and drained on shutdown
+var sqsWorkers []*worker.SQSWorker
+
+// registerCheck adds a dependency check to /readyz, critical unless it's in
+// READINESS_NON_CRITICAL
+func registerCheck(checker health.HealthChecker) {
+	health.GetInstance().RegisterCheck(checker, health.CheckOptions{Critical: config.Env.IsCriticalCheck(checker.Name())})
+}
+
 func Setup() {
 	var err error
 
@@ -23,6 +48,20 @@ func Setup() {
 		log.Fatal(err)
 	}
 
+	// first, the managers log from the callbacks of their drivers
+	if err = logger.Setup(config.Env.LogLevel); err != nil {
+		log.Fatal(err)
+	}
+
+	health.SetInstance(health.NewRegistry(health.RegistryConfig{
+		Timeout:  config.Env.ReadinessTimeout,
+		CacheTTL: config.Env.ReadinessCacheTTL,
+	}))
+
+	// dedupeStore handles redelivered messages once, only within this instance unless the
+	// cache is set up
+	var dedupeStore dedupe.Store = dedupe.NewMemoryStore(dedupe.Config{})
+
 	/*
 		if err = cache.GetInstance().Setup(cache.Config{
 			Type:         config.Env.RedisType,
@@ -31,11 +70,14 @@ func Setup() {
 		}); err != nil {
 			log.Fatalf("cache Setup, error:%v", err)
 		}
+		registerCheck(cache.GetInstance())
+		dedupeStore = dedupe.NewCacheStore(cache.GetInstance(), dedupe.Config{})
 	*/
 
 	if err = database.Setup(config.Env.MongoURI); err != nil {
 		log.Fatalf("database Setup, error:%v", err)
 	}
+	registerCheck(database.HealthChecker())
 
 	/*
 		if err = postgres.GetInstance().Setup(postgres.Config{
@@ -51,15 +93,22 @@ func Setup() {
 		}); err != nil {
 			log.Fatalf("postgres Setup, error:%v", err)
 		}
+		registerCheck(postgres.GetInstance())
 	*/
 
-	/*
+	if config.Env.NatsUrl != "" {
+		var streams []nats.StreamConfig
+		for name, subjects := range config.Env.NatsStreamSubjects() {
+			streams = append(streams, nats.StreamConfig{Name: name, Subjects: subjects, Storage: nats.FileStorage})
+		}
 		if err = queue.GetInstance().Setup(queue.Config{
-			Url: config.Env.NatsUrl,
+			Url:     config.Env.NatsUrl,
+			Streams: streams,
 		}); err != nil {
 			log.Fatalf("queue Setup, error:%v", err)
 		}
-	*/
+		registerCheck(queue.GetInstance())
+	}
 
 	/*
 		if err = search.GetInstance().Setup(search.Config{
@@ -70,79 +119,166 @@ func Setup() {
 		}
 	*/
 
-	if err = logger.Setup(config.Env.LogLevel); err != nil {
-		log.Fatal(err)
-	}
-
 	client.Setup()
+	registerCheck(externalAccount.HealthChecker())
 
+	s3.SetBuckets(config.Env.S3Buckets(), config.Env.AWSS3Bucket)
 	if s3API, err := s3.NewBaseS3API(s3.Config{
-		AWSS3Bucket:         config.Env.AWSS3Bucket,
 		AWSS3Region:         config.Env.AWSS3Region,
 		IsEnabledAccelerate: config.Env.IsEnabledAccelerate,
+		Endpoint:            config.Env.S3Endpoint(),
+		UsePathStyle:        config.Env.AWSS3UsePathStyle,
 	}); err != nil {
 		log.Fatalf("aws Setup, error:%v", err)
 	} else {
 		s3.SetInstance(s3API)
 	}
+	registerCheck(s3.HealthChecker(config.Env.AWSS3Bucket))
 
-	if sqsTest, err := sqs.NewBaseManager(sqs.Config{
-		QueueName: config.Env.AWSSQSQueueName,
-		Region:    config.Env.AWSSQSRegion,
+	if sqsRegistry, err := sqs.NewRegistry(sqs.RegistryConfig{
+		Region:     config.Env.AWSSQSRegion,
+		Endpoint:   config.Env.SQSEndpoint(),
+		QueueNames: config.Env.SQSQueueNames(),
 	}); err != nil {
-		log.Fatalf("sqs LodCreated Setup, region: %s, queue name: %s, error:%v", config.Env.AWSSQSRegion, config.Env.AWSSQSQueueName, err)
+		log.Fatalf("sqs Setup, region: %s, error:%v", config.Env.AWSSQSRegion, err)
 	} else {
-		sqs.TestSQS = &sqsTest
+		sqs.SetInstance(sqsRegistry)
+	}
+	registerCheck(sqs.HealthChecker(config.Env.AWSSQSQueueName))
+	defaultQueue, err := sqs.GetInstance().Queue(context.Background(), config.Env.AWSSQSQueueName)
+	if err != nil {
+		log.Fatalf("sqs queue Setup, region: %s, queue name: %s, error:%v", config.Env.AWSSQSRegion, config.Env.AWSSQSQueueName, err)
+	}
+	if defaultWorker, err := worker.NewSQSWorker(worker.SQSWorkerConfig{
+		QueueName:   config.Env.AWSSQSQueueName,
+		Queue:       defaultQueue,
+		Processor:   &worker.DefaultMessageProcessor{},
+		Concurrency: config.Env.AWSSQSWorkerConcurrency,
+		DedupeStore: dedupeStore,
+	}); err != nil {
+		log.Fatalf("sqs worker Setup, queue name: %s, error:%v", config.Env.AWSSQSQueueName, err)
+	} else {
+		sqsWorkers = append(sqsWorkers, defaultWorker)
+	}
+
+	if multipartCleanupWorker, err = worker.NewMultipartCleanupWorker(worker.MultipartCleanupWorkerConfig{
+		BucketNames: s3.Buckets(),
+		Interval:    config.Env.AWSS3MultipartCleanupInterval,
+		MaxAge:      config.Env.AWSS3MultipartUploadMaxAge,
+	}); err != nil {
+		log.Fatalf("multipart cleanup worker Setup, error:%v", err)
+	}
+
+	if config.Env.AWSSQSIconQueueName != "" {
+		var iconDeadLetterQueue sqs.SQSAPI
+		if config.Env.AWSSQSIconDeadLetterQueueName != "" {
+			if iconDeadLetterQueue, err = sqs.GetInstance().Queue(context.Background(), config.Env.AWSSQSIconDeadLetterQueueName); err != nil {
+				log.Fatalf("icon worker Setup, dead-letter queue name: %s, error:%v", config.Env.AWSSQSIconDeadLetterQueueName, err)
+			}
+		}
+		if iconWorker, err := worker.NewSQSWorker(worker.SQSWorkerConfig{
+			QueueName: config.Env.AWSSQSIconQueueName,
+			Processor: service.NewIconProcessor(service.IconProcessorConfig{
+				Sizes:        config.Env.IconVariantSizes,
+				MaxDimension: config.Env.IconMaxDimension,
+				MaxFileSize:  config.Env.IconMaxFileSize,
+			}),
+			WorkerCount:     config.Env.IconWorkerCount,
+			Concurrency:     config.Env.IconWorkerConcurrency,
+			DeadLetterQueue: iconDeadLetterQueue,
+			MaxReceiveCount: config.Env.IconMaxReceiveCount,
+			DedupeStore:     dedupeStore,
+		}); err != nil {
+			log.Fatalf("icon worker Setup, queue name: %s, error:%v", config.Env.AWSSQSIconQueueName, err)
+		} else {
+			sqsWorkers = append(sqsWorkers, iconWorker)
+		}
 	}
 
 	if err = router.Setup(); err != nil {
 		log.Fatal(err)
 	}
+	if config.Env.NatsUrl != "" {
+		if err = router.SetupNats(queue.GetInstance(), queue.DedupeMiddleware(dedupeStore)); err != nil {
+			log.Fatalf("nats router Setup, error:%v", err)
+		}
+	}
 }
 
-func Close() {
+// Close stops accepting requests and messages, drains the ones in progress until ctx is
+// done and closes the connections
+func Close(ctx context.Context, server *http.Server) {
+	multipartCleanupWorker.Stop()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := server.Shutdown(ctx); err != nil {
+			logger.Error.Printf("server Shutdown, error:%v", err)
+		}
+	}()
+	for _, sqsWorker := range sqsWorkers {
+		wg.Add(1)
+		go func(sqsWorker *worker.SQSWorker) {
+			defer wg.Done()
+			if err := sqsWorker.Shutdown(ctx); err != nil {
+				logger.Error.Printf("sqs worker Shutdown, error:%v", err)
+			}
+		}(sqsWorker)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := queue.GetInstance().Close(ctx); err != nil {
+			logger.Error.Printf("queue Close, error:%v", err)
+		}
+	}()
+	wg.Wait()
+
+	if err := database.Close(ctx); err != nil {
+		logger.Error.Printf("database Close, error:%v", err)
+	}
+	if err := cache.GetInstance().Close(); err != nil {
+		logger.Error.Printf("cache Close, error:%v", err)
+	}
+	postgres.GetInstance().Close()
 }
 
-func RunServer() {
+// RunServer starts serving HTTP in the background
+func RunServer() *http.Server {
 	s := &http.Server{
 		Addr:         fmt.Sprintf(":%s", config.Env.Port),
 		Handler:      router.Router,
 		ReadTimeout:  30 * time.Minute,
 		WriteTimeout: 30 * time.Minute,
 	}
-	if err := s.ListenAndServe(); err != nil {
-		log.Fatalf("%s\n", err)
-	}
-}
-
-func receiveMessage() {
-	for {
-		hasMsg, msg, err := sqs.TestSQS.ReceiveMessage(context.TODO(), 20, 30)
-		if err != nil {
-			log.Fatalf("ReceiveMessage, error:%v", err)
-		} else if hasMsg {
-			go func() {
-				logger.Info.Printf("received sqs message: %v", msg)
-
-				defer func() {
-					logger.Info.Printf("delete sqs message: %v", msg)
-					if err := sqs.TestSQS.DeleteMessage(context.TODO(), msg.ReceiptHandle); err != nil {
-						err = fmt.Errorf("fetch lod file failed to delete sqs message: %v", err)
-						logger.Error.Printf(err.Error())
-					}
-				}()
-			}()
-		} else {
-			logger.Info.Printf("no message received")
+	go func() {
+		if err := s.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("%s\n", err)
 		}
-	}
+	}()
+	return s
 }
 
 // @title        Community Service Swagger
 // @description  this service is Community Service
 func main() {
 	Setup()
-	defer Close()
-	go receiveMessage()
-	RunServer()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	multipartCleanupWorker.Start(context.Background())
+	for _, sqsWorker := range sqsWorkers {
+		sqsWorker.Start(context.Background())
+	}
+	server := RunServer()
+
+	<-ctx.Done()
+	logger.Info.Printf("shutting down, draining requests and messages for up to %s", config.Env.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.Env.ShutdownTimeout)
+	defer cancel()
+	Close(shutdownCtx, server)
+	logger.Info.Printf("shutdown complete")
 }
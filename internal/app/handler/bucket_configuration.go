@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator"
+
+	"go-base/internal/app/service"
+	"go-base/internal/pkg/logger"
+	model "go-base/internal/pkg/model"
+	modelHttp "go-base/internal/pkg/model/http"
+)
+
+func GetBucketLifecycleHandler(c *gin.Context) {
+	var request modelHttp.GetBucketLifecycleRequest
+
+	if err := c.ShouldBindQuery(&request); err != nil {
+		logger.Error.Printf("Failed to bind request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		logger.Error.Printf("Failed to validate request: %v", err)
+		result(c, nil, model.ServiceError.BadRequestError("Validation failed: "+err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, serviceResp := service.GetBucketLifecycle(ctx, request)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to get bucket lifecycle: %v", serviceResp.ErrCode)
+		result(c, nil, serviceResp)
+		return
+	}
+
+	result(c, response, serviceResp)
+}
+
+func PutBucketLifecycleHandler(c *gin.Context) {
+	var request modelHttp.PutBucketLifecycleRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logger.Error.Printf("Failed to bind request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		logger.Error.Printf("Failed to validate request: %v", err)
+		result(c, nil, model.ServiceError.BadRequestError("Validation failed: "+err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, serviceResp := service.PutBucketLifecycle(ctx, request)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to put bucket lifecycle: %v", serviceResp.ErrCode)
+		result(c, nil, serviceResp)
+		return
+	}
+
+	result(c, response, serviceResp)
+}
+
+func GetBucketCORSHandler(c *gin.Context) {
+	var request modelHttp.GetBucketCORSRequest
+
+	if err := c.ShouldBindQuery(&request); err != nil {
+		logger.Error.Printf("Failed to bind request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		logger.Error.Printf("Failed to validate request: %v", err)
+		result(c, nil, model.ServiceError.BadRequestError("Validation failed: "+err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, serviceResp := service.GetBucketCORS(ctx, request)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to get bucket CORS: %v", serviceResp.ErrCode)
+		result(c, nil, serviceResp)
+		return
+	}
+
+	result(c, response, serviceResp)
+}
+
+func PutBucketCORSHandler(c *gin.Context) {
+	var request modelHttp.PutBucketCORSRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logger.Error.Printf("Failed to bind request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		logger.Error.Printf("Failed to validate request: %v", err)
+		result(c, nil, model.ServiceError.BadRequestError("Validation failed: "+err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, serviceResp := service.PutBucketCORS(ctx, request)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to put bucket CORS: %v", serviceResp.ErrCode)
+		result(c, nil, serviceResp)
+		return
+	}
+
+	result(c, response, serviceResp)
+}
+
+func GetPublicAccessBlockHandler(c *gin.Context) {
+	var request modelHttp.GetPublicAccessBlockRequest
+
+	if err := c.ShouldBindQuery(&request); err != nil {
+		logger.Error.Printf("Failed to bind request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		logger.Error.Printf("Failed to validate request: %v", err)
+		result(c, nil, model.ServiceError.BadRequestError("Validation failed: "+err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, serviceResp := service.GetPublicAccessBlock(ctx, request)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to get public access block: %v", serviceResp.ErrCode)
+		result(c, nil, serviceResp)
+		return
+	}
+
+	result(c, response, serviceResp)
+}
+
+func PutPublicAccessBlockHandler(c *gin.Context) {
+	var request modelHttp.PutPublicAccessBlockRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logger.Error.Printf("Failed to bind request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		logger.Error.Printf("Failed to validate request: %v", err)
+		result(c, nil, model.ServiceError.BadRequestError("Validation failed: "+err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, serviceResp := service.PutPublicAccessBlock(ctx, request)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to put public access block: %v", serviceResp.ErrCode)
+		result(c, nil, serviceResp)
+		return
+	}
+
+	result(c, response, serviceResp)
+}
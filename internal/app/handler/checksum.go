@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator"
+
+	"go-base/internal/app/service"
+	"go-base/internal/pkg/logger"
+	model "go-base/internal/pkg/model"
+	modelHttp "go-base/internal/pkg/model/http"
+)
+
+func VerifyObjectHandler(c *gin.Context) {
+	var request modelHttp.VerifyObjectRequest
+
+	if err := c.ShouldBindQuery(&request); err != nil {
+		logger.Error.Printf("Failed to bind request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		logger.Error.Printf("Failed to validate request: %v", err)
+		result(c, nil, model.ServiceError.BadRequestError("Validation failed: "+err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, serviceResp := service.VerifyObject(ctx, request)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to verify object: %v", serviceResp.ErrCode)
+		result(c, nil, serviceResp)
+		return
+	}
+
+	result(c, response, serviceResp)
+}
@@ -5,6 +5,7 @@ import (
 	"net/url"
 
 	"go-base/internal/pkg/config"
+	"go-base/internal/pkg/health"
 	"go-base/internal/pkg/logger"
 	"go-base/internal/pkg/model"
 	"go-base/internal/pkg/util"
@@ -20,6 +21,31 @@ func HealthHandler(c *gin.Context) {
 	c.String(http.StatusOK, "ok")
 }
 
+// LivezHandler is liveness probe API, ok while the process serves requests
+// @Tags     Default
+// @Success  200  {string}  string  "ok"
+// @Router   /livez [get]
+func LivezHandler(c *gin.Context) {
+	c.String(http.StatusOK, "ok")
+}
+
+// ReadyzHandler is readiness probe API, it checks the dependencies and fails when a
+// critical one is not up
+// @Tags     Default
+// @Produce  json
+// @Success  200  {object}  health.Report
+// @Failure  503  {object}  health.Report
+// @Router   /readyz [get]
+func ReadyzHandler(c *gin.Context) {
+	report := health.GetInstance().Check(c.Request.Context())
+	if !report.Healthy {
+		logger.Warn.Printf("status=%+v, resp=%+v\n", http.StatusServiceUnavailable, util.StructToJsonString(report))
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
 // VersionHandler is version checker API
 // @Tags     Default
 // @Success  200  {string}  string  "0.4.12"
@@ -64,6 +90,10 @@ func result(c *gin.Context, data interface{}, err model.ServiceResp) {
 		logger.Info.Printf("status=%+v, resp=%+v\n", http.StatusNotFound, util.StructToJsonString(err.ErrCode))
 		c.JSON(http.StatusNotFound, err.ErrCode)
 
+	case http.StatusRequestedRangeNotSatisfiable:
+		logger.Info.Printf("status=%+v, resp=%+v\n", http.StatusRequestedRangeNotSatisfiable, util.StructToJsonString(err.ErrCode))
+		c.JSON(http.StatusRequestedRangeNotSatisfiable, err.ErrCode)
+
 	case http.StatusFailedDependency:
 		logger.Info.Printf("status=%+v, resp=%+v\n", http.StatusFailedDependency, util.StructToJsonString(err.ErrCode))
 		c.JSON(http.StatusFailedDependency, err.ErrCode)
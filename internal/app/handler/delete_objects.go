@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator"
+
+	"go-base/internal/app/service"
+	"go-base/internal/pkg/logger"
+	model "go-base/internal/pkg/model"
+	modelHttp "go-base/internal/pkg/model/http"
+)
+
+func DeleteObjectsByPrefixHandler(c *gin.Context) {
+	var request modelHttp.DeleteObjectsByPrefixRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logger.Error.Printf("Failed to bind request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		logger.Error.Printf("Failed to validate request: %v", err)
+		result(c, nil, model.ServiceError.BadRequestError("Validation failed: "+err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, serviceResp := service.DeleteObjectsByPrefix(ctx, request)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to delete objects by prefix: %v", serviceResp.ErrCode)
+		result(c, nil, serviceResp)
+		return
+	}
+
+	// Large prefixes are deleted in the background, poll /s3/jobs/:job_id for the outcome
+	if response.JobID != "" {
+		c.JSON(http.StatusAccepted, response)
+		return
+	}
+
+	result(c, response, serviceResp)
+}
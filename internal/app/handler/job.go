@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"go-base/internal/app/service"
+	"go-base/internal/pkg/logger"
+	modelHttp "go-base/internal/pkg/model/http"
+)
+
+func GetJobHandler(c *gin.Context) {
+	var request modelHttp.GetJobRequest
+
+	if err := c.ShouldBindUri(&request); err != nil {
+		logger.Error.Printf("Failed to bind request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, serviceResp := service.GetJob(ctx, request)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to get job: %v", serviceResp.ErrCode)
+		result(c, nil, serviceResp)
+		return
+	}
+
+	result(c, response, serviceResp)
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator"
+
+	"go-base/internal/app/service"
+	"go-base/internal/pkg/logger"
+	model "go-base/internal/pkg/model"
+	modelHttp "go-base/internal/pkg/model/http"
+)
+
+func CreateMultipartUploadHandler(c *gin.Context) {
+	var request modelHttp.CreateMultipartUploadRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logger.Error.Printf("Failed to bind request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		logger.Error.Printf("Failed to validate request: %v", err)
+		result(c, nil, model.ServiceError.BadRequestError("Validation failed: "+err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, serviceResp := service.CreateMultipartUpload(ctx, request)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to create multipart upload: %v", serviceResp.ErrCode)
+		result(c, nil, serviceResp)
+		return
+	}
+
+	result(c, response, serviceResp)
+}
+
+func PresignUploadPartsHandler(c *gin.Context) {
+	var request modelHttp.PresignUploadPartsRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logger.Error.Printf("Failed to bind request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		logger.Error.Printf("Failed to validate request: %v", err)
+		result(c, nil, model.ServiceError.BadRequestError("Validation failed: "+err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, serviceResp := service.PresignUploadParts(ctx, request)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to presign upload parts: %v", serviceResp.ErrCode)
+		result(c, nil, serviceResp)
+		return
+	}
+
+	result(c, response, serviceResp)
+}
+
+func ListPartsHandler(c *gin.Context) {
+	var request modelHttp.ListPartsRequest
+
+	if err := c.ShouldBindQuery(&request); err != nil {
+		logger.Error.Printf("Failed to bind request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		logger.Error.Printf("Failed to validate request: %v", err)
+		result(c, nil, model.ServiceError.BadRequestError("Validation failed: "+err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, serviceResp := service.ListParts(ctx, request)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to list parts: %v", serviceResp.ErrCode)
+		result(c, nil, serviceResp)
+		return
+	}
+
+	result(c, response, serviceResp)
+}
+
+func CompleteMultipartUploadHandler(c *gin.Context) {
+	var request modelHttp.CompleteMultipartUploadRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logger.Error.Printf("Failed to bind request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		logger.Error.Printf("Failed to validate request: %v", err)
+		result(c, nil, model.ServiceError.BadRequestError("Validation failed: "+err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, serviceResp := service.CompleteMultipartUpload(ctx, request)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to complete multipart upload: %v", serviceResp.ErrCode)
+		result(c, nil, serviceResp)
+		return
+	}
+
+	result(c, response, serviceResp)
+}
+
+func AbortMultipartUploadHandler(c *gin.Context) {
+	var request modelHttp.AbortMultipartUploadRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logger.Error.Printf("Failed to bind request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		logger.Error.Printf("Failed to validate request: %v", err)
+		result(c, nil, model.ServiceError.BadRequestError("Validation failed: "+err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, serviceResp := service.AbortMultipartUpload(ctx, request)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to abort multipart upload: %v", serviceResp.ErrCode)
+		result(c, nil, serviceResp)
+		return
+	}
+
+	result(c, response, serviceResp)
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator"
+
+	"go-base/internal/app/service"
+	"go-base/internal/pkg/logger"
+	model "go-base/internal/pkg/model"
+	modelHttp "go-base/internal/pkg/model/http"
+)
+
+func GetObjectTaggingHandler(c *gin.Context) {
+	var request modelHttp.GetObjectTaggingRequest
+
+	if err := c.ShouldBindQuery(&request); err != nil {
+		logger.Error.Printf("Failed to bind request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		logger.Error.Printf("Failed to validate request: %v", err)
+		result(c, nil, model.ServiceError.BadRequestError("Validation failed: "+err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, serviceResp := service.GetObjectTagging(ctx, request)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to get object tagging: %v", serviceResp.ErrCode)
+		result(c, nil, serviceResp)
+		return
+	}
+
+	result(c, response, serviceResp)
+}
+
+func PutObjectTaggingHandler(c *gin.Context) {
+	var request modelHttp.PutObjectTaggingRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logger.Error.Printf("Failed to bind request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		logger.Error.Printf("Failed to validate request: %v", err)
+		result(c, nil, model.ServiceError.BadRequestError("Validation failed: "+err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, serviceResp := service.PutObjectTagging(ctx, request)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to put object tagging: %v", serviceResp.ErrCode)
+		result(c, nil, serviceResp)
+		return
+	}
+
+	result(c, response, serviceResp)
+}
+
+func DeleteObjectTaggingHandler(c *gin.Context) {
+	var request modelHttp.DeleteObjectTaggingRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logger.Error.Printf("Failed to bind request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		logger.Error.Printf("Failed to validate request: %v", err)
+		result(c, nil, model.ServiceError.BadRequestError("Validation failed: "+err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, serviceResp := service.DeleteObjectTagging(ctx, request)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to delete object tagging: %v", serviceResp.ErrCode)
+		result(c, nil, serviceResp)
+		return
+	}
+
+	result(c, response, serviceResp)
+}
+
+func ReplaceObjectMetadataHandler(c *gin.Context) {
+	var request modelHttp.ReplaceObjectMetadataRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logger.Error.Printf("Failed to bind request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		logger.Error.Printf("Failed to validate request: %v", err)
+		result(c, nil, model.ServiceError.BadRequestError("Validation failed: "+err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, serviceResp := service.ReplaceObjectMetadata(ctx, request)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to replace object metadata: %v", serviceResp.ErrCode)
+		result(c, nil, serviceResp)
+		return
+	}
+
+	result(c, response, serviceResp)
+}
@@ -40,6 +40,33 @@ func GetIconPresignedURLHandler(c *gin.Context) {
 	result(c, response, serviceResp)
 }
 
+func GetPresignedPostHandler(c *gin.Context) {
+	var request modelHttp.GetPresignedPostRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logger.Error.Printf("Failed to bind request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		logger.Error.Printf("Failed to validate request: %v", err)
+		result(c, nil, model.ServiceError.BadRequestError("Validation failed: "+err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, serviceResp := service.GetPresignedPost(ctx, request)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to get presigned post: %v", serviceResp.ErrCode)
+		result(c, nil, serviceResp)
+		return
+	}
+
+	result(c, response, serviceResp)
+}
+
 func GetIconHeadObjectHandler(c *gin.Context) {
 	var request modelHttp.GetIconHeadObjectRequest
 
@@ -99,8 +126,7 @@ func GetIconCheckObjectExistsHandler(c *gin.Context) {
 func GetIconDeleteObjectsHandler(c *gin.Context) {
 	var request modelHttp.GetIconDeleteObjectsRequest
 
-	// 使用查詢參數
-	if err := c.ShouldBindQuery(&request); err != nil {
+	if err := c.ShouldBindJSON(&request); err != nil {
 		logger.Error.Printf("Failed to bind request: %v", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"mime"
 	"net/http"
+	"path"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator"
@@ -100,6 +102,10 @@ func UploadFileHandler(c *gin.Context) {
 		return
 	}
 
+	if request.ChecksumSHA256 == "" {
+		request.ChecksumSHA256 = c.GetHeader("x-amz-checksum-sha256")
+	}
+
 	ctx := c.Request.Context()
 	response, serviceResp := service.UploadFile(ctx, request)
 	if serviceResp.Status != http.StatusOK {
@@ -128,6 +134,10 @@ func UploadLargeObjectHandler(c *gin.Context) {
 		return
 	}
 
+	if request.ChecksumSHA256 == "" {
+		request.ChecksumSHA256 = c.GetHeader("x-amz-checksum-sha256")
+	}
+
 	ctx := c.Request.Context()
 	response, serviceResp := service.UploadLargeObject(ctx, request)
 	if serviceResp.Status != http.StatusOK {
@@ -168,6 +178,7 @@ func DownloadFileHandler(c *gin.Context) {
 }
 
 // 7. DownloadLargeObject Handler
+// Streams the object body as-is, honouring Range and If-None-Match.
 func DownloadLargeObjectHandler(c *gin.Context) {
 	var request modelHttp.DownloadLargeObjectRequest
 
@@ -183,16 +194,55 @@ func DownloadLargeObjectHandler(c *gin.Context) {
 		result(c, nil, model.ServiceError.BadRequestError("Validation failed: "+err.Error()))
 		return
 	}
+	request.Range = c.GetHeader("Range")
+	request.IfNoneMatch = c.GetHeader("If-None-Match")
 
 	ctx := c.Request.Context()
-	response, serviceResp := service.DownloadLargeObject(ctx, request)
+	stream, serviceResp := service.DownloadLargeObject(ctx, request)
+	if serviceResp.Status == http.StatusNotModified {
+		if stream != nil && stream.ETag != "" {
+			c.Header("ETag", stream.ETag)
+		}
+		c.Status(http.StatusNotModified)
+		return
+	}
 	if serviceResp.Status != http.StatusOK {
 		logger.Error.Printf("Failed to download large object: %v", serviceResp.ErrCode)
 		result(c, nil, serviceResp)
 		return
 	}
+	defer stream.Body.Close()
 
-	result(c, response, serviceResp)
+	fileName := request.FileName
+	if fileName == "" {
+		fileName = path.Base(request.ObjectKey)
+	}
+
+	status := http.StatusOK
+	headers := map[string]string{
+		"Accept-Ranges":       "bytes",
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": fileName}),
+	}
+	if stream.ETag != "" {
+		headers["ETag"] = stream.ETag
+	}
+	if stream.VersionID != "" {
+		headers["X-Amz-Version-Id"] = stream.VersionID
+	}
+	if !stream.LastModified.IsZero() {
+		headers["Last-Modified"] = stream.LastModified.UTC().Format(http.TimeFormat)
+	}
+	if stream.ContentRange != "" {
+		status = http.StatusPartialContent
+		headers["Content-Range"] = stream.ContentRange
+	}
+	// The body is verified while it streams, a mismatch cuts the response short of its
+	// Content-Length. Clients can check the header themselves as well.
+	if stream.ChecksumSHA256 != "" {
+		headers["x-amz-checksum-sha256"] = stream.ChecksumSHA256
+	}
+
+	c.DataFromReader(status, stream.ContentLength, stream.ContentType, stream.Body, headers)
 }
 
 // 8. CopyToFolder Handler
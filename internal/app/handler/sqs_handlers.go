@@ -85,3 +85,113 @@ func SendMessageHandler(c *gin.Context) {
 
 	result(c, response, serviceResp)
 }
+
+// ListDeadLetterMessagesHandler handles inspecting the messages of a dead-letter queue
+// @Summary Inspect a dead-letter queue
+// @Description List messages of a configured dead-letter queue with the failure that moved them, without hiding them
+// @Tags SQS
+// @Produce json
+// @Param queue_name query string true "Dead-letter queue name"
+// @Param max_messages query int false "Messages to return, 1-10"
+// @Success 200 {object} modelHttp.ListDeadLetterMessagesResponse
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /sqs/dlq/messages [get]
+func ListDeadLetterMessagesHandler(c *gin.Context) {
+	var request modelHttp.ListDeadLetterMessagesRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		logger.Error.Printf("Failed to bind request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		logger.Error.Printf("Failed to validate request: %v", err)
+		result(c, nil, model.ServiceError.BadRequestError("Validation failed: "+err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, serviceResp := service.ListDeadLetterMessages(ctx, request)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to list dead-letter messages: %v", serviceResp.ErrCode)
+		result(c, nil, serviceResp)
+		return
+	}
+
+	result(c, response, serviceResp)
+}
+
+// RedriveDeadLetterMessagesHandler handles sending dead-lettered messages back to their source queue
+// @Summary Redrive a dead-letter queue
+// @Description Send the messages of a dead-letter queue matching the filters back to the queue they failed in
+// @Tags SQS
+// @Accept json
+// @Produce json
+// @Param request body modelHttp.RedriveDeadLetterMessagesRequest true "Redrive request"
+// @Success 200 {object} modelHttp.RedriveDeadLetterMessagesResponse
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /sqs/dlq/redrive [post]
+func RedriveDeadLetterMessagesHandler(c *gin.Context) {
+	var request modelHttp.RedriveDeadLetterMessagesRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logger.Error.Printf("Failed to bind request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		logger.Error.Printf("Failed to validate request: %v", err)
+		result(c, nil, model.ServiceError.BadRequestError("Validation failed: "+err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, serviceResp := service.RedriveDeadLetterMessages(ctx, request)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to redrive dead-letter messages: %v", serviceResp.ErrCode)
+		result(c, nil, serviceResp)
+		return
+	}
+
+	result(c, response, serviceResp)
+}
+
+// PurgeDeadLetterQueueHandler handles deleting every message of a dead-letter queue
+// @Summary Purge a dead-letter queue
+// @Description Delete every message of a configured dead-letter queue
+// @Tags SQS
+// @Produce json
+// @Param queue_name query string true "Dead-letter queue name"
+// @Success 200 {object} modelHttp.PurgeDeadLetterQueueResponse
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /sqs/dlq/purge [delete]
+func PurgeDeadLetterQueueHandler(c *gin.Context) {
+	var request modelHttp.PurgeDeadLetterQueueRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		logger.Error.Printf("Failed to bind request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		logger.Error.Printf("Failed to validate request: %v", err)
+		result(c, nil, model.ServiceError.BadRequestError("Validation failed: "+err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, serviceResp := service.PurgeDeadLetterQueue(ctx, request)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to purge dead-letter queue: %v", serviceResp.ErrCode)
+		result(c, nil, serviceResp)
+		return
+	}
+
+	result(c, response, serviceResp)
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin/binding"
+
+	"go-base/internal/app/service"
+	"go-base/internal/pkg/logger"
+	model "go-base/internal/pkg/model"
+	modelDB "go-base/internal/pkg/model/db"
+	modelHttp "go-base/internal/pkg/model/http"
+	"go-base/internal/pkg/queue"
+)
+
+// Subjects of the todo requests over NATS, answered with queue.Reply
+const (
+	TodoCreateSubject = "todo.create"
+	TodoListSubject   = "todo.list"
+	TodoGetSubject    = "todo.get"
+	TodoUpdateSubject = "todo.update"
+	TodoDeleteSubject = "todo.delete"
+)
+
+// natsValidate validates NATS requests with gin's binding validator, the one that validates
+// the same requests over HTTP, so both share its version, tag and registered validations
+func natsValidate(request interface{}) error {
+	if err := binding.Validator.ValidateStruct(request); err != nil {
+		logger.Error.Printf("Failed to validate request: %v", err)
+		return replyError(model.ServiceError.BadRequestError("Validation failed: " + err.Error()))
+	}
+	return nil
+}
+
+// replyError turns a failed service response into the error of a NATS reply
+func replyError(serviceResp model.ServiceResp) error {
+	return &queue.ReplyError{Status: serviceResp.Status, Code: serviceResp.ErrCode.Code}
+}
+
+// CreateTodoNatsHandler is CreateTodoHandler over NATS
+func CreateTodoNatsHandler(ctx context.Context, request modelHttp.CreateTodoRequest) (*modelDB.Todo, error) {
+	if err := natsValidate(request); err != nil {
+		return nil, err
+	}
+
+	todo, serviceResp := service.CreateTodo(ctx, request)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to create todo: %v", serviceResp.ErrCode)
+		return nil, replyError(serviceResp)
+	}
+
+	return todo, nil
+}
+
+// GetAllTodoNatsHandler is GetAllTodoHandler over NATS, the request is empty
+func GetAllTodoNatsHandler(ctx context.Context, request struct{}) ([]modelDB.Todo, error) {
+	todo, serviceResp := service.GetAllTodo(ctx)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to get all todo: %v", serviceResp.ErrCode)
+		return nil, replyError(serviceResp)
+	}
+
+	return todo, nil
+}
+
+// GetTodoNatsHandler is GetTodoHandler over NATS
+func GetTodoNatsHandler(ctx context.Context, request modelHttp.TodoIDRequest) (modelDB.Todo, error) {
+	if err := natsValidate(request); err != nil {
+		return modelDB.Todo{}, err
+	}
+
+	todo, serviceResp := service.GetTodo(ctx, request.ID)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to get todo: %v", serviceResp.ErrCode)
+		return modelDB.Todo{}, replyError(serviceResp)
+	}
+
+	return todo, nil
+}
+
+// UpdateTodoNatsHandler is UpdateTodoHandler over NATS, the reply has no data
+func UpdateTodoNatsHandler(ctx context.Context, request modelHttp.UpdateTodoByIDRequest) (interface{}, error) {
+	if err := natsValidate(request); err != nil {
+		return nil, err
+	}
+
+	serviceResp := service.UpdateTodo(ctx, request.ID, request.UpdateTodoRequest)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to update todo: %v", serviceResp.ErrCode)
+		return nil, replyError(serviceResp)
+	}
+
+	return nil, nil
+}
+
+// DeleteTodoNatsHandler is DeleteTodoHandler over NATS, the reply has no data
+func DeleteTodoNatsHandler(ctx context.Context, request modelHttp.TodoIDRequest) (interface{}, error) {
+	if err := natsValidate(request); err != nil {
+		return nil, err
+	}
+
+	serviceResp := service.DeleteTodo(ctx, request.ID)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to delete todo: %v", serviceResp.ErrCode)
+		return nil, replyError(serviceResp)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator"
+
+	"go-base/internal/app/service"
+	"go-base/internal/pkg/logger"
+	model "go-base/internal/pkg/model"
+	modelHttp "go-base/internal/pkg/model/http"
+)
+
+func CopyObjectsHandler(c *gin.Context) {
+	transferObjectsHandler(c, "copy objects", service.CopyObjects)
+}
+
+func MoveObjectsHandler(c *gin.Context) {
+	transferObjectsHandler(c, "move objects", service.MoveObjects)
+}
+
+func SyncObjectsHandler(c *gin.Context) {
+	transferObjectsHandler(c, "sync objects", service.SyncObjects)
+}
+
+// transferObjectsHandler starts a transfer job and answers 202 with its job_id, the outcome
+// is read from /s3/jobs/:job_id
+func transferObjectsHandler(c *gin.Context, operation string, start func(context.Context, modelHttp.TransferObjectsRequest) (modelHttp.TransferObjectsResponse, model.ServiceResp)) {
+	var request modelHttp.TransferObjectsRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logger.Error.Printf("Failed to bind request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		logger.Error.Printf("Failed to validate request: %v", err)
+		result(c, nil, model.ServiceError.BadRequestError("Validation failed: "+err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, serviceResp := start(ctx, request)
+	if serviceResp.Status != http.StatusOK {
+		logger.Error.Printf("Failed to %s: %v", operation, serviceResp.ErrCode)
+		result(c, nil, serviceResp)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response)
+}
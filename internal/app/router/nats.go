@@ -0,0 +1,28 @@
+package router
+
+import (
+	"go-base/internal/app/handler"
+	"go-base/internal/pkg/queue"
+)
+
+// natsQueueGroup load balances the requests between the instances of this service
+const natsQueueGroup = "todo"
+
+// SetupNats answers the todo requests sent over NATS, alongside the HTTP routes, through
+// middleware
+func SetupNats(manager *queue.Manager, middleware ...queue.Middleware) error {
+	subjects := map[string]queue.MsgHandler{
+		handler.TodoCreateSubject: queue.Respond(handler.CreateTodoNatsHandler),
+		handler.TodoListSubject:   queue.Respond(handler.GetAllTodoNatsHandler),
+		handler.TodoGetSubject:    queue.Respond(handler.GetTodoNatsHandler),
+		handler.TodoUpdateSubject: queue.Respond(handler.UpdateTodoNatsHandler),
+		handler.TodoDeleteSubject: queue.Respond(handler.DeleteTodoNatsHandler),
+	}
+	for subject, msgHandler := range subjects {
+		if _, err := manager.QueueSubscribeHandler(subject, natsQueueGroup, msgHandler, middleware...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
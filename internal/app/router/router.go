@@ -23,6 +23,8 @@ func SetupRouter() (router *gin.Engine) {
 	router.Use(handler.CORSMiddleware(), handler.ErrorMiddleware())
 
 	router.GET("/health", handler.HealthHandler)
+	router.GET("/livez", handler.LivezHandler)
+	router.GET("/readyz", handler.ReadyzHandler)
 	router.GET("/version", handler.VersionHandler)
 
 	// Todo routes
@@ -39,9 +41,10 @@ func SetupRouter() (router *gin.Engine) {
 	iconRoutes := router.Group("/s3")
 	{
 		iconRoutes.GET("/presigned-url", handler.GetIconPresignedURLHandler)
+		iconRoutes.POST("/presigned-post", handler.GetPresignedPostHandler)
 		iconRoutes.GET("/head-object", handler.GetIconHeadObjectHandler)
 		iconRoutes.GET("/check-object-exists", handler.GetIconCheckObjectExistsHandler)
-		iconRoutes.GET("/delete-objects", handler.GetIconDeleteObjectsHandler)
+		iconRoutes.DELETE("/delete-objects", handler.GetIconDeleteObjectsHandler)
 
 		// New S3 APIs
 		iconRoutes.GET("/list-buckets", handler.ListBucketsHandler)
@@ -50,12 +53,44 @@ func SetupRouter() (router *gin.Engine) {
 		iconRoutes.POST("/upload-file", handler.UploadFileHandler)
 		iconRoutes.POST("/upload-large-object", handler.UploadLargeObjectHandler)
 		iconRoutes.GET("/download-file", handler.DownloadFileHandler)
+		iconRoutes.GET("/verify", handler.VerifyObjectHandler)
 		iconRoutes.GET("/download-large-object", handler.DownloadLargeObjectHandler)
 		iconRoutes.POST("/copy-to-folder", handler.CopyToFolderHandler)
 		iconRoutes.POST("/copy-to-bucket", handler.CopyToBucketHandler)
 		iconRoutes.GET("/list-objects", handler.ListObjectsHandler)
 		iconRoutes.DELETE("/delete-objects-from-bucket", handler.DeleteObjectsFromBucketHandler)
+		iconRoutes.DELETE("/delete-objects-by-prefix", handler.DeleteObjectsByPrefixHandler)
+		iconRoutes.POST("/copy-objects", handler.CopyObjectsHandler)
+		iconRoutes.POST("/move-objects", handler.MoveObjectsHandler)
+		iconRoutes.POST("/sync-objects", handler.SyncObjectsHandler)
+		iconRoutes.GET("/jobs/:job_id", handler.GetJobHandler)
 		iconRoutes.DELETE("/delete-bucket", handler.DeleteBucketHandler)
+
+		// Multipart upload through presigned part URLs
+		iconRoutes.POST("/create-multipart-upload", handler.CreateMultipartUploadHandler)
+		iconRoutes.POST("/presign-upload-parts", handler.PresignUploadPartsHandler)
+		iconRoutes.GET("/list-parts", handler.ListPartsHandler)
+		iconRoutes.POST("/complete-multipart-upload", handler.CompleteMultipartUploadHandler)
+		iconRoutes.DELETE("/abort-multipart-upload", handler.AbortMultipartUploadHandler)
+
+		// Tagging and metadata
+		iconRoutes.GET("/get-object-tagging", handler.GetObjectTaggingHandler)
+		iconRoutes.PUT("/put-object-tagging", handler.PutObjectTaggingHandler)
+		iconRoutes.DELETE("/delete-object-tagging", handler.DeleteObjectTaggingHandler)
+		iconRoutes.PUT("/replace-object-metadata", handler.ReplaceObjectMetadataHandler)
+
+		// Versioning
+		iconRoutes.GET("/list-object-versions", handler.ListObjectVersionsHandler)
+		iconRoutes.DELETE("/delete-object-version", handler.DeleteObjectVersionHandler)
+		iconRoutes.POST("/restore-object-version", handler.RestoreObjectVersionHandler)
+		iconRoutes.GET("/get-bucket-versioning", handler.GetBucketVersioningHandler)
+		iconRoutes.PUT("/put-bucket-versioning", handler.PutBucketVersioningHandler)
+		iconRoutes.GET("/get-bucket-lifecycle", handler.GetBucketLifecycleHandler)
+		iconRoutes.PUT("/put-bucket-lifecycle", handler.PutBucketLifecycleHandler)
+		iconRoutes.GET("/get-bucket-cors", handler.GetBucketCORSHandler)
+		iconRoutes.PUT("/put-bucket-cors", handler.PutBucketCORSHandler)
+		iconRoutes.GET("/get-public-access-block", handler.GetPublicAccessBlockHandler)
+		iconRoutes.PUT("/put-public-access-block", handler.PutPublicAccessBlockHandler)
 	}
 
 	// SQS routes
@@ -63,6 +98,11 @@ func SetupRouter() (router *gin.Engine) {
 	{
 		sqsRoutes.POST("/send-message", handler.SendMessageHandler)
 		sqsRoutes.POST("/send-messages", handler.SendMessagesHandler)
+
+		// Dead-letter queues
+		sqsRoutes.GET("/dlq/messages", handler.ListDeadLetterMessagesHandler)
+		sqsRoutes.POST("/dlq/redrive", handler.RedriveDeadLetterMessagesHandler)
+		sqsRoutes.DELETE("/dlq/purge", handler.PurgeDeadLetterQueueHandler)
 	}
 
 	return
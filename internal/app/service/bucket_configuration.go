@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"go-base/internal/pkg/aws/s3"
+	"go-base/internal/pkg/logger"
+	model "go-base/internal/pkg/model"
+	modelHttp "go-base/internal/pkg/model/http"
+)
+
+// Infrequent access classes have a 30 day minimum storage duration, S3 rejects earlier transitions
+var minimumTransitionDays = map[string]int32{
+	"STANDARD_IA": 30,
+	"ONEZONE_IA":  30,
+}
+
+func GetBucketLifecycle(ctx context.Context, req modelHttp.GetBucketLifecycleRequest) (modelHttp.GetBucketLifecycleResponse, model.ServiceResp) {
+	rules, err := s3.GetInstance().GetBucketLifecycle(ctx, s3.ResolveBucket(req.BucketName))
+	if err != nil {
+		if errors.Is(err, s3.ErrBucketNotFound) {
+			return modelHttp.GetBucketLifecycleResponse{}, model.ServiceError.NotFoundError
+		}
+		return modelHttp.GetBucketLifecycleResponse{}, model.ServiceError.InternalServiceError(model.S3GetBucketLifecycleFail)
+	}
+
+	response := modelHttp.GetBucketLifecycleResponse{
+		Rules: make([]modelHttp.LifecycleRule, 0, len(rules)),
+	}
+	for _, rule := range rules {
+		status := "Disabled"
+		if rule.Enabled {
+			status = "Enabled"
+		}
+		transitions := make([]modelHttp.LifecycleTransition, 0, len(rule.Transitions))
+		for _, transition := range rule.Transitions {
+			transitions = append(transitions, modelHttp.LifecycleTransition{
+				Days:         transition.Days,
+				StorageClass: transition.StorageClass,
+			})
+		}
+		response.Rules = append(response.Rules, modelHttp.LifecycleRule{
+			ID:                                 rule.ID,
+			Prefix:                             rule.Prefix,
+			Status:                             status,
+			ExpirationDays:                     rule.ExpirationDays,
+			NoncurrentVersionExpirationDays:    rule.NoncurrentVersionExpirationDays,
+			AbortIncompleteMultipartUploadDays: rule.AbortIncompleteMultipartUploadDays,
+			Transitions:                        transitions,
+		})
+	}
+
+	return response, model.ServiceError.OK
+}
+
+func PutBucketLifecycle(ctx context.Context, req modelHttp.PutBucketLifecycleRequest) (modelHttp.PutBucketLifecycleResponse, model.ServiceResp) {
+	if !validLifecycleRules(req.Rules) {
+		return modelHttp.PutBucketLifecycleResponse{}, model.ServiceError.BadRequestError(model.S3InvalidLifecycleConfiguration)
+	}
+
+	rules := make([]s3.LifecycleRule, 0, len(req.Rules))
+	for _, rule := range req.Rules {
+		transitions := make([]s3.LifecycleTransition, 0, len(rule.Transitions))
+		for _, transition := range rule.Transitions {
+			transitions = append(transitions, s3.LifecycleTransition{
+				Days:         transition.Days,
+				StorageClass: transition.StorageClass,
+			})
+		}
+		rules = append(rules, s3.LifecycleRule{
+			ID:                                 rule.ID,
+			Prefix:                             rule.Prefix,
+			Enabled:                            rule.Status == "Enabled",
+			ExpirationDays:                     rule.ExpirationDays,
+			NoncurrentVersionExpirationDays:    rule.NoncurrentVersionExpirationDays,
+			AbortIncompleteMultipartUploadDays: rule.AbortIncompleteMultipartUploadDays,
+			Transitions:                        transitions,
+		})
+	}
+
+	err := s3.GetInstance().PutBucketLifecycle(ctx, s3.ResolveBucket(req.BucketName), rules)
+	if err != nil {
+		if errors.Is(err, s3.ErrBucketNotFound) {
+			return modelHttp.PutBucketLifecycleResponse{}, model.ServiceError.NotFoundError
+		}
+		return modelHttp.PutBucketLifecycleResponse{}, model.ServiceError.InternalServiceError(model.S3PutBucketLifecycleFail)
+	}
+
+	logger.Info.Printf("PutBucketLifecycle: bucket=%s, rules=%d", req.BucketName, len(rules))
+	response := modelHttp.PutBucketLifecycleResponse{
+		Success: true,
+		Message: "Bucket lifecycle updated successfully",
+	}
+
+	return response, model.ServiceError.OK
+}
+
+// validLifecycleRules checks what the binding tags can't: unique IDs, at least one action per
+// rule, and transitions that move forward in time before the objects expire
+func validLifecycleRules(rules []modelHttp.LifecycleRule) bool {
+	ids := map[string]bool{}
+	for _, rule := range rules {
+		if ids[rule.ID] {
+			return false
+		}
+		ids[rule.ID] = true
+
+		if rule.ExpirationDays == 0 && rule.NoncurrentVersionExpirationDays == 0 &&
+			rule.AbortIncompleteMultipartUploadDays == 0 && len(rule.Transitions) == 0 {
+			return false
+		}
+
+		storageClasses := map[string]bool{}
+		var previousDays int32 = -1
+		for _, transition := range rule.Transitions {
+			if storageClasses[transition.StorageClass] || transition.Days <= previousDays {
+				return false
+			}
+			if transition.Days < minimumTransitionDays[transition.StorageClass] {
+				return false
+			}
+			if rule.ExpirationDays > 0 && transition.Days >= rule.ExpirationDays {
+				return false
+			}
+			storageClasses[transition.StorageClass] = true
+			previousDays = transition.Days
+		}
+	}
+	return true
+}
+
+func GetBucketCORS(ctx context.Context, req modelHttp.GetBucketCORSRequest) (modelHttp.GetBucketCORSResponse, model.ServiceResp) {
+	rules, err := s3.GetInstance().GetBucketCORS(ctx, s3.ResolveBucket(req.BucketName))
+	if err != nil {
+		if errors.Is(err, s3.ErrBucketNotFound) {
+			return modelHttp.GetBucketCORSResponse{}, model.ServiceError.NotFoundError
+		}
+		return modelHttp.GetBucketCORSResponse{}, model.ServiceError.InternalServiceError(model.S3GetBucketCORSFail)
+	}
+
+	response := modelHttp.GetBucketCORSResponse{
+		Rules: make([]modelHttp.CORSRule, 0, len(rules)),
+	}
+	for _, rule := range rules {
+		response.Rules = append(response.Rules, modelHttp.CORSRule{
+			ID:             rule.ID,
+			AllowedOrigins: rule.AllowedOrigins,
+			AllowedMethods: rule.AllowedMethods,
+			AllowedHeaders: rule.AllowedHeaders,
+			ExposeHeaders:  rule.ExposeHeaders,
+			MaxAgeSeconds:  rule.MaxAgeSeconds,
+		})
+	}
+
+	return response, model.ServiceError.OK
+}
+
+func PutBucketCORS(ctx context.Context, req modelHttp.PutBucketCORSRequest) (modelHttp.PutBucketCORSResponse, model.ServiceResp) {
+	if !validCORSRules(req.Rules) {
+		return modelHttp.PutBucketCORSResponse{}, model.ServiceError.BadRequestError(model.S3InvalidCORSConfiguration)
+	}
+
+	rules := make([]s3.CORSRule, 0, len(req.Rules))
+	for _, rule := range req.Rules {
+		rules = append(rules, s3.CORSRule{
+			ID:             rule.ID,
+			AllowedOrigins: rule.AllowedOrigins,
+			AllowedMethods: rule.AllowedMethods,
+			AllowedHeaders: rule.AllowedHeaders,
+			ExposeHeaders:  rule.ExposeHeaders,
+			MaxAgeSeconds:  rule.MaxAgeSeconds,
+		})
+	}
+
+	err := s3.GetInstance().PutBucketCORS(ctx, s3.ResolveBucket(req.BucketName), rules)
+	if err != nil {
+		if errors.Is(err, s3.ErrBucketNotFound) {
+			return modelHttp.PutBucketCORSResponse{}, model.ServiceError.NotFoundError
+		}
+		return modelHttp.PutBucketCORSResponse{}, model.ServiceError.InternalServiceError(model.S3PutBucketCORSFail)
+	}
+
+	logger.Info.Printf("PutBucketCORS: bucket=%s, rules=%d", req.BucketName, len(rules))
+	response := modelHttp.PutBucketCORSResponse{
+		Success: true,
+		Message: "Bucket CORS updated successfully",
+	}
+
+	return response, model.ServiceError.OK
+}
+
+// validCORSRules rejects origins with more than one wildcard, which S3 doesn't accept
+func validCORSRules(rules []modelHttp.CORSRule) bool {
+	for _, rule := range rules {
+		for _, origin := range rule.AllowedOrigins {
+			if strings.Count(origin, "*") > 1 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func GetPublicAccessBlock(ctx context.Context, req modelHttp.GetPublicAccessBlockRequest) (modelHttp.GetPublicAccessBlockResponse, model.ServiceResp) {
+	block, err := s3.GetInstance().GetPublicAccessBlock(ctx, s3.ResolveBucket(req.BucketName))
+	if err != nil {
+		if errors.Is(err, s3.ErrBucketNotFound) {
+			return modelHttp.GetPublicAccessBlockResponse{}, model.ServiceError.NotFoundError
+		}
+		return modelHttp.GetPublicAccessBlockResponse{}, model.ServiceError.InternalServiceError(model.S3GetPublicAccessBlockFail)
+	}
+
+	response := modelHttp.GetPublicAccessBlockResponse{
+		PublicAccessBlock: modelHttp.PublicAccessBlock{
+			BlockPublicAcls:       block.BlockPublicAcls,
+			IgnorePublicAcls:      block.IgnorePublicAcls,
+			BlockPublicPolicy:     block.BlockPublicPolicy,
+			RestrictPublicBuckets: block.RestrictPublicBuckets,
+		},
+	}
+
+	return response, model.ServiceError.OK
+}
+
+func PutPublicAccessBlock(ctx context.Context, req modelHttp.PutPublicAccessBlockRequest) (modelHttp.PutPublicAccessBlockResponse, model.ServiceResp) {
+	block := s3.PublicAccessBlock{
+		BlockPublicAcls:       req.BlockPublicAcls,
+		IgnorePublicAcls:      req.IgnorePublicAcls,
+		BlockPublicPolicy:     req.BlockPublicPolicy,
+		RestrictPublicBuckets: req.RestrictPublicBuckets,
+	}
+	err := s3.GetInstance().PutPublicAccessBlock(ctx, s3.ResolveBucket(req.BucketName), block)
+	if err != nil {
+		if errors.Is(err, s3.ErrBucketNotFound) {
+			return modelHttp.PutPublicAccessBlockResponse{}, model.ServiceError.NotFoundError
+		}
+		return modelHttp.PutPublicAccessBlockResponse{}, model.ServiceError.InternalServiceError(model.S3PutPublicAccessBlockFail)
+	}
+
+	logger.Info.Printf("PutPublicAccessBlock: bucket=%s, %+v", req.BucketName, block)
+	response := modelHttp.PutPublicAccessBlockResponse{
+		Success: true,
+		Message: "Public access block updated successfully",
+	}
+
+	return response, model.ServiceError.OK
+}
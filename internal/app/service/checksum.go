@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+
+	"go-base/internal/pkg/aws/s3"
+	"go-base/internal/pkg/logger"
+	model "go-base/internal/pkg/model"
+	modelHttp "go-base/internal/pkg/model/http"
+)
+
+// objectChecksum is the SHA-256 recomputed from an object body next to the one S3 stored
+type objectChecksum struct {
+	computed string
+	stored   string
+	size     int64
+}
+
+// VerifyObject recomputes the SHA-256 of an object. A mismatch is reported in the
+// response, it isn't an error of the request.
+func VerifyObject(ctx context.Context, req modelHttp.VerifyObjectRequest) (modelHttp.VerifyObjectResponse, model.ServiceResp) {
+	if req.ChecksumSHA256 != "" && !s3.ValidChecksumSHA256(req.ChecksumSHA256) {
+		return modelHttp.VerifyObjectResponse{}, model.ServiceError.BadRequestError(model.S3InvalidChecksum)
+	}
+
+	checksum, err := computeObjectChecksum(ctx, s3.ResolveBucket(req.BucketName), req.ObjectKey, req.VersionID)
+	if err != nil {
+		if errors.Is(err, s3.ErrObjectNotFound) || errors.Is(err, s3.ErrVersionNotFound) {
+			return modelHttp.VerifyObjectResponse{}, model.ServiceError.NotFoundError
+		}
+		logger.Error.Printf("VerifyObject fail, %+v", err)
+		return modelHttp.VerifyObjectResponse{}, model.ServiceError.InternalServiceError(model.S3VerifyObjectFail)
+	}
+
+	valid := checksum.stored == "" || checksum.stored == checksum.computed || !s3.ValidChecksumSHA256(checksum.stored)
+	if req.ChecksumSHA256 != "" && req.ChecksumSHA256 != checksum.computed {
+		valid = false
+	}
+	if !valid {
+		logger.Error.Printf("VerifyObject %s/%s mismatch, computed %s, stored %s, expected %s", req.BucketName, req.ObjectKey, checksum.computed, checksum.stored, req.ChecksumSHA256)
+	}
+
+	response := modelHttp.VerifyObjectResponse{
+		Valid:                valid,
+		ChecksumSHA256:       checksum.computed,
+		StoredChecksumSHA256: checksum.stored,
+		Size:                 checksum.size,
+	}
+
+	return response, model.ServiceError.OK
+}
+
+// computeObjectChecksum hashes the object while streaming it, so large objects are never
+// held in memory
+func computeObjectChecksum(ctx context.Context, bucketName string, objectKey string, versionID string) (objectChecksum, error) {
+	stream, err := s3.GetInstance().GetObjectStream(ctx, bucketName, objectKey, s3.GetObjectOptions{
+		VersionID: versionID,
+	})
+	if err != nil {
+		return objectChecksum{}, err
+	}
+	defer stream.Body.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, stream.Body)
+	// The whole body went through the hash before the stream reported the mismatch
+	if err != nil && !errors.Is(err, s3.ErrChecksumMismatch) {
+		return objectChecksum{}, err
+	}
+
+	return objectChecksum{
+		computed: base64.StdEncoding.EncodeToString(hash.Sum(nil)),
+		stored:   stream.ChecksumSHA256,
+		size:     size,
+	}, nil
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-base/internal/pkg/aws/sqs"
+	"go-base/internal/pkg/config"
+	"go-base/internal/pkg/logger"
+	"go-base/internal/pkg/model"
+	modelHttp "go-base/internal/pkg/model/http"
+)
+
+const (
+	defaultRedriveMaxMessages = 1000
+	// redriveWaitTime long polls the dead-letter queue, a short poll only samples some of
+	// the SQS servers and can come back empty while messages are left
+	redriveWaitTime int32 = 1
+	// redriveMaxEmptyReceives ends a redrive after that many empty receives in a row, unless
+	// the queue reports no messages left before
+	redriveMaxEmptyReceives = 3
+	// redriveVisibilityTimeout hides the received messages while a redrive runs, so they
+	// are inspected once. The ones left in the dead-letter queue are released at the end.
+	redriveVisibilityTimeout int32 = 300
+	// inspectVisibilityTimeout hides the listed messages until they are released right
+	// after receiving them, or for a few seconds when releasing fails. A visibility timeout
+	// of 0 isn't sent to SQS, which then hides them for the queue's default.
+	inspectVisibilityTimeout int32 = 5
+)
+
+var errUnknownSourceQueue = errors.New("unknown source queue")
+
+// ListDeadLetterMessages returns up to MaxMessages messages of a dead-letter queue, with the
+// failure that moved them, and makes them visible again right away. Receiving them still
+// counts as a delivery.
+func ListDeadLetterMessages(ctx context.Context, request modelHttp.ListDeadLetterMessagesRequest) (*modelHttp.ListDeadLetterMessagesResponse, model.ServiceResp) {
+	queue, serviceResp := resolveDeadLetterQueue(ctx, request.QueueName)
+	if serviceResp.Status != http.StatusOK {
+		return nil, serviceResp
+	}
+
+	maxMessages := request.MaxMessages
+	if maxMessages <= 0 {
+		maxMessages = sqs.MaxBatchEntries
+	}
+	messages, err := queue.ReceiveMessages(ctx, maxMessages, 0, inspectVisibilityTimeout)
+	if err != nil {
+		logger.Error.Printf("Failed to receive messages from dead-letter queue %s: %v", request.QueueName, err)
+		return nil, model.ServiceError.InternalServiceError(model.SQSReceiveMessagesFail)
+	}
+	receiptHandles := make([]string, len(messages))
+	for i, message := range messages {
+		receiptHandles[i] = message.ReceiptHandle
+	}
+	releaseMessages(ctx, queue, request.QueueName, receiptHandles)
+
+	response := &modelHttp.ListDeadLetterMessagesResponse{
+		Messages: make([]modelHttp.DeadLetterMessage, len(messages)),
+	}
+	for i, message := range messages {
+		response.Messages[i] = modelHttp.DeadLetterMessage{
+			MessageID:       message.MessageID,
+			Body:            message.Body,
+			Attributes:      sqs.Redrive(message).Attributes,
+			SourceQueueName: message.Attributes[sqs.DeadLetterSourceQueueAttribute],
+			Error:           message.Attributes[sqs.DeadLetterErrorAttribute],
+			ReceiveCount:    message.ReceiveCount,
+			FailedAt:        message.Attributes[sqs.DeadLetterFailedAtAttribute],
+			SentTimestamp:   message.SentTimestamp.UTC().Format(time.RFC3339),
+		}
+	}
+	return response, model.ServiceError.OK
+}
+
+// RedriveDeadLetterMessages sends the messages of a dead-letter queue that match the
+// filters back to their source queue, without the failure metadata, and deletes them from
+// the dead-letter queue. A message that fails stays in the dead-letter queue.
+func RedriveDeadLetterMessages(ctx context.Context, request modelHttp.RedriveDeadLetterMessagesRequest) (*modelHttp.RedriveDeadLetterMessagesResponse, model.ServiceResp) {
+	queue, serviceResp := resolveDeadLetterQueue(ctx, request.QueueName)
+	if serviceResp.Status != http.StatusOK {
+		return nil, serviceResp
+	}
+
+	maxMessages := request.MaxMessages
+	if maxMessages <= 0 {
+		maxMessages = defaultRedriveMaxMessages
+	}
+
+	response := &modelHttp.RedriveDeadLetterMessagesResponse{}
+	// released are the receipt handles of the messages staying in the dead-letter queue
+	var released []string
+	defer func() {
+		releaseMessages(ctx, queue, request.QueueName, released)
+	}()
+
+	for inspected, emptyReceives := 0, 0; inspected < maxMessages; {
+		messages, err := queue.ReceiveMessages(ctx, int32(min(sqs.MaxBatchEntries, maxMessages-inspected)), redriveWaitTime, redriveVisibilityTimeout)
+		if err != nil {
+			logger.Error.Printf("Failed to receive messages from dead-letter queue %s: %v", request.QueueName, err)
+			return nil, model.ServiceError.InternalServiceError(model.SQSRedriveMessagesFail)
+		}
+		if len(messages) == 0 {
+			// Not drained yet when SQS still counts messages, they may be on other servers
+			emptyReceives++
+			if emptyReceives >= redriveMaxEmptyReceives {
+				break
+			}
+			if remaining := remainingMessages(ctx, queue, request.QueueName); remaining != nil && *remaining == 0 {
+				break
+			}
+			continue
+		}
+		emptyReceives = 0
+		inspected += len(messages)
+
+		var redriven []sqs.Message
+		for _, message := range messages {
+			if !matchesRedrive(message, request) {
+				response.Skipped++
+				released = append(released, message.ReceiptHandle)
+				continue
+			}
+			if err := redriveMessage(ctx, message, request.SourceQueueName); err != nil {
+				logger.Error.Printf("Failed to redrive message %s from dead-letter queue %s: %v", message.MessageID, request.QueueName, err)
+				response.Failed = append(response.Failed, modelHttp.RedriveMessageResult{MessageID: message.MessageID, Error: err.Error()})
+				released = append(released, message.ReceiptHandle)
+				continue
+			}
+			redriven = append(redriven, message)
+		}
+		if len(redriven) == 0 {
+			continue
+		}
+
+		receiptHandles := make([]string, len(redriven))
+		for i, message := range redriven {
+			receiptHandles[i] = message.ReceiptHandle
+		}
+		for i, err := range queue.DeleteMessageBatch(ctx, receiptHandles) {
+			if err != nil {
+				// Already sent, it would be redriven twice if it were released
+				logger.Error.Printf("Failed to delete redriven message %s from dead-letter queue %s: %v", redriven[i].MessageID, request.QueueName, err)
+				response.Failed = append(response.Failed, modelHttp.RedriveMessageResult{
+					MessageID: redriven[i].MessageID,
+					Error:     fmt.Sprintf("redriven but not deleted from the dead-letter queue: %v", err),
+				})
+				continue
+			}
+			response.Redriven++
+		}
+	}
+
+	// Counted before the skipped and failed messages are released, they are reported apart
+	response.Remaining = remainingMessages(ctx, queue, request.QueueName)
+
+	logger.Info.Printf("Redrove %d messages from dead-letter queue %s, %d skipped, %d failed", response.Redriven, request.QueueName, response.Skipped, len(response.Failed))
+	return response, model.ServiceError.OK
+}
+
+// remainingMessages returns the approximate number of visible messages of a queue, nil
+// when SQS doesn't report it
+func remainingMessages(ctx context.Context, queue sqs.SQSAPI, queueName string) *int {
+	attributes, err := queue.GetQueueAttributes(ctx, "ApproximateNumberOfMessages")
+	if err != nil {
+		logger.Warn.Printf("Failed to count the messages left in dead-letter queue %s: %v", queueName, err)
+		return nil
+	}
+	remaining, err := strconv.Atoi(attributes["ApproximateNumberOfMessages"])
+	if err != nil {
+		logger.Warn.Printf("Failed to count the messages left in dead-letter queue %s: %v", queueName, err)
+		return nil
+	}
+	return &remaining
+}
+
+// PurgeDeadLetterQueue deletes every message of a dead-letter queue
+func PurgeDeadLetterQueue(ctx context.Context, request modelHttp.PurgeDeadLetterQueueRequest) (*modelHttp.PurgeDeadLetterQueueResponse, model.ServiceResp) {
+	queue, serviceResp := resolveDeadLetterQueue(ctx, request.QueueName)
+	if serviceResp.Status != http.StatusOK {
+		return nil, serviceResp
+	}
+
+	if err := queue.PurgeQueue(ctx); err != nil {
+		logger.Error.Printf("Failed to purge dead-letter queue %s: %v", request.QueueName, err)
+		return nil, model.ServiceError.InternalServiceError(model.SQSPurgeQueueFail)
+	}
+
+	logger.Info.Printf("Purged dead-letter queue %s", request.QueueName)
+	return &modelHttp.PurgeDeadLetterQueueResponse{Success: true}, model.ServiceError.OK
+}
+
+// resolveDeadLetterQueue looks up a queue like resolveQueue, rejecting the queues that
+// aren't configured as dead-letter queues
+func resolveDeadLetterQueue(ctx context.Context, queueName string) (sqs.SQSAPI, model.ServiceResp) {
+	for _, name := range config.Env.SQSDeadLetterQueueNames() {
+		if name == queueName {
+			return lookupQueue(ctx, queueName)
+		}
+	}
+	logger.Error.Printf("Queue %s is not a dead-letter queue", queueName)
+	return nil, model.ServiceError.BadRequestError(model.SQSNotDeadLetterQueue)
+}
+
+// matchesRedrive reports whether a message matches every filter of the request
+func matchesRedrive(message sqs.Message, request modelHttp.RedriveDeadLetterMessagesRequest) bool {
+	if len(request.MessageIDs) > 0 {
+		found := false
+		for _, messageID := range request.MessageIDs {
+			found = found || messageID == message.MessageID
+		}
+		if !found {
+			return false
+		}
+	}
+	for name, value := range request.Attributes {
+		if message.Attributes[name] != value {
+			return false
+		}
+	}
+	return strings.Contains(message.Body, request.BodyContains)
+}
+
+// redriveMessage sends a dead-lettered message to sourceQueueName, or to the queue it
+// failed in when empty
+func redriveMessage(ctx context.Context, message sqs.Message, sourceQueueName string) error {
+	if sourceQueueName == "" {
+		sourceQueueName = message.Attributes[sqs.DeadLetterSourceQueueAttribute]
+	}
+	if sourceQueueName == "" {
+		return errUnknownSourceQueue
+	}
+	source, err := sqs.GetInstance().Queue(ctx, sourceQueueName)
+	if err != nil {
+		return fmt.Errorf("source queue %s: %w", sourceQueueName, err)
+	}
+	_, err = source.SendMessage(ctx, sqs.Redrive(message))
+	return err
+}
+
+// releaseMessages makes messages visible again right away
+func releaseMessages(ctx context.Context, queue sqs.SQSAPI, queueName string, receiptHandles []string) {
+	for _, receiptHandle := range receiptHandles {
+		if err := queue.ChangeMessageVisibility(ctx, receiptHandle, 0); err != nil {
+			logger.Warn.Printf("Failed to release message of dead-letter queue %s: %v", queueName, err)
+		}
+	}
+}
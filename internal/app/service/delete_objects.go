@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"go-base/internal/pkg/aws/s3"
+	"go-base/internal/pkg/job"
+	"go-base/internal/pkg/logger"
+	model "go-base/internal/pkg/model"
+	modelHttp "go-base/internal/pkg/model/http"
+)
+
+const JobTypeDeleteObjectsByPrefix = "delete-objects-by-prefix"
+
+// prefixDeleteChunk is how many listed keys are handed to DeleteObjects at once, enough for
+// its batches to run in parallel
+const prefixDeleteChunk = 4 * s3.MaxDeleteBatchSize
+
+// DeleteObjectsByPrefix deletes everything under a prefix. Prefixes that fit in one listing
+// page are deleted right away, larger ones by a background job.
+func DeleteObjectsByPrefix(ctx context.Context, req modelHttp.DeleteObjectsByPrefixRequest) (modelHttp.DeleteObjectsByPrefixResponse, model.ServiceResp) {
+	bucketName := s3.ResolveBucket(req.BucketName)
+	page, err := s3.GetInstance().ListObjects(ctx, bucketName, req.Prefix, "", "", s3.MaxDeleteBatchSize)
+	if err != nil {
+		if errors.Is(err, s3.ErrBucketNotFound) {
+			return modelHttp.DeleteObjectsByPrefixResponse{}, model.ServiceError.NotFoundError
+		}
+		return modelHttp.DeleteObjectsByPrefixResponse{}, model.ServiceError.InternalServiceError(model.S3DeleteObjectsByPrefixFail)
+	}
+
+	if page.IsTruncated {
+		started := job.Start(JobTypeDeleteObjectsByPrefix, func(ctx context.Context, progress *job.Progress) (interface{}, error) {
+			return deletePrefix(ctx, bucketName, req.Prefix, page, progress)
+		})
+		logger.Info.Printf("DeleteObjectsByPrefix: bucket=%s, prefix=%s, job=%s", req.BucketName, req.Prefix, started.ID)
+		return modelHttp.DeleteObjectsByPrefixResponse{JobID: started.ID}, model.ServiceError.OK
+	}
+
+	response, err := deletePrefix(ctx, bucketName, req.Prefix, page, nil)
+	if err != nil {
+		if errors.Is(err, s3.ErrBucketNotFound) {
+			return modelHttp.DeleteObjectsByPrefixResponse{}, model.ServiceError.NotFoundError
+		}
+		return modelHttp.DeleteObjectsByPrefixResponse{}, model.ServiceError.InternalServiceError(model.S3DeleteObjectsByPrefixFail)
+	}
+
+	return response, model.ServiceError.OK
+}
+
+// deletePrefix deletes the objects of page and of every page after it
+func deletePrefix(ctx context.Context, bucketName string, prefix string, page *s3.ListObjectsResult, progress *job.Progress) (modelHttp.DeleteObjectsByPrefixResponse, error) {
+	response := modelHttp.DeleteObjectsByPrefixResponse{}
+	for {
+		var keys []string
+		for {
+			for _, object := range page.Objects {
+				keys = append(keys, object.Key)
+			}
+			if !page.IsTruncated || len(keys) >= prefixDeleteChunk {
+				break
+			}
+			var err error
+			if page, err = s3.GetInstance().ListObjects(ctx, bucketName, prefix, "", page.NextContinuationToken, s3.MaxDeleteBatchSize); err != nil {
+				return response, err
+			}
+		}
+		progress.AddTotal(len(keys))
+
+		if len(keys) > 0 {
+			deleteResult, err := s3.GetInstance().DeleteObjects(ctx, bucketName, keys)
+			if err != nil {
+				return response, err
+			}
+			response.DeletedCount += len(deleteResult.Deleted)
+			response.Errors = append(response.Errors, toDeleteObjectErrors(deleteResult.Errors)...)
+			progress.Add(len(keys), len(deleteResult.Errors))
+		}
+
+		if !page.IsTruncated {
+			break
+		}
+		var err error
+		if page, err = s3.GetInstance().ListObjects(ctx, bucketName, prefix, "", page.NextContinuationToken, s3.MaxDeleteBatchSize); err != nil {
+			return response, err
+		}
+	}
+
+	response.Success = len(response.Errors) == 0
+	return response, nil
+}
+
+func toDeleteObjectErrors(deleteErrors []s3.DeleteError) []modelHttp.DeleteObjectError {
+	if len(deleteErrors) == 0 {
+		return nil
+	}
+	errorItems := make([]modelHttp.DeleteObjectError, 0, len(deleteErrors))
+	for _, deleteError := range deleteErrors {
+		errorItems = append(errorItems, modelHttp.DeleteObjectError{
+			Key:     deleteError.Key,
+			Code:    deleteError.Code,
+			Message: deleteError.Message,
+		})
+	}
+	return errorItems
+}
@@ -1,6 +1,7 @@
 package externalAccount
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"go-base/internal/pkg/config"
+	"go-base/internal/pkg/health"
 	"go-base/internal/pkg/http/client"
 	"go-base/internal/pkg/logger"
 	"go-base/internal/pkg/model"
@@ -89,3 +91,14 @@ func GetAuthToken() (string, model.ServiceResp) {
 func ClearAuthCache() {
 	authCache.clear()
 }
+
+// HealthChecker reports whether an auth token can be fetched, from the cache while it's
+// valid
+func HealthChecker() health.HealthChecker {
+	return health.NewChecker("auth", func(ctx context.Context) error {
+		if _, resp := GetAuthToken(); resp.Status != http.StatusOK {
+			return fmt.Errorf("get auth token fail, status: %d, code: %s", resp.Status, resp.ErrCode.Code)
+		}
+		return nil
+	})
+}
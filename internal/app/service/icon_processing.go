@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go-base/internal/pkg/aws/s3"
+	"go-base/internal/pkg/aws/sqs"
+	"go-base/internal/pkg/database"
+	"go-base/internal/pkg/icon"
+	"go-base/internal/pkg/logger"
+	modelDB "go-base/internal/pkg/model/db"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const (
+	// IconVariantPrefix holds the resized copies, uploads under it are never processed
+	IconVariantPrefix = "variants/"
+	// IconProcessedMetadata marks objects written by the processor so the ObjectCreated
+	// events they raise are ignored
+	IconProcessedMetadata = "icon-processed"
+)
+
+// IconProcessor validates icons uploaded to S3 and renders their variants. It consumes
+// S3 event notifications delivered through SQS.
+type IconProcessor struct {
+	sizes        []int
+	maxDimension int
+	maxFileSize  int64
+}
+
+// IconProcessorConfig holds configuration for IconProcessor
+type IconProcessorConfig struct {
+	Sizes        []int // variant bounding boxes in pixels
+	MaxDimension int   // widest or tallest side allowed, in pixels
+	MaxFileSize  int64 // in bytes, larger uploads are rejected without downloading them
+}
+
+// NewIconProcessor creates a new IconProcessor
+func NewIconProcessor(cfg IconProcessorConfig) *IconProcessor {
+	return &IconProcessor{
+		sizes:        cfg.Sizes,
+		maxDimension: cfg.MaxDimension,
+		maxFileSize:  cfg.MaxFileSize,
+	}
+}
+
+// s3Event is the part of an S3 event notification the processor reads
+type s3Event struct {
+	Event   string `json:"Event"` // s3:TestEvent is sent once when notifications are configured
+	Records []struct {
+		EventName string `json:"eventName"`
+		S3        struct {
+			Bucket struct {
+				Name string `json:"name"`
+			} `json:"bucket"`
+			Object struct {
+				Key string `json:"key"`
+			} `json:"object"`
+		} `json:"s3"`
+	} `json:"Records"`
+}
+
+// ProcessMessage processes every ObjectCreated record of an S3 event. Rejected icons are
+// not errors, only failures worth retrying are returned.
+func (p *IconProcessor) ProcessMessage(ctx context.Context, message sqs.Message) error {
+	var event s3Event
+	if err := json.Unmarshal([]byte(message.Body), &event); err != nil {
+		// Retrying won't make the body parse
+		logger.Error.Printf("IconProcessor invalid message, %v, body=%s", err, message.Body)
+		return nil
+	}
+
+	var errs []error
+	for _, record := range event.Records {
+		if !strings.HasPrefix(record.EventName, "ObjectCreated:") {
+			continue
+		}
+		// Keys in S3 events are URL encoded, with spaces as '+'
+		key, err := url.QueryUnescape(record.S3.Object.Key)
+		if err != nil {
+			logger.Error.Printf("IconProcessor invalid key %s, %v", record.S3.Object.Key, err)
+			continue
+		}
+		if err := p.ProcessIcon(ctx, record.S3.Bucket.Name, key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ProcessIcon validates one uploaded icon. A valid icon is re-uploaded without its
+// metadata next to its variants, an invalid one is deleted. Both outcomes are recorded
+// for GetIconHeadObject.
+func (p *IconProcessor) ProcessIcon(ctx context.Context, bucketName string, key string) error {
+	if strings.HasPrefix(key, IconVariantPrefix) {
+		return nil
+	}
+
+	head, err := s3.GetInstance().GetHeadObject(ctx, bucketName, key)
+	if err != nil {
+		var notFoundErr *types.NotFound
+		if errors.As(err, &notFoundErr) {
+			// Deleted before the event was processed
+			return nil
+		}
+		return fmt.Errorf("head %s/%s: %w", bucketName, key, err)
+	}
+	if head.Metadata[IconProcessedMetadata] == "true" {
+		return nil
+	}
+
+	if p.maxFileSize > 0 && aws.ToInt64(head.ContentLength) > p.maxFileSize {
+		return p.reject(ctx, bucketName, key, fmt.Sprintf("%d bytes exceeds the %d bytes limit", aws.ToInt64(head.ContentLength), p.maxFileSize))
+	}
+
+	data, err := s3.GetInstance().DownloadFile(ctx, bucketName, key)
+	if err != nil {
+		return fmt.Errorf("download %s/%s: %w", bucketName, key, err)
+	}
+
+	result, err := icon.Process(data, icon.Options{MaxDimension: p.maxDimension, Sizes: p.sizes})
+	if err != nil {
+		var rejectErr *icon.RejectError
+		if errors.As(err, &rejectErr) {
+			return p.reject(ctx, bucketName, key, rejectErr.Reason)
+		}
+		return fmt.Errorf("process %s/%s: %w", bucketName, key, err)
+	}
+
+	record := modelDB.Icon{
+		Bucket:      bucketName,
+		Key:         key,
+		Status:      modelDB.IconStatusReady,
+		ContentType: icon.SanitizedContentType(result.ContentType),
+		Width:       result.Width,
+		Height:      result.Height,
+		Variants:    []modelDB.IconVariant{},
+	}
+	for _, variant := range result.Variants {
+		variantKey := IconVariantKey(key, variant.Size)
+		if err := s3.GetInstance().UploadFile(ctx, bucketName, variantKey, variant.Data, s3.ObjectOptions{
+			ContentType: icon.ContentTypePNG,
+			Metadata:    map[string]string{IconProcessedMetadata: "true"},
+		}); err != nil {
+			return fmt.Errorf("upload %s/%s: %w", bucketName, variantKey, err)
+		}
+		record.Variants = append(record.Variants, modelDB.IconVariant{
+			Size:   variant.Size,
+			Key:    variantKey,
+			Format: "png",
+			Width:  variant.Width,
+			Height: variant.Height,
+			Bytes:  int64(len(variant.Data)),
+		})
+	}
+
+	// Replace the original with the re-encoded copy, which has no EXIF data
+	metadata := map[string]string{}
+	for name, value := range head.Metadata {
+		metadata[name] = value
+	}
+	metadata[IconProcessedMetadata] = "true"
+	if err := s3.GetInstance().UploadFile(ctx, bucketName, key, result.Sanitized, s3.ObjectOptions{
+		ContentType:  record.ContentType,
+		Metadata:     metadata,
+		StorageClass: string(head.StorageClass),
+		SSEKMSKeyID:  aws.ToString(head.SSEKMSKeyId),
+	}); err != nil {
+		return fmt.Errorf("upload %s/%s: %w", bucketName, key, err)
+	}
+
+	record.ProcessedAt = time.Now().Unix()
+	if err := database.UpsertIcon(record); err != nil {
+		return err
+	}
+	logger.Info.Printf("IconProcessor %s/%s ready, %dx%d, %d variants", bucketName, key, result.Width, result.Height, len(record.Variants))
+	return nil
+}
+
+// reject deletes an upload that is not a valid icon and records why
+func (p *IconProcessor) reject(ctx context.Context, bucketName string, key string, reason string) error {
+	logger.Warn.Printf("IconProcessor %s/%s rejected, %s", bucketName, key, reason)
+
+	if err := database.UpsertIcon(modelDB.Icon{
+		Bucket:      bucketName,
+		Key:         key,
+		Status:      modelDB.IconStatusRejected,
+		Reason:      reason,
+		Variants:    []modelDB.IconVariant{},
+		ProcessedAt: time.Now().Unix(),
+	}); err != nil {
+		return err
+	}
+
+	result, err := s3.GetInstance().DeleteObjects(ctx, bucketName, []string{key})
+	if err != nil {
+		return fmt.Errorf("delete %s/%s: %w", bucketName, key, err)
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("delete %s/%s: %s", bucketName, key, result.Errors[0].Message)
+	}
+	return nil
+}
+
+// IconVariantKey is where the variant of key for size is stored, e.g.
+// variants/avatars/me.jpg/64.png
+func IconVariantKey(key string, size int) string {
+	return fmt.Sprintf("%s%s/%d.png", IconVariantPrefix, key, size)
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+
+	"go-base/internal/pkg/job"
+	model "go-base/internal/pkg/model"
+	modelHttp "go-base/internal/pkg/model/http"
+)
+
+func GetJob(ctx context.Context, req modelHttp.GetJobRequest) (modelHttp.GetJobResponse, model.ServiceResp) {
+	found, ok := job.Get(req.JobID)
+	if !ok {
+		return modelHttp.GetJobResponse{}, model.ServiceError.NotFoundError
+	}
+
+	response := modelHttp.GetJobResponse{
+		JobID:     found.ID,
+		Type:      found.Type,
+		Status:    string(found.Status),
+		Total:     found.Total,
+		Processed: found.Processed,
+		Failed:    found.Failed,
+		Result:    found.Result,
+		Error:     found.Error,
+		CreatedAt: safeTime(&found.CreatedAt),
+	}
+	if !found.FinishedAt.IsZero() {
+		response.FinishedAt = safeTime(&found.FinishedAt)
+	}
+
+	return response, model.ServiceError.OK
+}
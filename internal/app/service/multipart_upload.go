@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"go-base/internal/pkg/aws/s3"
+	"go-base/internal/pkg/logger"
+	model "go-base/internal/pkg/model"
+	modelHttp "go-base/internal/pkg/model/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func CreateMultipartUpload(ctx context.Context, req modelHttp.CreateMultipartUploadRequest) (modelHttp.CreateMultipartUploadResponse, model.ServiceResp) {
+	uploadID, err := s3.GetInstance().CreateMultipartUpload(ctx, s3.ResolveBucket(req.BucketName), req.ObjectKey, toObjectOptions(req.ObjectOptions, req.ContentType))
+	if err != nil {
+		return modelHttp.CreateMultipartUploadResponse{}, model.ServiceError.InternalServiceError(model.S3CreateMultipartUploadFail)
+	}
+
+	logger.Info.Printf("CreateMultipartUpload: bucket=%s, key=%s, uploadID=%s", req.BucketName, req.ObjectKey, uploadID)
+	response := modelHttp.CreateMultipartUploadResponse{
+		BucketName: req.BucketName,
+		ObjectKey:  req.ObjectKey,
+		UploadID:   uploadID,
+	}
+
+	return response, model.ServiceError.OK
+}
+
+func PresignUploadParts(ctx context.Context, req modelHttp.PresignUploadPartsRequest) (modelHttp.PresignUploadPartsResponse, model.ServiceResp) {
+	expiresAt := time.Now().Add(s3.PresignURLExpiry)
+
+	parts := make([]modelHttp.PresignedPart, 0, len(req.PartNumbers))
+	for _, partNumber := range req.PartNumbers {
+		presignedURL, err := s3.GetInstance().PresignUploadPartURL(ctx, s3.ResolveBucket(req.BucketName), req.ObjectKey, req.UploadID, partNumber)
+		if err != nil {
+			return modelHttp.PresignUploadPartsResponse{}, model.ServiceError.InternalServiceError(model.S3PresignUploadPartFail)
+		}
+		parts = append(parts, modelHttp.PresignedPart{
+			PartNumber:   partNumber,
+			PresignedURL: presignedURL,
+		})
+	}
+
+	response := modelHttp.PresignUploadPartsResponse{
+		Parts:     parts,
+		ExpiresAt: expiresAt.UTC().Format(time.RFC3339),
+	}
+
+	return response, model.ServiceError.OK
+}
+
+func ListParts(ctx context.Context, req modelHttp.ListPartsRequest) (modelHttp.ListPartsResponse, model.ServiceResp) {
+	parts, err := s3.GetInstance().ListParts(ctx, s3.ResolveBucket(req.BucketName), req.ObjectKey, req.UploadID)
+	if err != nil {
+		if errors.Is(err, s3.ErrUploadNotFound) {
+			return modelHttp.ListPartsResponse{}, model.ServiceError.NotFoundError
+		}
+		return modelHttp.ListPartsResponse{}, model.ServiceError.InternalServiceError(model.S3ListPartsFail)
+	}
+
+	response := modelHttp.ListPartsResponse{
+		Parts: make([]modelHttp.UploadedPart, 0, len(parts)),
+	}
+	for _, part := range parts {
+		response.Parts = append(response.Parts, modelHttp.UploadedPart{
+			PartNumber:   aws.ToInt32(part.PartNumber),
+			ETag:         aws.ToString(part.ETag),
+			Size:         aws.ToInt64(part.Size),
+			LastModified: safeTime(part.LastModified),
+		})
+	}
+
+	return response, model.ServiceError.OK
+}
+
+func CompleteMultipartUpload(ctx context.Context, req modelHttp.CompleteMultipartUploadRequest) (modelHttp.CompleteMultipartUploadResponse, model.ServiceResp) {
+	var completedParts []types.CompletedPart
+	if len(req.Parts) > 0 {
+		for _, part := range req.Parts {
+			completedParts = append(completedParts, types.CompletedPart{
+				PartNumber: aws.Int32(part.PartNumber),
+				ETag:       aws.String(part.ETag),
+			})
+		}
+	} else {
+		// The client didn't keep track of the ETags (e.g. after resuming), use what S3 has
+		uploadedParts, err := s3.GetInstance().ListParts(ctx, s3.ResolveBucket(req.BucketName), req.ObjectKey, req.UploadID)
+		if err != nil {
+			if errors.Is(err, s3.ErrUploadNotFound) {
+				return modelHttp.CompleteMultipartUploadResponse{}, model.ServiceError.NotFoundError
+			}
+			return modelHttp.CompleteMultipartUploadResponse{}, model.ServiceError.InternalServiceError(model.S3ListPartsFail)
+		}
+		for _, part := range uploadedParts {
+			completedParts = append(completedParts, types.CompletedPart{
+				PartNumber: part.PartNumber,
+				ETag:       part.ETag,
+			})
+		}
+	}
+	if len(completedParts) == 0 {
+		return modelHttp.CompleteMultipartUploadResponse{}, model.ServiceError.BadRequestError(model.S3MultipartUploadNoParts)
+	}
+
+	// S3 requires the parts in ascending order
+	sort.Slice(completedParts, func(i, j int) bool {
+		return aws.ToInt32(completedParts[i].PartNumber) < aws.ToInt32(completedParts[j].PartNumber)
+	})
+
+	err := s3.GetInstance().CompleteMultipartUpload(ctx, s3.ResolveBucket(req.BucketName), req.ObjectKey, req.UploadID, completedParts)
+	if err != nil {
+		if errors.Is(err, s3.ErrUploadNotFound) {
+			return modelHttp.CompleteMultipartUploadResponse{}, model.ServiceError.NotFoundError
+		}
+		return modelHttp.CompleteMultipartUploadResponse{}, model.ServiceError.InternalServiceError(model.S3CompleteMultipartUploadFail)
+	}
+
+	response := modelHttp.CompleteMultipartUploadResponse{
+		Success:   true,
+		PartCount: len(completedParts),
+		Message:   "Multipart upload completed successfully",
+	}
+
+	return response, model.ServiceError.OK
+}
+
+func AbortMultipartUpload(ctx context.Context, req modelHttp.AbortMultipartUploadRequest) (modelHttp.AbortMultipartUploadResponse, model.ServiceResp) {
+	err := s3.GetInstance().AbortMultipartUpload(ctx, s3.ResolveBucket(req.BucketName), req.ObjectKey, req.UploadID)
+	if err != nil {
+		if errors.Is(err, s3.ErrUploadNotFound) {
+			return modelHttp.AbortMultipartUploadResponse{}, model.ServiceError.NotFoundError
+		}
+		return modelHttp.AbortMultipartUploadResponse{}, model.ServiceError.InternalServiceError(model.S3AbortMultipartUploadFail)
+	}
+
+	response := modelHttp.AbortMultipartUploadResponse{
+		Success: true,
+		Message: "Multipart upload aborted successfully",
+	}
+
+	return response, model.ServiceError.OK
+}
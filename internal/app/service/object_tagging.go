@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"go-base/internal/pkg/aws/s3"
+	"go-base/internal/pkg/logger"
+	model "go-base/internal/pkg/model"
+	modelHttp "go-base/internal/pkg/model/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func GetObjectTagging(ctx context.Context, req modelHttp.GetObjectTaggingRequest) (modelHttp.GetObjectTaggingResponse, model.ServiceResp) {
+	tags, err := s3.GetInstance().GetObjectTagging(ctx, s3.ResolveBucket(req.BucketName), req.ObjectKey, req.VersionID)
+	if err != nil {
+		if errors.Is(err, s3.ErrObjectNotFound) || errors.Is(err, s3.ErrVersionNotFound) {
+			return modelHttp.GetObjectTaggingResponse{}, model.ServiceError.NotFoundError
+		}
+		return modelHttp.GetObjectTaggingResponse{}, model.ServiceError.InternalServiceError(model.S3GetObjectTaggingFail)
+	}
+
+	response := modelHttp.GetObjectTaggingResponse{
+		Tags: tags,
+	}
+
+	return response, model.ServiceError.OK
+}
+
+func PutObjectTagging(ctx context.Context, req modelHttp.PutObjectTaggingRequest) (modelHttp.PutObjectTaggingResponse, model.ServiceResp) {
+	err := s3.GetInstance().PutObjectTagging(ctx, s3.ResolveBucket(req.BucketName), req.ObjectKey, req.VersionID, req.Tags)
+	if err != nil {
+		if errors.Is(err, s3.ErrObjectNotFound) || errors.Is(err, s3.ErrVersionNotFound) {
+			return modelHttp.PutObjectTaggingResponse{}, model.ServiceError.NotFoundError
+		}
+		return modelHttp.PutObjectTaggingResponse{}, model.ServiceError.InternalServiceError(model.S3PutObjectTaggingFail)
+	}
+
+	response := modelHttp.PutObjectTaggingResponse{
+		Success: true,
+		Message: "Object tags updated successfully",
+	}
+
+	return response, model.ServiceError.OK
+}
+
+func DeleteObjectTagging(ctx context.Context, req modelHttp.DeleteObjectTaggingRequest) (modelHttp.DeleteObjectTaggingResponse, model.ServiceResp) {
+	err := s3.GetInstance().DeleteObjectTagging(ctx, s3.ResolveBucket(req.BucketName), req.ObjectKey, req.VersionID)
+	if err != nil {
+		if errors.Is(err, s3.ErrObjectNotFound) || errors.Is(err, s3.ErrVersionNotFound) {
+			return modelHttp.DeleteObjectTaggingResponse{}, model.ServiceError.NotFoundError
+		}
+		return modelHttp.DeleteObjectTaggingResponse{}, model.ServiceError.InternalServiceError(model.S3DeleteObjectTaggingFail)
+	}
+
+	response := modelHttp.DeleteObjectTaggingResponse{
+		Success: true,
+		Message: "Object tags deleted successfully",
+	}
+
+	return response, model.ServiceError.OK
+}
+
+// ReplaceObjectMetadata copies the object onto itself with the new metadata, S3 has no
+// other way to change the metadata of an existing object
+func ReplaceObjectMetadata(ctx context.Context, req modelHttp.ReplaceObjectMetadataRequest) (modelHttp.ReplaceObjectMetadataResponse, model.ServiceResp) {
+	bucketName := s3.ResolveBucket(req.BucketName)
+
+	headObjectOutput, err := s3.GetInstance().GetHeadObject(ctx, bucketName, req.ObjectKey)
+	if err != nil {
+		var notFoundErr *types.NotFound
+		if errors.As(err, &notFoundErr) {
+			return modelHttp.ReplaceObjectMetadataResponse{}, model.ServiceError.NotFoundError
+		}
+		logger.Error.Printf("ReplaceObjectMetadata head object fail, %+v", err)
+		return modelHttp.ReplaceObjectMetadataResponse{}, model.ServiceError.InternalServiceError(model.S3ReplaceObjectMetadataFail)
+	}
+
+	// The copy would otherwise reset what isn't being changed to the bucket defaults
+	options := s3.ObjectOptions{
+		ContentType:  req.ContentType,
+		Metadata:     req.Metadata,
+		StorageClass: string(headObjectOutput.StorageClass),
+		SSEKMSKeyID:  aws.ToString(headObjectOutput.SSEKMSKeyId),
+	}
+	if options.ContentType == "" {
+		options.ContentType = aws.ToString(headObjectOutput.ContentType)
+	}
+
+	err = s3.GetInstance().CopyObject(ctx, bucketName, req.ObjectKey, bucketName, req.ObjectKey, options)
+	if err != nil {
+		return modelHttp.ReplaceObjectMetadataResponse{}, model.ServiceError.InternalServiceError(model.S3ReplaceObjectMetadataFail)
+	}
+
+	response := modelHttp.ReplaceObjectMetadataResponse{
+		Success: true,
+		Message: "Object metadata replaced successfully",
+	}
+
+	return response, model.ServiceError.OK
+}
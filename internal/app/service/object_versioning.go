@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"go-base/internal/pkg/aws/s3"
+	"go-base/internal/pkg/logger"
+	model "go-base/internal/pkg/model"
+	modelHttp "go-base/internal/pkg/model/http"
+)
+
+func ListObjectVersions(ctx context.Context, req modelHttp.ListObjectVersionsRequest) (modelHttp.ListObjectVersionsResponse, model.ServiceResp) {
+	page, err := s3.GetInstance().ListObjectVersions(ctx, s3.ResolveBucket(req.BucketName), req.Prefix, req.KeyMarker, req.VersionIDMarker, req.MaxKeys)
+	if err != nil {
+		if errors.Is(err, s3.ErrBucketNotFound) {
+			return modelHttp.ListObjectVersionsResponse{}, model.ServiceError.NotFoundError
+		}
+		return modelHttp.ListObjectVersionsResponse{}, model.ServiceError.InternalServiceError(model.S3ListObjectVersionsFail)
+	}
+
+	versions := make([]modelHttp.ObjectVersionInfo, 0, len(page.Versions))
+	for _, version := range page.Versions {
+		versions = append(versions, modelHttp.ObjectVersionInfo{
+			Key:            version.Key,
+			VersionID:      version.VersionID,
+			IsLatest:       version.IsLatest,
+			IsDeleteMarker: version.IsDeleteMarker,
+			Size:           version.Size,
+			ETag:           version.ETag,
+			StorageClass:   version.StorageClass,
+			LastModified:   safeTime(&version.LastModified),
+		})
+	}
+
+	response := modelHttp.ListObjectVersionsResponse{
+		Versions:            versions,
+		NextKeyMarker:       page.NextKeyMarker,
+		NextVersionIDMarker: page.NextVersionIDMarker,
+		IsTruncated:         page.IsTruncated,
+	}
+
+	return response, model.ServiceError.OK
+}
+
+func DeleteObjectVersion(ctx context.Context, req modelHttp.DeleteObjectVersionRequest) (modelHttp.DeleteObjectVersionResponse, model.ServiceResp) {
+	err := s3.GetInstance().DeleteObjectVersion(ctx, s3.ResolveBucket(req.BucketName), req.ObjectKey, req.VersionID)
+	if err != nil {
+		if errors.Is(err, s3.ErrVersionNotFound) {
+			return modelHttp.DeleteObjectVersionResponse{}, model.ServiceError.NotFoundError
+		}
+		return modelHttp.DeleteObjectVersionResponse{}, model.ServiceError.InternalServiceError(model.S3DeleteObjectVersionFail)
+	}
+
+	logger.Info.Printf("DeleteObjectVersion: bucket=%s, key=%s, versionID=%s", req.BucketName, req.ObjectKey, req.VersionID)
+	response := modelHttp.DeleteObjectVersionResponse{
+		Success: true,
+		Message: "Object version deleted successfully",
+	}
+
+	return response, model.ServiceError.OK
+}
+
+func RestoreObjectVersion(ctx context.Context, req modelHttp.RestoreObjectVersionRequest) (modelHttp.RestoreObjectVersionResponse, model.ServiceResp) {
+	versionID, err := s3.GetInstance().RestoreObjectVersion(ctx, s3.ResolveBucket(req.BucketName), req.ObjectKey, req.VersionID)
+	if err != nil {
+		if errors.Is(err, s3.ErrObjectNotFound) || errors.Is(err, s3.ErrVersionNotFound) {
+			return modelHttp.RestoreObjectVersionResponse{}, model.ServiceError.NotFoundError
+		}
+		return modelHttp.RestoreObjectVersionResponse{}, model.ServiceError.InternalServiceError(model.S3RestoreObjectVersionFail)
+	}
+
+	response := modelHttp.RestoreObjectVersionResponse{
+		Success:   true,
+		VersionID: versionID,
+		Message:   "Object version restored successfully",
+	}
+
+	return response, model.ServiceError.OK
+}
+
+func GetBucketVersioning(ctx context.Context, req modelHttp.GetBucketVersioningRequest) (modelHttp.GetBucketVersioningResponse, model.ServiceResp) {
+	status, err := s3.GetInstance().GetBucketVersioning(ctx, s3.ResolveBucket(req.BucketName))
+	if err != nil {
+		return modelHttp.GetBucketVersioningResponse{}, model.ServiceError.InternalServiceError(model.S3GetBucketVersioningFail)
+	}
+
+	response := modelHttp.GetBucketVersioningResponse{
+		Status: status,
+	}
+
+	return response, model.ServiceError.OK
+}
+
+func PutBucketVersioning(ctx context.Context, req modelHttp.PutBucketVersioningRequest) (modelHttp.PutBucketVersioningResponse, model.ServiceResp) {
+	err := s3.GetInstance().PutBucketVersioning(ctx, s3.ResolveBucket(req.BucketName), req.Status == "Enabled")
+	if err != nil {
+		return modelHttp.PutBucketVersioningResponse{}, model.ServiceError.InternalServiceError(model.S3PutBucketVersioningFail)
+	}
+
+	response := modelHttp.PutBucketVersioningResponse{
+		Success: true,
+		Message: "Bucket versioning updated successfully",
+	}
+
+	return response, model.ServiceError.OK
+}
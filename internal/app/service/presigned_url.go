@@ -3,11 +3,14 @@ package service
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
-
+	"io"
+	"net/http"
 	"time"
 
 	"go-base/internal/pkg/aws/s3"
+	"go-base/internal/pkg/database"
 	"go-base/internal/pkg/logger"
 	model "go-base/internal/pkg/model"
 	modelHttp "go-base/internal/pkg/model/http"
@@ -23,13 +26,24 @@ func GetIconPresignedURL(ctx context.Context, req modelHttp.GetIconPresignedURLR
 	var presignedURL string
 	var err error
 
+	s3API := s3.GetInstance()
+	if s3API == nil {
+		logger.Error.Printf("GetIconPresignedURL: S3 instance not initialized")
+		return modelHttp.GetIconPresignedURLResponse{}, model.ServiceError.InternalServiceError(model.DBGetIconPresignedURLFail)
+	}
+
 	if req.Method == HttpMethodGet {
-		presignedURL, err = s3.GetInstance().PresignGetURL(req.Key)
+		presignedURL, err = s3API.PresignGetURL(ctx, iconBucket(req.BucketName), req.Key)
 		if err != nil {
 			return modelHttp.GetIconPresignedURLResponse{}, model.ServiceError.InternalServiceError(model.DBGetIconPresignedURLFail)
 		}
 	} else if req.Method == HttpMethodPut {
-		presignedURL, err = s3.GetInstance().PresignPutURL(req.Key, req.ContentType)
+		if req.ChecksumSHA256 != "" && !s3.ValidChecksumSHA256(req.ChecksumSHA256) {
+			return modelHttp.GetIconPresignedURLResponse{}, model.ServiceError.BadRequestError(model.S3InvalidChecksum)
+		}
+		options := toObjectOptions(req.ObjectOptions, req.ContentType)
+		options.ChecksumSHA256 = req.ChecksumSHA256
+		presignedURL, err = s3API.PresignPutURL(ctx, iconBucket(req.BucketName), req.Key, options)
 		if err != nil {
 			return modelHttp.GetIconPresignedURLResponse{}, model.ServiceError.InternalServiceError(model.DBGetIconPresignedURLFail)
 		}
@@ -44,14 +58,54 @@ func GetIconPresignedURL(ctx context.Context, req modelHttp.GetIconPresignedURLR
 	return response, model.ServiceError.OK
 }
 
+func GetPresignedPost(ctx context.Context, req modelHttp.GetPresignedPostRequest) (modelHttp.GetPresignedPostResponse, model.ServiceResp) {
+	if (req.Key == "") == (req.KeyPrefix == "") {
+		return modelHttp.GetPresignedPostResponse{}, model.ServiceError.BadRequestError(model.S3PresignPostInvalidPolicy)
+	}
+	if req.ContentType != "" && req.ContentTypePrefix != "" {
+		return modelHttp.GetPresignedPostResponse{}, model.ServiceError.BadRequestError(model.S3PresignPostInvalidPolicy)
+	}
+	if req.MinSize > req.MaxSize {
+		return modelHttp.GetPresignedPostResponse{}, model.ServiceError.BadRequestError(model.S3PresignPostInvalidPolicy)
+	}
+	// A form can't outlive the credentials that signed it
+	if time.Duration(req.ExpiresIn)*time.Second > s3.PresignURLExpiry {
+		return modelHttp.GetPresignedPostResponse{}, model.ServiceError.BadRequestError(model.S3PresignPostInvalidPolicy)
+	}
+
+	policy := s3.PostPolicy{
+		Expiry:            time.Duration(req.ExpiresIn) * time.Second,
+		KeyPrefix:         req.KeyPrefix,
+		ContentType:       req.ContentType,
+		ContentTypePrefix: req.ContentTypePrefix,
+		MinContentLength:  req.MinSize,
+		MaxContentLength:  req.MaxSize,
+		Metadata:          req.Metadata,
+	}
+	presignedPost, err := s3.GetInstance().PresignPostObject(ctx, s3.ResolveBucket(req.BucketName), req.Key, policy)
+	if err != nil {
+		return modelHttp.GetPresignedPostResponse{}, model.ServiceError.InternalServiceError(model.S3PresignPostFail)
+	}
+
+	response := modelHttp.GetPresignedPostResponse{
+		URL:       presignedPost.URL,
+		Fields:    presignedPost.Fields,
+		ExpiresAt: presignedPost.ExpiresAt.UTC().Format(time.RFC3339),
+	}
+
+	return response, model.ServiceError.OK
+}
+
 func GetIconHeadObject(ctx context.Context, req modelHttp.GetIconHeadObjectRequest) (modelHttp.GetIconHeadObjectResponse, model.ServiceResp) {
-	headObjectOutput, err := s3.GetInstance().GetHeadObject(req.Key)
+	headObjectOutput, err := s3.GetInstance().GetHeadObject(ctx, iconBucket(req.BucketName), req.Key)
 	logger.Info.Printf("GetIconHeadObject: %+v", headObjectOutput)
 	if err != nil {
 		// 檢查是否為物件不存在的錯誤
 		response := modelHttp.GetIconHeadObjectResponse{
 			Exists: false,
 		}
+		// A rejected icon is deleted, its record still says why
+		addIconProcessing(&response, iconBucket(req.BucketName), req.Key)
 		return response, model.ServiceError.OK
 	}
 
@@ -80,11 +134,38 @@ func GetIconHeadObject(ctx context.Context, req modelHttp.GetIconHeadObjectReque
 		response.Metadata = headObjectOutput.Metadata
 	}
 
+	addIconProcessing(&response, iconBucket(req.BucketName), req.Key)
+
 	return response, model.ServiceError.OK
 }
 
+// addIconProcessing adds what the icon processor recorded for the object. Not every
+// upload goes through the processor, a missing record is fine.
+func addIconProcessing(response *modelHttp.GetIconHeadObjectResponse, bucketName string, key string) {
+	iconRecord, err := database.GetIcon(bucketName, key)
+	if err != nil {
+		if !errors.Is(err, database.ERROR_DATA_NOT_FOUND) {
+			logger.Error.Printf("GetIconHeadObject get icon record fail, %+v", err)
+		}
+		return
+	}
+
+	response.ProcessingStatus = iconRecord.Status
+	response.Reason = iconRecord.Reason
+	for _, variant := range iconRecord.Variants {
+		response.Variants = append(response.Variants, modelHttp.IconVariant{
+			Size:          variant.Size,
+			Key:           variant.Key,
+			Format:        variant.Format,
+			Width:         variant.Width,
+			Height:        variant.Height,
+			ContentLength: variant.Bytes,
+		})
+	}
+}
+
 func GetIconCheckObjectExists(ctx context.Context, req modelHttp.GetIconCheckObjectExistsRequest) (modelHttp.GetIconCheckObjectExistsResponse, model.ServiceResp) {
-	exists, err := s3.GetInstance().CheckObjectExists(req.Key)
+	exists, err := s3.GetInstance().CheckObjectExists(ctx, iconBucket(req.BucketName), req.Key)
 	if err != nil {
 		return modelHttp.GetIconCheckObjectExistsResponse{}, model.ServiceError.InternalServiceError("Failed to check object existence")
 	}
@@ -97,42 +178,19 @@ func GetIconCheckObjectExists(ctx context.Context, req modelHttp.GetIconCheckObj
 }
 
 func GetIconDeleteObjects(ctx context.Context, req modelHttp.GetIconDeleteObjectsRequest) (modelHttp.GetIconDeleteObjectsResponse, model.ServiceResp) {
-	deleteObjectsOutput, err := s3.GetInstance().DeleteObjects(req.Keys)
+	deleteResult, err := s3.GetInstance().DeleteObjects(ctx, iconBucket(req.BucketName), req.Keys)
 	if err != nil {
-		return modelHttp.GetIconDeleteObjectsResponse{}, model.ServiceError.InternalServiceError("Failed to delete objects")
+		if errors.Is(err, s3.ErrBucketNotFound) {
+			return modelHttp.GetIconDeleteObjectsResponse{}, model.ServiceError.NotFoundError
+		}
+		return modelHttp.GetIconDeleteObjectsResponse{}, model.ServiceError.InternalServiceError(model.S3DeleteObjectsFail)
 	}
 
 	response := modelHttp.GetIconDeleteObjectsResponse{
-		Success:      true,
-		DeletedCount: len(deleteObjectsOutput.Deleted),
-	}
-
-	// 處理錯誤項目
-	if len(deleteObjectsOutput.Errors) > 0 {
-		var errors []modelHttp.DeleteObjectError
-		for _, deleteError := range deleteObjectsOutput.Errors {
-			errorItem := modelHttp.DeleteObjectError{}
-
-			if deleteError.Key != nil {
-				errorItem.Key = *deleteError.Key
-			}
-
-			if deleteError.Code != nil {
-				errorItem.Code = *deleteError.Code
-			}
-
-			if deleteError.Message != nil {
-				errorItem.Message = *deleteError.Message
-			}
-
-			errors = append(errors, errorItem)
-		}
-		response.Errors = errors
-
-		// 如果有錯誤，將成功標記為 false
-		if len(deleteObjectsOutput.Deleted) == 0 {
-			response.Success = false
-		}
+		Success:      len(deleteResult.Errors) == 0,
+		DeletedCount: len(deleteResult.Deleted),
+		Deleted:      deleteResult.Deleted,
+		Errors:       toDeleteObjectErrors(deleteResult.Errors),
 	}
 
 	return response, model.ServiceError.OK
@@ -140,7 +198,7 @@ func GetIconDeleteObjects(ctx context.Context, req modelHttp.GetIconDeleteObject
 
 // 1. ListBuckets Service
 func ListBuckets(ctx context.Context, req modelHttp.ListBucketsRequest) (modelHttp.ListBucketsResponse, model.ServiceResp) {
-	buckets, err := s3.GetInstance().ListBuckets()
+	buckets, err := s3.GetInstance().ListBuckets(ctx)
 	if err != nil {
 		return modelHttp.ListBucketsResponse{}, model.ServiceError.InternalServiceError("Failed to list buckets")
 	}
@@ -161,6 +219,39 @@ func ConvertBuckets(buckets []types.Bucket) modelHttp.ListBucketsResponse {
 	return resp
 }
 
+// iconBucket resolves the bucket of the icon endpoints, which default to the icons bucket
+func iconBucket(name string) string {
+	if name == "" {
+		return s3.Bucket(s3.BucketIcons)
+	}
+	return s3.ResolveBucket(name)
+}
+
+func toObjectOptions(options modelHttp.ObjectOptions, contentType string) s3.ObjectOptions {
+	return s3.ObjectOptions{
+		ContentType:  contentType,
+		Metadata:     options.Metadata,
+		StorageClass: options.StorageClass,
+		ACL:          options.ACL,
+		SSEKMSKeyID:  options.SSEKMSKeyID,
+	}
+}
+
+// toUploadOptions adds the SHA-256 S3 verifies the upload against. The caller's checksum is
+// forwarded as it is, without one it is computed here so S3 still stores one.
+func toUploadOptions(options modelHttp.ObjectOptions, contentType string, checksum string, fileData []byte) (s3.ObjectOptions, model.ServiceResp) {
+	uploadOptions := toObjectOptions(options, contentType)
+	if checksum == "" {
+		uploadOptions.ChecksumSHA256 = s3.ChecksumSHA256(fileData)
+		return uploadOptions, model.ServiceError.OK
+	}
+	if !s3.ValidChecksumSHA256(checksum) {
+		return uploadOptions, model.ServiceError.BadRequestError(model.S3InvalidChecksum)
+	}
+	uploadOptions.ChecksumSHA256 = checksum
+	return uploadOptions, model.ServiceError.OK
+}
+
 func safeString(s *string) string {
 	if s == nil {
 		return ""
@@ -177,7 +268,7 @@ func safeTime(t *time.Time) string {
 
 // 2. BucketExists Service
 func BucketExists(ctx context.Context, req modelHttp.BucketExistsRequest) (modelHttp.BucketExistsResponse, model.ServiceResp) {
-	exists, err := s3.GetInstance().BucketExists(req.BucketName)
+	exists, err := s3.GetInstance().BucketExists(ctx, s3.ResolveBucket(req.BucketName))
 	if err != nil {
 		return modelHttp.BucketExistsResponse{}, model.ServiceError.InternalServiceError("Failed to check bucket existence")
 	}
@@ -191,7 +282,7 @@ func BucketExists(ctx context.Context, req modelHttp.BucketExistsRequest) (model
 
 // 3. CreateBucket Service
 func CreateBucket(ctx context.Context, req modelHttp.CreateBucketRequest) (modelHttp.CreateBucketResponse, model.ServiceResp) {
-	err := s3.GetInstance().CreateBucket(req.BucketName, req.Region)
+	err := s3.GetInstance().CreateBucket(ctx, req.BucketName, req.Region)
 	if err != nil {
 		return modelHttp.CreateBucketResponse{
 			Success: false,
@@ -218,7 +309,14 @@ func UploadFile(ctx context.Context, req modelHttp.UploadFileRequest) (modelHttp
 		}, model.ServiceError.BadRequestError("Invalid file data encoding")
 	}
 
-	err = s3.GetInstance().UploadFile(req.BucketName, req.ObjectKey, fileData)
+	options, serviceResp := toUploadOptions(req.ObjectOptions, req.ContentType, req.ChecksumSHA256, fileData)
+	if serviceResp.Status != http.StatusOK {
+		return modelHttp.UploadFileResponse{}, serviceResp
+	}
+	err = s3.GetInstance().UploadFile(ctx, s3.ResolveBucket(req.BucketName), req.ObjectKey, fileData, options)
+	if errors.Is(err, s3.ErrChecksumMismatch) {
+		return modelHttp.UploadFileResponse{}, model.ServiceError.BadRequestError(model.S3ChecksumMismatch)
+	}
 	if err != nil {
 		return modelHttp.UploadFileResponse{
 			Success: false,
@@ -227,8 +325,9 @@ func UploadFile(ctx context.Context, req modelHttp.UploadFileRequest) (modelHttp
 	}
 
 	response := modelHttp.UploadFileResponse{
-		Success: true,
-		Message: "File uploaded successfully",
+		Success:        true,
+		Message:        "File uploaded successfully",
+		ChecksumSHA256: options.ChecksumSHA256,
 	}
 
 	return response, model.ServiceError.OK
@@ -244,7 +343,14 @@ func UploadLargeObject(ctx context.Context, req modelHttp.UploadLargeObjectReque
 		}, model.ServiceError.BadRequestError("Invalid file data encoding")
 	}
 
-	err = s3.GetInstance().UploadFile(req.BucketName, req.ObjectKey, fileData)
+	options, serviceResp := toUploadOptions(req.ObjectOptions, req.ContentType, req.ChecksumSHA256, fileData)
+	if serviceResp.Status != http.StatusOK {
+		return modelHttp.UploadLargeObjectResponse{}, serviceResp
+	}
+	err = s3.GetInstance().UploadFile(ctx, s3.ResolveBucket(req.BucketName), req.ObjectKey, fileData, options)
+	if errors.Is(err, s3.ErrChecksumMismatch) {
+		return modelHttp.UploadLargeObjectResponse{}, model.ServiceError.BadRequestError(model.S3ChecksumMismatch)
+	}
 	if err != nil {
 		return modelHttp.UploadLargeObjectResponse{
 			Success: false,
@@ -253,8 +359,9 @@ func UploadLargeObject(ctx context.Context, req modelHttp.UploadLargeObjectReque
 	}
 
 	response := modelHttp.UploadLargeObjectResponse{
-		Success: true,
-		Message: "Large object uploaded successfully",
+		Success:        true,
+		Message:        "Large object uploaded successfully",
+		ChecksumSHA256: options.ChecksumSHA256,
 	}
 
 	return response, model.ServiceError.OK
@@ -262,45 +369,73 @@ func UploadLargeObject(ctx context.Context, req modelHttp.UploadLargeObjectReque
 
 // 6. DownloadFile Service
 func DownloadFile(ctx context.Context, req modelHttp.DownloadFileRequest) (modelHttp.DownloadFileResponse, model.ServiceResp) {
-	fileData, err := s3.GetInstance().DownloadFile(req.BucketName, req.ObjectKey)
+	stream, err := s3.GetInstance().GetObjectStream(ctx, s3.ResolveBucket(req.BucketName), req.ObjectKey, s3.GetObjectOptions{
+		VersionID: req.VersionID,
+	})
 	if err != nil {
+		if errors.Is(err, s3.ErrObjectNotFound) || errors.Is(err, s3.ErrVersionNotFound) {
+			return modelHttp.DownloadFileResponse{}, model.ServiceError.NotFoundError
+		}
+		return modelHttp.DownloadFileResponse{}, model.ServiceError.InternalServiceError("Failed to download file")
+	}
+	defer stream.Body.Close()
+
+	fileData, err := io.ReadAll(stream.Body)
+	if errors.Is(err, s3.ErrChecksumMismatch) {
+		logger.Error.Printf("DownloadFile %s/%s corrupted, %+v", req.BucketName, req.ObjectKey, err)
+		return modelHttp.DownloadFileResponse{}, model.ServiceError.InternalServiceError(model.S3ChecksumMismatch)
+	}
+	if err != nil {
+		logger.Error.Printf("DownloadFile read body fail, %+v", err)
 		return modelHttp.DownloadFileResponse{}, model.ServiceError.InternalServiceError("Failed to download file")
 	}
 
 	encodedData := base64.StdEncoding.EncodeToString(fileData)
 
 	response := modelHttp.DownloadFileResponse{
-		FileData:    encodedData,
-		ContentType: "application/octet-stream",
-		Size:        int64(len(fileData)),
+		FileData:       encodedData,
+		ContentType:    stream.ContentType,
+		Size:           int64(len(fileData)),
+		ChecksumSHA256: stream.ChecksumSHA256,
 	}
 
 	return response, model.ServiceError.OK
 }
 
 // 7. DownloadLargeObject Service
-func DownloadLargeObject(ctx context.Context, req modelHttp.DownloadLargeObjectRequest) (modelHttp.DownloadLargeObjectResponse, model.ServiceResp) {
-	fileData, err := s3.GetInstance().DownloadFile(req.BucketName, req.ObjectKey)
+// The caller is responsible for closing the returned stream's Body. When the object is
+// not modified, the stream has no Body and only holds the current ETag.
+func DownloadLargeObject(ctx context.Context, req modelHttp.DownloadLargeObjectRequest) (*s3.ObjectStream, model.ServiceResp) {
+	stream, err := s3.GetInstance().GetObjectStream(ctx, s3.ResolveBucket(req.BucketName), req.ObjectKey, s3.GetObjectOptions{
+		Range:       req.Range,
+		IfNoneMatch: req.IfNoneMatch,
+		VersionID:   req.VersionID,
+	})
 	if err != nil {
-		return modelHttp.DownloadLargeObjectResponse{}, model.ServiceError.InternalServiceError("Failed to download large object")
-	}
-
-	encodedData := base64.StdEncoding.EncodeToString(fileData)
-
-	response := modelHttp.DownloadLargeObjectResponse{
-		FileData:    encodedData,
-		ContentType: "application/octet-stream",
-		Size:        int64(len(fileData)),
+		switch {
+		case errors.Is(err, s3.ErrNotModified):
+			var notModified *s3.NotModifiedError
+			if errors.As(err, &notModified) {
+				return &s3.ObjectStream{ETag: notModified.ETag}, model.ServiceError.NotModified(model.S3ObjectNotModified)
+			}
+			return nil, model.ServiceError.NotModified(model.S3ObjectNotModified)
+		case errors.Is(err, s3.ErrObjectNotFound), errors.Is(err, s3.ErrVersionNotFound):
+			return nil, model.ServiceError.NotFoundError
+		case errors.Is(err, s3.ErrInvalidRange):
+			return nil, model.ServiceError.RangeNotSatisfiable(model.S3InvalidRange)
+		}
+		return nil, model.ServiceError.InternalServiceError(model.S3GetObjectFail)
 	}
 
-	return response, model.ServiceError.OK
+	return stream, model.ServiceError.OK
 }
 
 // 8. CopyToFolder Service
 func CopyToFolder(ctx context.Context, req modelHttp.CopyToFolderRequest) (modelHttp.CopyToFolderResponse, model.ServiceResp) {
 	newObjectKey := fmt.Sprintf("%s/%s", req.FolderName, req.ObjectKey)
 
-	err := s3.GetInstance().CopyObject(req.BucketName, req.ObjectKey, req.BucketName, newObjectKey)
+	bucketName := s3.ResolveBucket(req.BucketName)
+	err := s3.GetInstance().CopyObject(ctx, bucketName, req.ObjectKey, bucketName, newObjectKey, toObjectOptions(req.ObjectOptions, ""))
 	if err != nil {
 		return modelHttp.CopyToFolderResponse{
 			Success: false,
@@ -319,7 +454,7 @@ func CopyToFolder(ctx context.Context, req modelHttp.CopyToFolderRequest) (model
 
 // 9. CopyToBucket Service
 func CopyToBucket(ctx context.Context, req modelHttp.CopyToBucketRequest) (modelHttp.CopyToBucketResponse, model.ServiceResp) {
-	err := s3.GetInstance().CopyObject(req.SourceBucket, req.ObjectKey, req.DestinationBucket, req.ObjectKey)
+	err := s3.GetInstance().CopyObject(ctx, s3.ResolveBucket(req.SourceBucket), req.ObjectKey, s3.ResolveBucket(req.DestinationBucket), req.ObjectKey, toObjectOptions(req.ObjectOptions, ""))
 	if err != nil {
 		return modelHttp.CopyToBucketResponse{
 			Success: false,
@@ -337,14 +472,30 @@ func CopyToBucket(ctx context.Context, req modelHttp.CopyToBucketRequest) (model
 
 // 10. ListObjects Service
 func ListObjects(ctx context.Context, req modelHttp.ListObjectsRequest) (modelHttp.ListObjectsResponse, model.ServiceResp) {
-	_, err := s3.GetInstance().ListObjects(req.BucketName)
+	page, err := s3.GetInstance().ListObjects(ctx, s3.ResolveBucket(req.BucketName), req.Prefix, req.Delimiter, req.ContinuationToken, req.MaxKeys)
 	if err != nil {
+		if errors.Is(err, s3.ErrBucketNotFound) {
+			return modelHttp.ListObjectsResponse{}, model.ServiceError.NotFoundError
+		}
 		return modelHttp.ListObjectsResponse{}, model.ServiceError.InternalServiceError("Failed to list objects")
 	}
 
-	var objects []modelHttp.ObjectInfo
+	objects := make([]modelHttp.ObjectInfo, 0, len(page.Objects))
+	for _, object := range page.Objects {
+		objects = append(objects, modelHttp.ObjectInfo{
+			Key:          object.Key,
+			Size:         object.Size,
+			LastModified: safeTime(&object.LastModified),
+			ETag:         object.ETag,
+			StorageClass: object.StorageClass,
+		})
+	}
+
 	response := modelHttp.ListObjectsResponse{
-		Objects: objects,
+		Objects:               objects,
+		CommonPrefixes:        page.CommonPrefixes,
+		NextContinuationToken: page.NextContinuationToken,
+		IsTruncated:           page.IsTruncated,
 	}
 
 	return response, model.ServiceError.OK
@@ -352,16 +503,21 @@ func ListObjects(ctx context.Context, req modelHttp.ListObjectsRequest) (modelHt
 
 // 11. DeleteObjectsFromBucket Service
 func DeleteObjectsFromBucket(ctx context.Context, req modelHttp.DeleteObjectsFromBucketRequest) (modelHttp.DeleteObjectsFromBucketResponse, model.ServiceResp) {
-	err := s3.GetInstance().DeleteObjectsFromBucket(req.BucketName, req.ObjectKeys)
+	deleteResult, err := s3.GetInstance().DeleteObjects(ctx, s3.ResolveBucket(req.BucketName), req.ObjectKeys)
 	if err != nil {
+		if errors.Is(err, s3.ErrBucketNotFound) {
+			return modelHttp.DeleteObjectsFromBucketResponse{}, model.ServiceError.NotFoundError
+		}
 		return modelHttp.DeleteObjectsFromBucketResponse{
 			Success: false,
-		}, model.ServiceError.InternalServiceError("Failed to delete objects from bucket")
+		}, model.ServiceError.InternalServiceError(model.S3DeleteObjectsFail)
 	}
 
 	response := modelHttp.DeleteObjectsFromBucketResponse{
-		Success:      true,
-		DeletedCount: len(req.ObjectKeys),
+		Success:      len(deleteResult.Errors) == 0,
+		DeletedCount: len(deleteResult.Deleted),
+		Deleted:      deleteResult.Deleted,
+		Errors:       toDeleteObjectErrors(deleteResult.Errors),
 	}
 
 	return response, model.ServiceError.OK
@@ -369,7 +525,7 @@ func DeleteObjectsFromBucket(ctx context.Context, req modelHttp.DeleteObjectsFro
 
 // 12. DeleteBucket Service
 func DeleteBucket(ctx context.Context, req modelHttp.DeleteBucketRequest) (modelHttp.DeleteBucketResponse, model.ServiceResp) {
-	err := s3.GetInstance().DeleteBucket(req.BucketName)
+	err := s3.GetInstance().DeleteBucket(ctx, req.BucketName)
 	if err != nil {
 		return modelHttp.DeleteBucketResponse{
 			Success: false,
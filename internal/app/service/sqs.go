@@ -2,10 +2,12 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"time"
+	"net/http"
 
 	"go-base/internal/pkg/aws/sqs"
+	"go-base/internal/pkg/config"
 	"go-base/internal/pkg/logger"
 	"go-base/internal/pkg/model"
 	modelHttp "go-base/internal/pkg/model/http"
@@ -15,68 +17,114 @@ import (
 func SendMessage(ctx context.Context, request modelHttp.SendMessageRequest) (*modelHttp.SendMessageResponse, model.ServiceResp) {
 	logger.Info.Printf("Sending message to queue: %s", request.QueueName)
 
+	queue, serviceResp := resolveQueue(ctx, request.QueueName)
+	if serviceResp.Status != http.StatusOK {
+		return nil, serviceResp
+	}
+
 	// Send message
-	err := sqs.TestSQS.SendMessage(ctx, request.Message)
+	messageID, err := queue.SendMessage(ctx, toOutgoingMessage(request.Message, request.MessageOptions))
+	if errors.Is(err, sqs.ErrMessageTooLarge) {
+		logger.Error.Printf("Message to queue %s is too large", request.QueueName)
+		return nil, model.ServiceError.BadRequestError(model.SQSMessageTooLarge)
+	}
 	if err != nil {
 		logger.Error.Printf("Failed to send message to queue %s: %v", request.QueueName, err)
-		return nil, model.ServiceError.InternalServiceError(fmt.Sprintf("Failed to send message: %v", err))
+		return nil, model.ServiceError.InternalServiceError(model.SQSSendMessageFail)
 	}
 
 	response := &modelHttp.SendMessageResponse{
 		Success:   true,
-		MessageID: generateMessageID(), // You might want to get actual message ID from AWS response
+		MessageID: messageID,
 	}
 
-	logger.Info.Printf("Successfully sent message to queue: %s", request.QueueName)
+	logger.Info.Printf("Successfully sent message %s to queue: %s", messageID, request.QueueName)
 	return response, model.ServiceError.OK
 }
 
-// SendMessages sends multiple messages to the specified SQS queue
+// SendMessages sends multiple messages to the specified SQS queue in batches. A message
+// that fails doesn't fail the request, it is reported in the results.
 func SendMessages(ctx context.Context, request modelHttp.SendMessagesRequest) (*modelHttp.SendMessagesResponse, model.ServiceResp) {
-	logger.Info.Printf("Sending %d messages to queue: %s", len(request.Messages), request.QueueName)
+	messages := make([]sqs.OutgoingMessage, 0, len(request.Messages)+len(request.Entries))
+	for _, message := range request.Messages {
+		messages = append(messages, sqs.OutgoingMessage{Body: message})
+	}
+	for _, entry := range request.Entries {
+		messages = append(messages, toOutgoingMessage(entry.Message, entry.MessageOptions))
+	}
+	logger.Info.Printf("Sending %d messages to queue: %s", len(messages), request.QueueName)
 
-	if len(request.Messages) == 0 {
+	if len(messages) == 0 {
 		return nil, model.ServiceError.BadRequestError("No messages provided")
 	}
 
-	var failedMessages []string
-	successCount := 0
-
-	// Send each message individually
-	for i, message := range request.Messages {
-		err := sqs.TestSQS.SendMessage(ctx, message)
-		if err != nil {
-			logger.Error.Printf("Failed to send message %d to queue %s: %v", i, request.QueueName, err)
-			failedMessages = append(failedMessages, message)
-		} else {
-			successCount++
-		}
+	queue, serviceResp := resolveQueue(ctx, request.QueueName)
+	if serviceResp.Status != http.StatusOK {
+		return nil, serviceResp
 	}
 
 	response := &modelHttp.SendMessagesResponse{
-		Success:        len(failedMessages) == 0,
-		SuccessCount:   successCount,
-		FailedMessages: failedMessages,
+		Results: make([]modelHttp.SendMessageResult, len(messages)),
+	}
+	for i, result := range queue.SendMessageBatch(ctx, messages) {
+		response.Results[i] = modelHttp.SendMessageResult{Index: i, MessageID: result.MessageID}
+		if result.Err != nil {
+			logger.Error.Printf("Failed to send message %d to queue %s: %v", i, request.QueueName, result.Err)
+			response.Results[i].Error = result.Err.Error()
+			response.FailedMessages = append(response.FailedMessages, messages[i].Body)
+		} else {
+			response.SuccessCount++
+		}
 	}
+	response.Success = len(response.FailedMessages) == 0
 
-	if len(failedMessages) > 0 {
-		response.Error = fmt.Sprintf("Failed to send %d out of %d messages", len(failedMessages), len(request.Messages))
-		logger.Warn.Printf("Partially failed to send messages to queue %s: %d failed, %d succeeded", request.QueueName, len(failedMessages), successCount)
+	if len(response.FailedMessages) > 0 {
+		response.Error = fmt.Sprintf("Failed to send %d out of %d messages", len(response.FailedMessages), len(messages))
+		logger.Warn.Printf("Partially failed to send messages to queue %s: %d failed, %d succeeded", request.QueueName, len(response.FailedMessages), response.SuccessCount)
 	} else {
-		logger.Info.Printf("Successfully sent all %d messages to queue: %s", len(request.Messages), request.QueueName)
+		logger.Info.Printf("Successfully sent all %d messages to queue: %s", len(messages), request.QueueName)
 	}
 
 	return response, model.ServiceError.OK
 }
 
-// generateMessageID generates a simple message ID
-// In production, you might want to get the actual message ID from AWS SQS response
-func generateMessageID() string {
-	// This is a simple implementation - you might want to use UUID or get actual AWS message ID
-	return fmt.Sprintf("msg-%d", getCurrentTimestamp())
+func toOutgoingMessage(body string, options modelHttp.MessageOptions) sqs.OutgoingMessage {
+	return sqs.OutgoingMessage{
+		Body:                   body,
+		Attributes:             options.Attributes,
+		DelaySeconds:           options.DelaySeconds,
+		MessageGroupID:         options.MessageGroupID,
+		MessageDeduplicationID: options.MessageDeduplicationID,
+	}
+}
+
+// resolveQueue looks up a queue /sqs may send to, queues that aren't in the send allow-list
+// or don't exist are rejected as bad requests
+func resolveQueue(ctx context.Context, queueName string) (sqs.SQSAPI, model.ServiceResp) {
+	for _, name := range config.Env.SQSSendQueueNames() {
+		if name == queueName {
+			return lookupQueue(ctx, queueName)
+		}
+	}
+	logger.Error.Printf("Queue %s is not allowed", queueName)
+	return nil, model.ServiceError.BadRequestError(model.SQSQueueNotAllowed)
 }
 
-// getCurrentTimestamp returns current unix timestamp
-func getCurrentTimestamp() int64 {
-	return time.Now().Unix()
+// lookupQueue looks up any queue the service may resolve, queues that aren't allowed or
+// don't exist are rejected as bad requests
+func lookupQueue(ctx context.Context, queueName string) (sqs.SQSAPI, model.ServiceResp) {
+	queue, err := sqs.GetInstance().Queue(ctx, queueName)
+	if errors.Is(err, sqs.ErrQueueNotAllowed) {
+		logger.Error.Printf("Queue %s is not allowed", queueName)
+		return nil, model.ServiceError.BadRequestError(model.SQSQueueNotAllowed)
+	}
+	if errors.Is(err, sqs.ErrQueueNotFound) {
+		logger.Error.Printf("Queue %s does not exist", queueName)
+		return nil, model.ServiceError.BadRequestError(model.SQSQueueNotFound)
+	}
+	if err != nil {
+		logger.Error.Printf("Failed to resolve queue %s: %v", queueName, err)
+		return nil, model.ServiceError.InternalServiceError(model.SQSSendMessageFail)
+	}
+	return queue, model.ServiceError.OK
 }
@@ -2,22 +2,32 @@ package service
 
 import (
 	"context"
+	"errors"
 	"net/http"
 
 	externalAccount "go-base/internal/app/service/external/account"
 	externalVendor "go-base/internal/app/service/external/vendor"
+	"go-base/internal/pkg/aws/s3"
 	"go-base/internal/pkg/database"
 	"go-base/internal/pkg/logger"
 	"go-base/internal/pkg/model"
 	modelDB "go-base/internal/pkg/model/db"
 	modelHttp "go-base/internal/pkg/model/http"
 	"go-base/internal/pkg/util"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
 // CreateTodo creates a new todo item
 func CreateTodo(ctx context.Context, req modelHttp.CreateTodoRequest) (*modelDB.Todo, model.ServiceResp) {
 	currentTs := util.GetCurrentMilliseconds()
 
+	attachments, attachmentsServiceResp := resolveTodoAttachments(ctx, req.Attachments)
+	if attachmentsServiceResp.Status != http.StatusOK {
+		return nil, attachmentsServiceResp
+	}
+
 	token, authServiceResp := externalAccount.GetAuthToken()
 	if authServiceResp.Status != http.StatusOK {
 		return nil, model.ServiceError.FailedDependencyError(authServiceResp.ErrCode.Code)
@@ -41,6 +51,7 @@ func CreateTodo(ctx context.Context, req modelHttp.CreateTodoRequest) (*modelDB.
 		Completed:   false,
 		CreatedAt:   currentTs,
 		UpdatedAt:   currentTs,
+		Attachments: attachments,
 	}
 	err := database.InsertTodo(*todo)
 	if err != nil {
@@ -73,11 +84,17 @@ func GetTodo(ctx context.Context, id string) (modelDB.Todo, model.ServiceResp) {
 }
 
 func UpdateTodo(ctx context.Context, id string, req modelHttp.UpdateTodoRequest) model.ServiceResp {
+	attachments, attachmentsServiceResp := resolveTodoAttachments(ctx, req.Attachments)
+	if attachmentsServiceResp.Status != http.StatusOK {
+		return attachmentsServiceResp
+	}
+
 	todo := &modelDB.Todo{
 		Title:       req.Title,
 		Description: req.Description,
 		Completed:   req.Completed,
 		UpdatedAt:   util.GetCurrentMilliseconds(),
+		Attachments: attachments,
 	}
 
 	err := database.UpdateTodo(id, *todo)
@@ -96,3 +113,48 @@ func DeleteTodo(ctx context.Context, id string) model.ServiceResp {
 
 	return model.ServiceError.OK
 }
+
+// resolveTodoAttachments looks up the SHA-256 of every attachment. The checksum S3 stored at
+// upload is used when there is one, otherwise it is recomputed from the object. A checksum
+// sent by the caller has to match it.
+func resolveTodoAttachments(ctx context.Context, attachments []modelHttp.TodoAttachment) ([]modelDB.TodoAttachment, model.ServiceResp) {
+	var resolved []modelDB.TodoAttachment
+	for _, attachment := range attachments {
+		if attachment.ChecksumSHA256 != "" && !s3.ValidChecksumSHA256(attachment.ChecksumSHA256) {
+			return nil, model.ServiceError.BadRequestError(model.S3InvalidChecksum)
+		}
+
+		bucketName := s3.ResolveBucket(attachment.BucketName)
+		head, err := s3.GetInstance().GetHeadObject(ctx, bucketName, attachment.ObjectKey)
+		if err != nil {
+			var notFoundErr *types.NotFound
+			if errors.As(err, &notFoundErr) {
+				return nil, model.ServiceError.NotFoundError
+			}
+			logger.Error.Printf("resolveTodoAttachments head object fail, %+v", err)
+			return nil, model.ServiceError.InternalServiceError(model.S3VerifyObjectFail)
+		}
+
+		checksum := aws.ToString(head.ChecksumSHA256)
+		size := aws.ToInt64(head.ContentLength)
+		if !s3.ValidChecksumSHA256(checksum) {
+			computed, err := computeObjectChecksum(ctx, bucketName, attachment.ObjectKey, "")
+			if err != nil {
+				logger.Error.Printf("resolveTodoAttachments compute checksum fail, %+v", err)
+				return nil, model.ServiceError.InternalServiceError(model.S3VerifyObjectFail)
+			}
+			checksum, size = computed.computed, computed.size
+		}
+		if attachment.ChecksumSHA256 != "" && attachment.ChecksumSHA256 != checksum {
+			return nil, model.ServiceError.BadRequestError(model.S3ChecksumMismatch)
+		}
+
+		resolved = append(resolved, modelDB.TodoAttachment{
+			BucketName:     attachment.BucketName,
+			ObjectKey:      attachment.ObjectKey,
+			ChecksumSHA256: checksum,
+			Size:           size,
+		})
+	}
+	return resolved, model.ServiceError.OK
+}
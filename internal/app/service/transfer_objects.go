@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"go-base/internal/pkg/aws/s3"
+	"go-base/internal/pkg/job"
+	"go-base/internal/pkg/logger"
+	model "go-base/internal/pkg/model"
+	modelHttp "go-base/internal/pkg/model/http"
+)
+
+const (
+	JobTypeCopyObjects = "copy-objects"
+	JobTypeMoveObjects = "move-objects"
+	JobTypeSyncObjects = "sync-objects"
+)
+
+const (
+	// transferConcurrency bounds how many objects of a listing page are copied at once
+	transferConcurrency = 8
+	// maxPlannedItems caps how many dry run items are kept on the job result
+	maxPlannedItems = 1000
+)
+
+// transfer is one copy, move or sync between two prefixes
+type transfer struct {
+	jobType           string
+	sourceBucket      string
+	sourcePrefix      string
+	destinationBucket string
+	destinationPrefix string
+	dryRun            bool
+	options           s3.ObjectOptions
+}
+
+// transferOutcome is what happened to a single source object
+type transferOutcome struct {
+	item modelHttp.TransferItem
+	err  error
+}
+
+func CopyObjects(ctx context.Context, req modelHttp.TransferObjectsRequest) (modelHttp.TransferObjectsResponse, model.ServiceResp) {
+	return startTransfer(ctx, JobTypeCopyObjects, req)
+}
+
+// MoveObjects copies like CopyObjects and then deletes each source that was copied
+func MoveObjects(ctx context.Context, req modelHttp.TransferObjectsRequest) (modelHttp.TransferObjectsResponse, model.ServiceResp) {
+	return startTransfer(ctx, JobTypeMoveObjects, req)
+}
+
+// SyncObjects copies only the objects missing from the destination or different from it
+func SyncObjects(ctx context.Context, req modelHttp.TransferObjectsRequest) (modelHttp.TransferObjectsResponse, model.ServiceResp) {
+	return startTransfer(ctx, JobTypeSyncObjects, req)
+}
+
+func startTransfer(ctx context.Context, jobType string, req modelHttp.TransferObjectsRequest) (modelHttp.TransferObjectsResponse, model.ServiceResp) {
+	t := transfer{
+		jobType:           jobType,
+		sourceBucket:      s3.ResolveBucket(req.SourceBucket),
+		sourcePrefix:      req.SourcePrefix,
+		destinationBucket: s3.ResolveBucket(req.DestinationBucket),
+		destinationPrefix: req.DestinationPrefix,
+		dryRun:            req.DryRun,
+		options:           toObjectOptions(req.ObjectOptions, ""),
+	}
+
+	// Overlapping prefixes in one bucket would list the objects the job itself writes
+	if t.sourceBucket == t.destinationBucket &&
+		(strings.HasPrefix(t.sourcePrefix, t.destinationPrefix) || strings.HasPrefix(t.destinationPrefix, t.sourcePrefix)) {
+		return modelHttp.TransferObjectsResponse{}, model.ServiceError.BadRequestError(model.S3InvalidTransfer)
+	}
+
+	for _, bucketName := range []string{t.sourceBucket, t.destinationBucket} {
+		if _, err := s3.GetInstance().ListObjects(ctx, bucketName, "", "", "", 1); err != nil {
+			if errors.Is(err, s3.ErrBucketNotFound) {
+				return modelHttp.TransferObjectsResponse{}, model.ServiceError.NotFoundError
+			}
+			return modelHttp.TransferObjectsResponse{}, model.ServiceError.InternalServiceError(model.S3TransferObjectsFail)
+		}
+	}
+
+	started := job.Start(jobType, func(ctx context.Context, progress *job.Progress) (interface{}, error) {
+		return t.run(ctx, progress)
+	})
+	logger.Info.Printf("%s: %s/%s -> %s/%s, dry_run=%v, job=%s", jobType, t.sourceBucket, t.sourcePrefix, t.destinationBucket, t.destinationPrefix, t.dryRun, started.ID)
+
+	return modelHttp.TransferObjectsResponse{JobID: started.ID}, model.ServiceError.OK
+}
+
+func (t transfer) run(ctx context.Context, progress *job.Progress) (modelHttp.TransferObjectsResult, error) {
+	result := modelHttp.TransferObjectsResult{DryRun: t.dryRun}
+
+	var existing map[string]s3.ObjectSummary
+	if t.jobType == JobTypeSyncObjects {
+		var err error
+		if existing, err = listAllObjects(ctx, t.destinationBucket, t.destinationPrefix); err != nil {
+			return result, err
+		}
+	}
+
+	token := ""
+	for {
+		page, err := s3.GetInstance().ListObjects(ctx, t.sourceBucket, t.sourcePrefix, "", token, s3.MaxDeleteBatchSize)
+		if err != nil {
+			return result, err
+		}
+		progress.AddTotal(len(page.Objects))
+
+		failed := 0
+		var copiedKeys []string
+		for _, outcome := range t.transferPage(ctx, page.Objects, existing) {
+			switch {
+			case outcome.err != nil:
+				failed++
+				result.Errors = append(result.Errors, modelHttp.TransferError{SourceKey: outcome.item.SourceKey, Message: outcome.err.Error()})
+			case outcome.item.Action == "skip":
+				result.Skipped++
+			default:
+				result.Copied++
+				copiedKeys = append(copiedKeys, outcome.item.SourceKey)
+			}
+			if t.dryRun && len(result.Planned) < maxPlannedItems {
+				result.Planned = append(result.Planned, outcome.item)
+			}
+		}
+
+		if t.jobType == JobTypeMoveObjects && !t.dryRun && len(copiedKeys) > 0 {
+			deleteResult, err := s3.GetInstance().DeleteObjects(ctx, t.sourceBucket, copiedKeys)
+			if err != nil {
+				return result, err
+			}
+			result.Deleted += len(deleteResult.Deleted)
+			for _, deleteError := range deleteResult.Errors {
+				failed++
+				result.Errors = append(result.Errors, modelHttp.TransferError{SourceKey: deleteError.Key, Message: "copied but not deleted: " + deleteError.Message})
+			}
+		}
+
+		result.Failed += failed
+		progress.Add(len(page.Objects), failed)
+
+		if !page.IsTruncated {
+			break
+		}
+		token = page.NextContinuationToken
+	}
+
+	return result, nil
+}
+
+// transferPage copies the objects of one listing page with up to transferConcurrency
+// copies in flight. Outcomes keep the order of the page.
+func (t transfer) transferPage(ctx context.Context, objects []s3.ObjectSummary, existing map[string]s3.ObjectSummary) []transferOutcome {
+	outcomes := make([]transferOutcome, len(objects))
+	semaphore := make(chan struct{}, transferConcurrency)
+	var wg sync.WaitGroup
+	for i, object := range objects {
+		destinationKey := t.destinationPrefix + strings.TrimPrefix(object.Key, t.sourcePrefix)
+		outcomes[i].item = modelHttp.TransferItem{SourceKey: object.Key, DestinationKey: destinationKey, Action: "copy"}
+		if destination, ok := existing[destinationKey]; ok && upToDate(object, destination) {
+			outcomes[i].item.Action = "skip"
+			continue
+		}
+		if t.dryRun {
+			continue
+		}
+
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, object s3.ObjectSummary) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			outcomes[i].err = t.copyObject(ctx, object, outcomes[i].item.DestinationKey)
+		}(i, object)
+	}
+	wg.Wait()
+	return outcomes
+}
+
+func (t transfer) copyObject(ctx context.Context, object s3.ObjectSummary, destinationKey string) error {
+	if object.Size > s3.MaxCopyObjectSize {
+		return s3.GetInstance().CopyLargeObject(ctx, t.sourceBucket, object.Key, t.destinationBucket, destinationKey, object.Size, t.options)
+	}
+	return s3.GetInstance().CopyObject(ctx, t.sourceBucket, object.Key, t.destinationBucket, destinationKey, t.options)
+}
+
+// upToDate reports whether a sync can skip source because destination already matches it.
+// Multipart copies get a new ETag, so for those a same-size destination that is not older
+// than the source counts as current.
+func upToDate(source s3.ObjectSummary, destination s3.ObjectSummary) bool {
+	if source.Size != destination.Size {
+		return false
+	}
+	if source.ETag == destination.ETag {
+		return true
+	}
+	if strings.Contains(source.ETag, "-") || strings.Contains(destination.ETag, "-") {
+		return !destination.LastModified.Before(source.LastModified)
+	}
+	return false
+}
+
+// listAllObjects returns every object under prefix keyed by its key
+func listAllObjects(ctx context.Context, bucketName string, prefix string) (map[string]s3.ObjectSummary, error) {
+	objects := map[string]s3.ObjectSummary{}
+	token := ""
+	for {
+		page, err := s3.GetInstance().ListObjects(ctx, bucketName, prefix, "", token, s3.MaxDeleteBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range page.Objects {
+			objects[object.Key] = object
+		}
+		if !page.IsTruncated {
+			return objects, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
@@ -0,0 +1,46 @@
+package endpoint
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+// Config points an AWS client at an S3/SQS compatible service such as MinIO or
+// LocalStack. The zero value keeps the default AWS endpoints and credential chain.
+type Config struct {
+	URL             string // e.g. http://localhost:4566, the scheme defaults to https
+	AccessKeyID     string // static credentials, used instead of the default chain when set
+	SecretAccessKey string
+	DisableTLS      bool
+}
+
+// LoadOptions returns the options for config.LoadDefaultConfig
+func (c Config) LoadOptions() []func(*config.LoadOptions) error {
+	var options []func(*config.LoadOptions) error
+	if c.AccessKeyID != "" {
+		options = append(options, config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(c.AccessKeyID, c.SecretAccessKey, ""),
+		))
+	}
+	return options
+}
+
+// BaseEndpoint returns the URL the client should send requests to, nil for the
+// default AWS endpoint
+func (c Config) BaseEndpoint() *string {
+	if c.URL == "" {
+		return nil
+	}
+
+	url := c.URL
+	if !strings.Contains(url, "://") {
+		url = "https://" + url
+	}
+	if c.DisableTLS && strings.HasPrefix(url, "https://") {
+		url = "http://" + strings.TrimPrefix(url, "https://")
+	}
+	return aws.String(strings.TrimSuffix(url, "/"))
+}
@@ -0,0 +1,256 @@
+package s3
+
+import (
+	"context"
+	"errors"
+
+	"go-base/internal/pkg/logger"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3SDK "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// LifecycleRule is one bucket lifecycle rule. Zero day counts leave the action out.
+type LifecycleRule struct {
+	ID                                 string
+	Prefix                             string
+	Enabled                            bool
+	ExpirationDays                     int32
+	NoncurrentVersionExpirationDays    int32
+	AbortIncompleteMultipartUploadDays int32
+	Transitions                        []LifecycleTransition
+}
+
+// LifecycleTransition moves objects to StorageClass Days after they were created
+type LifecycleTransition struct {
+	Days         int32
+	StorageClass string
+}
+
+// CORSRule is one bucket CORS rule
+type CORSRule struct {
+	ID             string
+	AllowedOrigins []string
+	AllowedMethods []string
+	AllowedHeaders []string
+	ExposeHeaders  []string
+	MaxAgeSeconds  int32
+}
+
+// PublicAccessBlock holds the four public access block switches of a bucket
+type PublicAccessBlock struct {
+	BlockPublicAcls       bool
+	IgnorePublicAcls      bool
+	BlockPublicPolicy     bool
+	RestrictPublicBuckets bool
+}
+
+// GetBucketLifecycle returns the lifecycle rules of a bucket, none when it has no configuration
+func (manager BaseS3API) GetBucketLifecycle(ctx context.Context, bucketName string) ([]LifecycleRule, error) {
+	output, err := manager.client.GetBucketLifecycleConfiguration(ctx, &s3SDK.GetBucketLifecycleConfigurationInput{
+		Bucket: aws.String(bucketName),
+	})
+	if err != nil {
+		if isErrorCode(err, "NoSuchLifecycleConfiguration") {
+			return []LifecycleRule{}, nil
+		}
+		return nil, convertBucketError("GetBucketLifecycleConfiguration", err)
+	}
+
+	rules := make([]LifecycleRule, 0, len(output.Rules))
+	for _, sdkRule := range output.Rules {
+		rule := LifecycleRule{
+			ID:      aws.ToString(sdkRule.ID),
+			Prefix:  aws.ToString(sdkRule.Prefix),
+			Enabled: sdkRule.Status == types.ExpirationStatusEnabled,
+		}
+		if sdkRule.Filter != nil && sdkRule.Filter.Prefix != nil {
+			rule.Prefix = aws.ToString(sdkRule.Filter.Prefix)
+		}
+		if sdkRule.Expiration != nil {
+			rule.ExpirationDays = aws.ToInt32(sdkRule.Expiration.Days)
+		}
+		if sdkRule.NoncurrentVersionExpiration != nil {
+			rule.NoncurrentVersionExpirationDays = aws.ToInt32(sdkRule.NoncurrentVersionExpiration.NoncurrentDays)
+		}
+		if sdkRule.AbortIncompleteMultipartUpload != nil {
+			rule.AbortIncompleteMultipartUploadDays = aws.ToInt32(sdkRule.AbortIncompleteMultipartUpload.DaysAfterInitiation)
+		}
+		for _, transition := range sdkRule.Transitions {
+			rule.Transitions = append(rule.Transitions, LifecycleTransition{
+				Days:         aws.ToInt32(transition.Days),
+				StorageClass: string(transition.StorageClass),
+			})
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// PutBucketLifecycle replaces the lifecycle rules of a bucket. No rules removes the configuration.
+func (manager BaseS3API) PutBucketLifecycle(ctx context.Context, bucketName string, rules []LifecycleRule) error {
+	if len(rules) == 0 {
+		_, err := manager.client.DeleteBucketLifecycle(ctx, &s3SDK.DeleteBucketLifecycleInput{
+			Bucket: aws.String(bucketName),
+		})
+		if err != nil {
+			return convertBucketError("DeleteBucketLifecycle", err)
+		}
+		return nil
+	}
+
+	sdkRules := make([]types.LifecycleRule, 0, len(rules))
+	for _, rule := range rules {
+		sdkRule := types.LifecycleRule{
+			ID:     aws.String(rule.ID),
+			Status: types.ExpirationStatusDisabled,
+			Filter: &types.LifecycleRuleFilter{Prefix: aws.String(rule.Prefix)},
+		}
+		if rule.Enabled {
+			sdkRule.Status = types.ExpirationStatusEnabled
+		}
+		if rule.ExpirationDays > 0 {
+			sdkRule.Expiration = &types.LifecycleExpiration{Days: aws.Int32(rule.ExpirationDays)}
+		}
+		if rule.NoncurrentVersionExpirationDays > 0 {
+			sdkRule.NoncurrentVersionExpiration = &types.NoncurrentVersionExpiration{NoncurrentDays: aws.Int32(rule.NoncurrentVersionExpirationDays)}
+		}
+		if rule.AbortIncompleteMultipartUploadDays > 0 {
+			sdkRule.AbortIncompleteMultipartUpload = &types.AbortIncompleteMultipartUpload{DaysAfterInitiation: aws.Int32(rule.AbortIncompleteMultipartUploadDays)}
+		}
+		for _, transition := range rule.Transitions {
+			sdkRule.Transitions = append(sdkRule.Transitions, types.Transition{
+				Days:         aws.Int32(transition.Days),
+				StorageClass: types.TransitionStorageClass(transition.StorageClass),
+			})
+		}
+		sdkRules = append(sdkRules, sdkRule)
+	}
+
+	_, err := manager.client.PutBucketLifecycleConfiguration(ctx, &s3SDK.PutBucketLifecycleConfigurationInput{
+		Bucket:                 aws.String(bucketName),
+		LifecycleConfiguration: &types.BucketLifecycleConfiguration{Rules: sdkRules},
+	})
+	if err != nil {
+		return convertBucketError("PutBucketLifecycleConfiguration", err)
+	}
+	return nil
+}
+
+// GetBucketCORS returns the CORS rules of a bucket, none when it has no configuration
+func (manager BaseS3API) GetBucketCORS(ctx context.Context, bucketName string) ([]CORSRule, error) {
+	output, err := manager.client.GetBucketCors(ctx, &s3SDK.GetBucketCorsInput{
+		Bucket: aws.String(bucketName),
+	})
+	if err != nil {
+		if isErrorCode(err, "NoSuchCORSConfiguration") {
+			return []CORSRule{}, nil
+		}
+		return nil, convertBucketError("GetBucketCors", err)
+	}
+
+	rules := make([]CORSRule, 0, len(output.CORSRules))
+	for _, sdkRule := range output.CORSRules {
+		rules = append(rules, CORSRule{
+			ID:             aws.ToString(sdkRule.ID),
+			AllowedOrigins: sdkRule.AllowedOrigins,
+			AllowedMethods: sdkRule.AllowedMethods,
+			AllowedHeaders: sdkRule.AllowedHeaders,
+			ExposeHeaders:  sdkRule.ExposeHeaders,
+			MaxAgeSeconds:  aws.ToInt32(sdkRule.MaxAgeSeconds),
+		})
+	}
+	return rules, nil
+}
+
+// PutBucketCORS replaces the CORS rules of a bucket. No rules removes the configuration.
+func (manager BaseS3API) PutBucketCORS(ctx context.Context, bucketName string, rules []CORSRule) error {
+	if len(rules) == 0 {
+		_, err := manager.client.DeleteBucketCors(ctx, &s3SDK.DeleteBucketCorsInput{
+			Bucket: aws.String(bucketName),
+		})
+		if err != nil {
+			return convertBucketError("DeleteBucketCors", err)
+		}
+		return nil
+	}
+
+	sdkRules := make([]types.CORSRule, 0, len(rules))
+	for _, rule := range rules {
+		sdkRule := types.CORSRule{
+			ID:             optionalString(rule.ID),
+			AllowedOrigins: rule.AllowedOrigins,
+			AllowedMethods: rule.AllowedMethods,
+			AllowedHeaders: rule.AllowedHeaders,
+			ExposeHeaders:  rule.ExposeHeaders,
+		}
+		if rule.MaxAgeSeconds > 0 {
+			sdkRule.MaxAgeSeconds = aws.Int32(rule.MaxAgeSeconds)
+		}
+		sdkRules = append(sdkRules, sdkRule)
+	}
+
+	_, err := manager.client.PutBucketCors(ctx, &s3SDK.PutBucketCorsInput{
+		Bucket:            aws.String(bucketName),
+		CORSConfiguration: &types.CORSConfiguration{CORSRules: sdkRules},
+	})
+	if err != nil {
+		return convertBucketError("PutBucketCors", err)
+	}
+	return nil
+}
+
+// GetPublicAccessBlock returns the public access block of a bucket, everything off when it has none
+func (manager BaseS3API) GetPublicAccessBlock(ctx context.Context, bucketName string) (*PublicAccessBlock, error) {
+	output, err := manager.client.GetPublicAccessBlock(ctx, &s3SDK.GetPublicAccessBlockInput{
+		Bucket: aws.String(bucketName),
+	})
+	if err != nil {
+		if isErrorCode(err, "NoSuchPublicAccessBlockConfiguration") {
+			return &PublicAccessBlock{}, nil
+		}
+		return nil, convertBucketError("GetPublicAccessBlock", err)
+	}
+
+	block := &PublicAccessBlock{}
+	if configuration := output.PublicAccessBlockConfiguration; configuration != nil {
+		block.BlockPublicAcls = aws.ToBool(configuration.BlockPublicAcls)
+		block.IgnorePublicAcls = aws.ToBool(configuration.IgnorePublicAcls)
+		block.BlockPublicPolicy = aws.ToBool(configuration.BlockPublicPolicy)
+		block.RestrictPublicBuckets = aws.ToBool(configuration.RestrictPublicBuckets)
+	}
+	return block, nil
+}
+
+func (manager BaseS3API) PutPublicAccessBlock(ctx context.Context, bucketName string, block PublicAccessBlock) error {
+	_, err := manager.client.PutPublicAccessBlock(ctx, &s3SDK.PutPublicAccessBlockInput{
+		Bucket: aws.String(bucketName),
+		PublicAccessBlockConfiguration: &types.PublicAccessBlockConfiguration{
+			BlockPublicAcls:       aws.Bool(block.BlockPublicAcls),
+			IgnorePublicAcls:      aws.Bool(block.IgnorePublicAcls),
+			BlockPublicPolicy:     aws.Bool(block.BlockPublicPolicy),
+			RestrictPublicBuckets: aws.Bool(block.RestrictPublicBuckets),
+		},
+	})
+	if err != nil {
+		return convertBucketError("PutPublicAccessBlock", err)
+	}
+	return nil
+}
+
+func isErrorCode(err error, code string) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == code
+}
+
+func convertBucketError(operation string, err error) error {
+	var noSuchBucket *types.NoSuchBucket
+	if errors.As(err, &noSuchBucket) || isErrorCode(err, "NoSuchBucket") {
+		return ErrBucketNotFound
+	}
+
+	logger.Error.Printf("%s fail, %+v\n", operation, err)
+	return err
+}
@@ -0,0 +1,75 @@
+package s3
+
+import (
+	"sort"
+	"sync"
+)
+
+// Named buckets used by the application, mapped onto real bucket names by SetBuckets
+const (
+	BucketIcons       = "icons"
+	BucketAttachments = "attachments"
+	BucketExports     = "exports"
+)
+
+var (
+	bucketsMu     sync.RWMutex
+	buckets       = map[string]string{}
+	defaultBucket string
+)
+
+// SetBuckets registers the real bucket behind each name. Names without an entry resolve
+// to fallback.
+func SetBuckets(named map[string]string, fallback string) {
+	bucketsMu.Lock()
+	defer bucketsMu.Unlock()
+	buckets = make(map[string]string, len(named))
+	for name, bucketName := range named {
+		buckets[name] = bucketName
+	}
+	defaultBucket = fallback
+}
+
+// Bucket returns the bucket registered for name, or the default bucket
+func Bucket(name string) string {
+	bucketsMu.RLock()
+	defer bucketsMu.RUnlock()
+	if bucketName, ok := buckets[name]; ok {
+		return bucketName
+	}
+	return defaultBucket
+}
+
+// ResolveBucket maps a bucket name taken from a request onto a real bucket. Registered
+// names are translated, an empty name is the default bucket, anything else is used as is.
+func ResolveBucket(name string) string {
+	if name == "" {
+		return Bucket("")
+	}
+	bucketsMu.RLock()
+	defer bucketsMu.RUnlock()
+	if bucketName, ok := buckets[name]; ok {
+		return bucketName
+	}
+	return name
+}
+
+// Buckets returns every distinct real bucket, the default one included
+func Buckets() []string {
+	bucketsMu.RLock()
+	defer bucketsMu.RUnlock()
+	seen := map[string]bool{"": true}
+	var bucketNames []string
+	add := func(bucketName string) {
+		if !seen[bucketName] {
+			seen[bucketName] = true
+			bucketNames = append(bucketNames, bucketName)
+		}
+	}
+	add(defaultBucket)
+	for _, bucketName := range buckets {
+		add(bucketName)
+	}
+	sort.Strings(bucketNames)
+	return bucketNames
+}
@@ -0,0 +1,87 @@
+package s3
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+
+	"go-base/internal/pkg/logger"
+)
+
+// ChecksumSHA256 returns the SHA-256 of data encoded the way S3 expects it in
+// x-amz-checksum-sha256, as base64
+func ChecksumSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// ValidChecksumSHA256 reports whether checksum is a base64 encoded SHA-256
+func ValidChecksumSHA256(checksum string) bool {
+	decoded, err := base64.StdEncoding.DecodeString(checksum)
+	return err == nil && len(decoded) == sha256.Size
+}
+
+// fullObjectChecksum reports whether checksum covers the whole body. Multipart uploads
+// get a checksum of their part checksums, e.g. "...-3", which a download can't recompute.
+func fullObjectChecksum(checksum string) bool {
+	return checksum != "" && !strings.Contains(checksum, "-")
+}
+
+// verifyChecksum compares data against the checksum S3 stored with the object
+func verifyChecksum(data []byte, checksum string) error {
+	if !fullObjectChecksum(checksum) {
+		return nil
+	}
+	if actual := ChecksumSHA256(data); actual != checksum {
+		logger.Error.Printf("checksum mismatch, expected %s, actual %s", checksum, actual)
+		return fmt.Errorf("%w: expected %s, actual %s", ErrChecksumMismatch, checksum, actual)
+	}
+	return nil
+}
+
+// convertChecksumError maps the error the SDK returns when it validates a response
+// checksum itself. Its type is internal to the SDK, only the message identifies it.
+func convertChecksumError(err error) error {
+	if err != nil && !errors.Is(err, ErrChecksumMismatch) && strings.Contains(err.Error(), "checksum did not match") {
+		logger.Error.Printf("checksum mismatch, %v", err)
+		return fmt.Errorf("%w: %v", ErrChecksumMismatch, err)
+	}
+	return err
+}
+
+// checksumReader hashes a body while it is read and fails the final read with
+// ErrChecksumMismatch instead of io.EOF when the hash doesn't match
+type checksumReader struct {
+	body     io.ReadCloser
+	hash     hash.Hash
+	checksum string
+}
+
+// verifiedBody wraps body so reading it to the end verifies checksum. Bodies without a
+// full object checksum are returned as they are.
+func verifiedBody(body io.ReadCloser, checksum string) io.ReadCloser {
+	if !fullObjectChecksum(checksum) {
+		return body
+	}
+	return &checksumReader{body: body, hash: sha256.New(), checksum: checksum}
+}
+
+func (r *checksumReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	r.hash.Write(p[:n])
+	if err == io.EOF {
+		if actual := base64.StdEncoding.EncodeToString(r.hash.Sum(nil)); actual != r.checksum {
+			logger.Error.Printf("checksum mismatch, expected %s, actual %s", r.checksum, actual)
+			return n, fmt.Errorf("%w: expected %s, actual %s", ErrChecksumMismatch, r.checksum, actual)
+		}
+	}
+	return n, convertChecksumError(err)
+}
+
+func (r *checksumReader) Close() error {
+	return r.body.Close()
+}
@@ -0,0 +1,123 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go-base/internal/pkg/logger"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3SDK "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	// MaxDeleteBatchSize is the most keys S3 accepts in one DeleteObjects request
+	MaxDeleteBatchSize = 1000
+	// deleteConcurrency bounds how many DeleteObjects requests run at once
+	deleteConcurrency = 4
+)
+
+// DeleteObjectsResult reports every requested key, either as deleted or with the reason it wasn't
+type DeleteObjectsResult struct {
+	Deleted []string
+	Errors  []DeleteError
+}
+
+type DeleteError struct {
+	Key     string
+	Code    string
+	Message string
+}
+
+// DeleteObjects deletes keys in batches of MaxDeleteBatchSize. A batch that fails as a whole
+// reports each of its keys as an error, so the error return is only set when the bucket is
+// missing or ctx is done.
+func (manager BaseS3API) DeleteObjects(ctx context.Context, bucketName string, keys []string) (*DeleteObjectsResult, error) {
+	return deleteInBatches(ctx, keys, func(ctx context.Context, batch []string) (*DeleteObjectsResult, error) {
+		objectIds := make([]types.ObjectIdentifier, 0, len(batch))
+		for _, key := range batch {
+			objectIds = append(objectIds, types.ObjectIdentifier{Key: aws.String(key)})
+		}
+
+		output, err := manager.client.DeleteObjects(ctx, &s3SDK.DeleteObjectsInput{
+			Bucket: aws.String(bucketName),
+			Delete: &types.Delete{Objects: objectIds},
+		})
+		if err != nil {
+			return nil, convertBucketError("DeleteObjects", err)
+		}
+
+		result := &DeleteObjectsResult{}
+		for _, deleted := range output.Deleted {
+			result.Deleted = append(result.Deleted, aws.ToString(deleted.Key))
+		}
+		for _, deleteError := range output.Errors {
+			result.Errors = append(result.Errors, DeleteError{
+				Key:     aws.ToString(deleteError.Key),
+				Code:    aws.ToString(deleteError.Code),
+				Message: aws.ToString(deleteError.Message),
+			})
+		}
+		return result, nil
+	})
+}
+
+// deleteInBatches splits keys into batches and runs deleteBatch on up to deleteConcurrency of
+// them at a time. Results keep the order of the batches.
+func deleteInBatches(ctx context.Context, keys []string, deleteBatch func(ctx context.Context, batch []string) (*DeleteObjectsResult, error)) (*DeleteObjectsResult, error) {
+	var batches [][]string
+	for start := 0; start < len(keys); start += MaxDeleteBatchSize {
+		end := start + MaxDeleteBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		batches = append(batches, keys[start:end])
+	}
+
+	results := make([]*DeleteObjectsResult, len(batches))
+	errs := make([]error, len(batches))
+	semaphore := make(chan struct{}, deleteConcurrency)
+	var wg sync.WaitGroup
+	for i, batch := range batches {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, batch []string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			results[i], errs[i] = deleteBatch(ctx, batch)
+		}(i, batch)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &DeleteObjectsResult{Deleted: []string{}, Errors: []DeleteError{}}
+	for i, batch := range batches {
+		if errors.Is(errs[i], ErrBucketNotFound) {
+			return nil, ErrBucketNotFound
+		}
+		if errs[i] != nil {
+			logger.Error.Printf("DeleteObjects batch of %d keys fail, %+v\n", len(batch), errs[i])
+			for _, key := range batch {
+				result.Errors = append(result.Errors, DeleteError{Key: key, Code: errorCode(errs[i]), Message: errs[i].Error()})
+			}
+			continue
+		}
+		result.Deleted = append(result.Deleted, results[i].Deleted...)
+		result.Errors = append(result.Errors, results[i].Errors...)
+	}
+	return result, nil
+}
+
+func errorCode(err error) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+	return "InternalError"
+}
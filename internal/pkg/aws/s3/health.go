@@ -0,0 +1,27 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go-base/internal/pkg/health"
+)
+
+// HealthChecker reports whether bucketName can be reached with HeadBucket
+func HealthChecker(bucketName string) health.HealthChecker {
+	return health.NewChecker("s3", func(ctx context.Context) error {
+		api := GetInstance()
+		if api == nil {
+			return errors.New("s3 not set up")
+		}
+		exists, err := api.BucketExists(ctx, bucketName)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("bucket %s not found", bucketName)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,114 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go-base/internal/pkg/logger"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3SDK "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const (
+	// MaxCopyObjectSize is the largest object a single CopyObject request can copy
+	MaxCopyObjectSize int64 = 5 * 1024 * 1024 * 1024
+	// minCopyPartSize keeps the part count low, S3 allows at most maxUploadParts parts
+	minCopyPartSize int64 = 512 * 1024 * 1024
+	maxUploadParts  int64 = 10000
+	// copyPartConcurrency bounds how many UploadPartCopy requests run at once per object
+	copyPartConcurrency = 4
+)
+
+// CopyLargeObject copies an object of the given size with a multipart upload made of
+// UploadPartCopy ranges. Use it for objects over MaxCopyObjectSize. Like CopyObject, the
+// content type and metadata of the source are kept unless options set them.
+func (manager BaseS3API) CopyLargeObject(ctx context.Context, sourceBucket string, sourceKey string, destBucket string, destKey string, size int64, options ObjectOptions) error {
+	if options.ContentType == "" && options.Metadata == nil {
+		head, err := manager.client.HeadObject(ctx, &s3SDK.HeadObjectInput{
+			Bucket: aws.String(sourceBucket),
+			Key:    aws.String(sourceKey),
+		})
+		if err != nil {
+			return convertObjectError(err)
+		}
+		options.ContentType = aws.ToString(head.ContentType)
+		options.Metadata = head.Metadata
+	}
+
+	uploadID, err := manager.CreateMultipartUpload(ctx, destBucket, destKey, options)
+	if err != nil {
+		return err
+	}
+
+	partSize := copyPartSize(size)
+	partCount := (size + partSize - 1) / partSize
+	parts := make([]types.CompletedPart, partCount)
+	errs := make([]error, partCount)
+	semaphore := make(chan struct{}, copyPartConcurrency)
+	var wg sync.WaitGroup
+	for i := int64(0); i < partCount; i++ {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int64) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			start := i * partSize
+			end := start + partSize - 1
+			if end >= size {
+				end = size - 1
+			}
+			output, err := manager.client.UploadPartCopy(ctx, &s3SDK.UploadPartCopyInput{
+				Bucket:          aws.String(destBucket),
+				Key:             aws.String(destKey),
+				UploadId:        aws.String(uploadID),
+				PartNumber:      aws.Int32(int32(i + 1)),
+				CopySource:      aws.String(copySource(sourceBucket, sourceKey, "")),
+				CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+			})
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			parts[i] = types.CompletedPart{
+				ETag:       output.CopyPartResult.ETag,
+				PartNumber: aws.Int32(int32(i + 1)),
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			logger.Error.Printf("UploadPartCopy fail, %+v\n", err)
+			manager.abortCopy(destBucket, destKey, uploadID)
+			return err
+		}
+	}
+
+	if err := manager.CompleteMultipartUpload(ctx, destBucket, destKey, uploadID, parts); err != nil {
+		logger.Error.Printf("CompleteMultipartUpload fail, upload_id=%s, %+v\n", uploadID, err)
+		manager.abortCopy(destBucket, destKey, uploadID)
+		return err
+	}
+	return nil
+}
+
+// abortCopy aborts the upload of a failed copy, so its parts aren't billed until the
+// cleanup worker removes them. It doesn't use the copy's ctx, which may be done already.
+func (manager BaseS3API) abortCopy(destBucket string, destKey string, uploadID string) {
+	if err := manager.AbortMultipartUpload(context.Background(), destBucket, destKey, uploadID); err != nil {
+		logger.Error.Printf("AbortMultipartUpload fail, upload_id=%s, %+v\n", uploadID, err)
+	}
+}
+
+func copyPartSize(size int64) int64 {
+	partSize := (size + maxUploadParts - 1) / maxUploadParts
+	if partSize < minCopyPartSize {
+		return minCopyPartSize
+	}
+	return partSize
+}
@@ -4,12 +4,19 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
 	"time"
 
+	"go-base/internal/pkg/aws/endpoint"
 	"go-base/internal/pkg/logger"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	s3SDK "github.com/aws/aws-sdk-go-v2/service/s3"
@@ -18,27 +25,163 @@ import (
 )
 
 type S3API interface {
-	PresignPutURL(key string, contentType string) (string, error)
-	PresignGetURL(key string) (string, error)
-	GetHeadObject(key string) (*s3SDK.HeadObjectOutput, error)
-	DeleteObjects(keys []string) (*s3SDK.DeleteObjectsOutput, error)
-	CheckObjectExists(key string) (bool, error)
+	PresignPutURL(ctx context.Context, bucketName string, key string, options ObjectOptions) (string, error)
+	PresignGetURL(ctx context.Context, bucketName string, key string) (string, error)
+	PresignPostObject(ctx context.Context, bucketName string, objectKey string, policy PostPolicy) (*PresignedPost, error)
+	GetHeadObject(ctx context.Context, bucketName string, key string) (*s3SDK.HeadObjectOutput, error)
+	// DeleteObjects takes any number of keys and reports the outcome of each one
+	DeleteObjects(ctx context.Context, bucketName string, keys []string) (*DeleteObjectsResult, error)
+	CheckObjectExists(ctx context.Context, bucketName string, key string) (bool, error)
 	// New simplified methods
-	ListBuckets() ([]types.Bucket, error)
-	BucketExists(bucketName string) (bool, error)
-	CreateBucket(name string, region string) error
-	UploadFile(bucketName string, objectKey string, fileContent []byte) error
-	DownloadFile(bucketName string, objectKey string) ([]byte, error)
-	CopyObject(sourceBucket string, sourceKey string, destBucket string, destKey string) error
-	ListObjects(bucketName string) (interface{}, error)
-	DeleteObjectsFromBucket(bucketName string, objectKeys []string) error
-	DeleteBucket(bucketName string) error
+	ListBuckets(ctx context.Context) ([]types.Bucket, error)
+	BucketExists(ctx context.Context, bucketName string) (bool, error)
+	CreateBucket(ctx context.Context, name string, region string) error
+	UploadFile(ctx context.Context, bucketName string, objectKey string, fileContent []byte, options ObjectOptions) error
+	DownloadFile(ctx context.Context, bucketName string, objectKey string) ([]byte, error)
+	GetObjectStream(ctx context.Context, bucketName string, objectKey string, options GetObjectOptions) (*ObjectStream, error)
+	// Multipart upload, the parts themselves are PUT by the client through presigned URLs
+	CreateMultipartUpload(ctx context.Context, bucketName string, objectKey string, options ObjectOptions) (string, error)
+	PresignUploadPartURL(ctx context.Context, bucketName string, objectKey string, uploadID string, partNumber int32) (string, error)
+	ListParts(ctx context.Context, bucketName string, objectKey string, uploadID string) ([]types.Part, error)
+	CompleteMultipartUpload(ctx context.Context, bucketName string, objectKey string, uploadID string, parts []types.CompletedPart) error
+	AbortMultipartUpload(ctx context.Context, bucketName string, objectKey string, uploadID string) error
+	ListMultipartUploads(ctx context.Context, bucketName string) ([]types.MultipartUpload, error)
+	CopyObject(ctx context.Context, sourceBucket string, sourceKey string, destBucket string, destKey string, options ObjectOptions) error
+	// CopyLargeObject copies objects over MaxCopyObjectSize with UploadPartCopy
+	CopyLargeObject(ctx context.Context, sourceBucket string, sourceKey string, destBucket string, destKey string, size int64, options ObjectOptions) error
+	ListObjects(ctx context.Context, bucketName string, prefix string, delimiter string, continuationToken string, maxKeys int32) (*ListObjectsResult, error)
+	DeleteBucket(ctx context.Context, bucketName string) error
+	// Tagging, an empty versionID means the latest version
+	GetObjectTagging(ctx context.Context, bucketName string, objectKey string, versionID string) (map[string]string, error)
+	PutObjectTagging(ctx context.Context, bucketName string, objectKey string, versionID string, tags map[string]string) error
+	DeleteObjectTagging(ctx context.Context, bucketName string, objectKey string, versionID string) error
+	// Versioning
+	ListObjectVersions(ctx context.Context, bucketName string, prefix string, keyMarker string, versionIDMarker string, maxKeys int32) (*ListObjectVersionsResult, error)
+	DeleteObjectVersion(ctx context.Context, bucketName string, objectKey string, versionID string) error
+	RestoreObjectVersion(ctx context.Context, bucketName string, objectKey string, versionID string) (string, error)
+	GetBucketVersioning(ctx context.Context, bucketName string) (string, error)
+	PutBucketVersioning(ctx context.Context, bucketName string, enabled bool) error
+	// Bucket configuration, putting no rules removes the configuration
+	GetBucketLifecycle(ctx context.Context, bucketName string) ([]LifecycleRule, error)
+	PutBucketLifecycle(ctx context.Context, bucketName string, rules []LifecycleRule) error
+	GetBucketCORS(ctx context.Context, bucketName string) ([]CORSRule, error)
+	PutBucketCORS(ctx context.Context, bucketName string, rules []CORSRule) error
+	GetPublicAccessBlock(ctx context.Context, bucketName string) (*PublicAccessBlock, error)
+	PutPublicAccessBlock(ctx context.Context, bucketName string, block PublicAccessBlock) error
 }
 
 var (
 	instance S3API
 )
 
+var (
+	ErrObjectNotFound  = errors.New("object not found")
+	ErrNotModified     = errors.New("object not modified")
+	ErrInvalidRange    = errors.New("requested range not satisfiable")
+	ErrUploadNotFound  = errors.New("multipart upload not found")
+	ErrBucketNotFound  = errors.New("bucket not found")
+	ErrVersionNotFound = errors.New("object version not found")
+	// ErrChecksumMismatch means the bytes don't match their x-amz-checksum-sha256, on upload
+	// S3 refused them, on download they were corrupted on the way
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+)
+
+// NotModifiedError is ErrNotModified with the current ETag of the object, which the 304
+// response has to carry
+type NotModifiedError struct {
+	ETag string
+}
+
+func (e *NotModifiedError) Error() string {
+	return ErrNotModified.Error()
+}
+
+func (e *NotModifiedError) Is(target error) bool {
+	return target == ErrNotModified
+}
+
+// ObjectSummary is one object of a ListObjects page
+type ObjectSummary struct {
+	Key          string
+	Size         int64
+	ETag         string
+	StorageClass string
+	LastModified time.Time
+}
+
+// ListObjectsResult is a single ListObjects page. Pass NextContinuationToken back to
+// ListObjects to fetch the following page while IsTruncated is true.
+type ListObjectsResult struct {
+	Objects               []ObjectSummary
+	CommonPrefixes        []string // "folders" under the prefix when a delimiter is used
+	NextContinuationToken string
+	IsTruncated           bool
+}
+
+// ObjectVersion is one version, or delete marker, of a ListObjectVersions page
+type ObjectVersion struct {
+	Key            string
+	VersionID      string
+	IsLatest       bool
+	IsDeleteMarker bool
+	Size           int64
+	ETag           string
+	StorageClass   string
+	LastModified   time.Time
+}
+
+// ListObjectVersionsResult is a single ListObjectVersions page, newest version first for
+// each key. Pass NextKeyMarker and NextVersionIDMarker back while IsTruncated is true.
+type ListObjectVersionsResult struct {
+	Versions            []ObjectVersion
+	NextKeyMarker       string
+	NextVersionIDMarker string
+	IsTruncated         bool
+}
+
+// ObjectOptions holds the per-call settings applied to an object when it is written.
+// Empty fields are left to the bucket defaults.
+type ObjectOptions struct {
+	ContentType  string
+	Metadata     map[string]string // stored as x-amz-meta-* headers
+	StorageClass string            // e.g. STANDARD_IA, INTELLIGENT_TIERING, GLACIER_IR
+	ACL          string            // canned ACL, e.g. private, public-read
+	SSEKMSKeyID  string            // when set the object is encrypted with SSE-KMS using this key
+	// ChecksumSHA256 is the base64 SHA-256 of the body, sent as x-amz-checksum-sha256 so S3
+	// rejects a body that doesn't match. Single part uploads only, multipart uploads ignore it.
+	ChecksumSHA256 string
+}
+
+func (options ObjectOptions) serverSideEncryption() types.ServerSideEncryption {
+	if options.SSEKMSKeyID == "" {
+		return ""
+	}
+	return types.ServerSideEncryptionAwsKms
+}
+
+// GetObjectOptions holds the optional HTTP conditions forwarded to GetObject
+type GetObjectOptions struct {
+	Range       string // e.g. "bytes=0-1023", passed through as the Range header
+	IfNoneMatch string // ETags the caller already has, or *
+	VersionID   string // a specific version instead of the latest one
+}
+
+// ObjectStream is an object body together with the headers needed to serve it.
+// The caller owns Body and must close it.
+type ObjectStream struct {
+	Body          io.ReadCloser
+	ContentType   string
+	ContentLength int64
+	ContentRange  string // set only for ranged (206) responses
+	ETag          string
+	VersionID     string // empty when the bucket has never been versioned
+	LastModified  time.Time
+	// ChecksumSHA256 is what S3 stored for the object, empty for ranged responses and objects
+	// uploaded without one. Reading Body to the end fails with ErrChecksumMismatch when the
+	// bytes don't match it.
+	ChecksumSHA256 string
+}
+
 func SetInstance(m S3API) {
 	instance = m
 }
@@ -48,30 +191,50 @@ func GetInstance() S3API {
 }
 
 type BaseS3API struct {
-	client  *s3SDK.Client
-	bucket  string
-	context context.Context
+	client *s3SDK.Client
 }
 
 type Config struct {
-	AWSS3Bucket         string
 	AWSS3Region         string
 	IsEnabledAccelerate bool
+	Endpoint            endpoint.Config // MinIO, LocalStack or another S3 compatible service
+	UsePathStyle        bool            // bucket in the path instead of the host, most S3 compatible services need it
 }
 
 var PresignURLExpiry = 2 * time.Hour
 
+// PostPolicy holds the conditions signed into a presigned POST policy document.
+// S3 rejects any form upload that doesn't satisfy them.
+type PostPolicy struct {
+	Expiry            time.Duration     // defaults to PresignURLExpiry
+	KeyPrefix         string            // when set, the client may post any key under this prefix
+	ContentType       string            // exact Content-Type
+	ContentTypePrefix string            // e.g. "image/", ignored when ContentType is set
+	MinContentLength  int64             // bytes
+	MaxContentLength  int64             // bytes, 0 means no content-length-range condition
+	Metadata          map[string]string // x-amz-meta-* fields that must be posted unchanged
+}
+
+// PresignedPost is the form target and the fields the client has to post along with the file
+type PresignedPost struct {
+	URL       string
+	Fields    map[string]string
+	ExpiresAt time.Time
+}
+
 func NewBaseS3API(setupConfig Config) (BaseS3API, error) {
 	manager := BaseS3API{}
 	background := context.Background()
 	cfg, err := config.LoadDefaultConfig(
 		background,
-		config.WithWebIdentityRoleCredentialOptions(func(o *stscreds.WebIdentityRoleOptions) {
-			o.Duration = time.Hour * 12 //max session duration, config by OPS
-		}),
-		config.WithCredentialsCacheOptions(func(o *aws.CredentialsCacheOptions) {
-			o.ExpiryWindow = PresignURLExpiry + time.Minute*1 //this value should greater than PresignURLExpiry, so credential won't expire earlier than url
-		}),
+		append([]func(*config.LoadOptions) error{
+			config.WithWebIdentityRoleCredentialOptions(func(o *stscreds.WebIdentityRoleOptions) {
+				o.Duration = time.Hour * 12 //max session duration, config by OPS
+			}),
+			config.WithCredentialsCacheOptions(func(o *aws.CredentialsCacheOptions) {
+				o.ExpiryWindow = PresignURLExpiry + time.Minute*1 //this value should greater than PresignURLExpiry, so credential won't expire earlier than url
+			}),
+		}, setupConfig.Endpoint.LoadOptions()...)...,
 	)
 
 	if err != nil {
@@ -89,25 +252,33 @@ func NewBaseS3API(setupConfig Config) (BaseS3API, error) {
 	client := s3SDK.NewFromConfig(cfg, func(o *s3SDK.Options) {
 		o.Region = setupConfig.AWSS3Region
 		o.UseAccelerate = setupConfig.IsEnabledAccelerate
+		o.UsePathStyle = setupConfig.UsePathStyle
+		o.BaseEndpoint = setupConfig.Endpoint.BaseEndpoint()
+		o.EndpointOptions.DisableHTTPS = setupConfig.Endpoint.DisableTLS
 	})
 
 	manager = BaseS3API{
-		client:  client,
-		bucket:  setupConfig.AWSS3Bucket,
-		context: background,
+		client: client,
 	}
 	return manager, nil
 }
 
-func (manager BaseS3API) PresignPutURL(key string, contentType string) (string, error) {
+func (manager BaseS3API) PresignPutURL(ctx context.Context, bucketName string, key string, options ObjectOptions) (string, error) {
 	psClient := s3SDK.NewPresignClient(manager.client)
 	input := &s3SDK.PutObjectInput{
-		Bucket:      &manager.bucket,
-		Key:         &key,
-		ContentType: &contentType,
+		Bucket:               aws.String(bucketName),
+		Key:                  aws.String(key),
+		ContentType:          optionalString(options.ContentType),
+		Metadata:             options.Metadata,
+		StorageClass:         types.StorageClass(options.StorageClass),
+		ACL:                  types.ObjectCannedACL(options.ACL),
+		ServerSideEncryption: options.serverSideEncryption(),
+		SSEKMSKeyId:          optionalString(options.SSEKMSKeyID),
+		// Signed into the URL, the client has to send the same x-amz-checksum-sha256 header
+		ChecksumSHA256: optionalString(options.ChecksumSHA256),
 	}
 	resp, err := psClient.PresignPutObject(
-		manager.context,
+		ctx,
 		input,
 		s3SDK.WithPresignExpires(PresignURLExpiry),
 	)
@@ -118,14 +289,14 @@ func (manager BaseS3API) PresignPutURL(key string, contentType string) (string,
 	return resp.URL, err
 }
 
-func (manager BaseS3API) PresignGetURL(key string) (string, error) {
+func (manager BaseS3API) PresignGetURL(ctx context.Context, bucketName string, key string) (string, error) {
 	psClient := s3SDK.NewPresignClient(manager.client)
 	input := &s3SDK.GetObjectInput{
-		Bucket: &manager.bucket,
-		Key:    &key,
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(key),
 	}
 	resp, err := psClient.PresignGetObject(
-		manager.context,
+		ctx,
 		input,
 		s3SDK.WithPresignExpires(PresignURLExpiry),
 	)
@@ -136,36 +307,82 @@ func (manager BaseS3API) PresignGetURL(key string) (string, error) {
 	return resp.URL, err
 }
 
-func (manager BaseS3API) GetHeadObject(key string) (*s3SDK.HeadObjectOutput, error) {
-	input := &s3SDK.HeadObjectInput{
-		Bucket: &manager.bucket,
-		Key:    &key,
+// conditions returns the key the form posts, the fields the client has to post unchanged
+// and the conditions to sign for the policy
+func (policy PostPolicy) conditions(objectKey string) (string, map[string]string, []interface{}) {
+	fields := map[string]string{}
+	var conditions []interface{}
+	if policy.MaxContentLength > 0 {
+		conditions = append(conditions, []interface{}{"content-length-range", policy.MinContentLength, policy.MaxContentLength})
 	}
-	return manager.client.HeadObject(manager.context, input)
+	if policy.ContentType != "" {
+		conditions = append(conditions, map[string]string{"Content-Type": policy.ContentType})
+		fields["Content-Type"] = policy.ContentType
+	} else if policy.ContentTypePrefix != "" {
+		conditions = append(conditions, []interface{}{"starts-with", "$Content-Type", policy.ContentTypePrefix})
+	}
+	if policy.KeyPrefix != "" {
+		conditions = append(conditions, []interface{}{"starts-with", "$key", policy.KeyPrefix})
+		objectKey = policy.KeyPrefix + "${filename}"
+	}
+	for name, value := range policy.Metadata {
+		field := "x-amz-meta-" + strings.ToLower(name)
+		conditions = append(conditions, map[string]string{field: value})
+		fields[field] = value
+	}
+	return objectKey, fields, conditions
 }
 
-func (manager BaseS3API) DeleteObjects(keys []string) (*s3SDK.DeleteObjectsOutput, error) {
-	var objectKeys []types.ObjectIdentifier
-	for _, v := range keys {
-		objectKeys = append(objectKeys, types.ObjectIdentifier{Key: aws.String(v)})
+func (manager BaseS3API) PresignPostObject(ctx context.Context, bucketName string, objectKey string, policy PostPolicy) (*PresignedPost, error) {
+	expiry := policy.Expiry
+	if expiry <= 0 {
+		expiry = PresignURLExpiry
 	}
 
-	input := &s3SDK.DeleteObjectsInput{
-		Bucket: &manager.bucket,
-		Delete: &types.Delete{
-			Objects: objectKeys,
+	objectKey, fields, conditions := policy.conditions(objectKey)
+
+	psClient := s3SDK.NewPresignClient(manager.client)
+	resp, err := psClient.PresignPostObject(
+		ctx,
+		&s3SDK.PutObjectInput{
+			Bucket: aws.String(bucketName),
+			Key:    aws.String(objectKey),
+		},
+		func(o *s3SDK.PresignPostOptions) {
+			o.Expires = expiry
+			o.Conditions = conditions
 		},
+	)
+	if err != nil {
+		logger.Error.Printf("PresignPostObject fail, %+v\n", err)
+		return nil, err
 	}
 
-	return manager.client.DeleteObjects(manager.context, input)
+	for name, value := range resp.Values {
+		fields[name] = value
+	}
+	return &PresignedPost{
+		URL:       resp.URL,
+		Fields:    fields,
+		ExpiresAt: time.Now().Add(expiry),
+	}, nil
+}
+
+func (manager BaseS3API) GetHeadObject(ctx context.Context, bucketName string, key string) (*s3SDK.HeadObjectOutput, error) {
+	input := &s3SDK.HeadObjectInput{
+		Bucket:       aws.String(bucketName),
+		Key:          aws.String(key),
+		ChecksumMode: types.ChecksumModeEnabled,
+	}
+	return manager.client.HeadObject(ctx, input)
 }
 
-func (manager BaseS3API) CheckObjectExists(key string) (bool, error) {
+func (manager BaseS3API) CheckObjectExists(ctx context.Context, bucketName string, key string) (bool, error) {
 	input := &s3SDK.HeadObjectInput{
-		Bucket: &manager.bucket,
+		Bucket: aws.String(bucketName),
 		Key:    aws.String(key),
 	}
-	_, err := manager.client.HeadObject(manager.context, input)
+	_, err := manager.client.HeadObject(ctx, input)
 	if err != nil {
 		var notFoundErr *types.NotFound
 		if ok := errors.As(err, &notFoundErr); ok {
@@ -177,9 +394,9 @@ func (manager BaseS3API) CheckObjectExists(key string) (bool, error) {
 }
 
 // New simplified implementations
-func (manager BaseS3API) ListBuckets() ([]types.Bucket, error) {
+func (manager BaseS3API) ListBuckets(ctx context.Context) ([]types.Bucket, error) {
 	/*
-		output, err := manager.client.ListBuckets(manager.context, &s3SDK.ListBucketsInput{})
+		output, err := manager.client.ListBuckets(ctx, &s3SDK.ListBucketsInput{})
 		if err != nil {
 			logger.Error.Printf("ListBuckets fail, %+v\n", err)
 			return nil, err
@@ -192,7 +409,7 @@ func (manager BaseS3API) ListBuckets() ([]types.Bucket, error) {
 	var buckets []types.Bucket
 	bucketPaginator := s3SDK.NewListBucketsPaginator(manager.client, &s3SDK.ListBucketsInput{})
 	for bucketPaginator.HasMorePages() {
-		output, err = bucketPaginator.NextPage(manager.context)
+		output, err = bucketPaginator.NextPage(ctx)
 		if err != nil {
 			var apiErr smithy.APIError
 			if errors.As(err, &apiErr) && apiErr.ErrorCode() == "AccessDenied" {
@@ -209,8 +426,8 @@ func (manager BaseS3API) ListBuckets() ([]types.Bucket, error) {
 	return buckets, err
 }
 
-func (manager BaseS3API) BucketExists(bucketName string) (bool, error) {
-	_, err := manager.client.HeadBucket(manager.context, &s3SDK.HeadBucketInput{
+func (manager BaseS3API) BucketExists(ctx context.Context, bucketName string) (bool, error) {
+	_, err := manager.client.HeadBucket(ctx, &s3SDK.HeadBucketInput{
 		Bucket: aws.String(bucketName),
 	})
 	if err != nil {
@@ -223,8 +440,8 @@ func (manager BaseS3API) BucketExists(bucketName string) (bool, error) {
 	return true, nil
 }
 
-func (manager BaseS3API) CreateBucket(name string, region string) error {
-	_, err := manager.client.CreateBucket(manager.context, &s3SDK.CreateBucketInput{
+func (manager BaseS3API) CreateBucket(ctx context.Context, name string, region string) error {
+	_, err := manager.client.CreateBucket(ctx, &s3SDK.CreateBucketInput{
 		Bucket: aws.String(name),
 		CreateBucketConfiguration: &types.CreateBucketConfiguration{
 			LocationConstraint: types.BucketLocationConstraint(region),
@@ -233,20 +450,36 @@ func (manager BaseS3API) CreateBucket(name string, region string) error {
 	return err
 }
 
-func (manager BaseS3API) UploadFile(bucketName string, objectKey string, fileContent []byte) error {
-	_, err := manager.client.PutObject(manager.context, &s3SDK.PutObjectInput{
-		Bucket:        aws.String(bucketName),
-		Key:           aws.String(objectKey),
-		Body:          bytes.NewReader(fileContent),
-		ContentLength: aws.Int64(int64(len(fileContent))),
+// UploadFile stores a SHA-256 checksum with the object, the SDK computes it unless
+// options.ChecksumSHA256 is set. S3 rejects a body that doesn't match with ErrChecksumMismatch.
+func (manager BaseS3API) UploadFile(ctx context.Context, bucketName string, objectKey string, fileContent []byte, options ObjectOptions) error {
+	_, err := manager.client.PutObject(ctx, &s3SDK.PutObjectInput{
+		Bucket:               aws.String(bucketName),
+		Key:                  aws.String(objectKey),
+		Body:                 bytes.NewReader(fileContent),
+		ContentLength:        aws.Int64(int64(len(fileContent))),
+		ContentType:          optionalString(options.ContentType),
+		Metadata:             options.Metadata,
+		StorageClass:         types.StorageClass(options.StorageClass),
+		ACL:                  types.ObjectCannedACL(options.ACL),
+		ServerSideEncryption: options.serverSideEncryption(),
+		SSEKMSKeyId:          optionalString(options.SSEKMSKeyID),
+		ChecksumAlgorithm:    types.ChecksumAlgorithmSha256,
+		ChecksumSHA256:       optionalString(options.ChecksumSHA256),
 	})
+	if isErrorCode(err, "BadDigest") {
+		return fmt.Errorf("%w: %v", ErrChecksumMismatch, err)
+	}
 	return err
 }
 
-func (manager BaseS3API) DownloadFile(bucketName string, objectKey string) ([]byte, error) {
-	result, err := manager.client.GetObject(manager.context, &s3SDK.GetObjectInput{
-		Bucket: aws.String(bucketName),
-		Key:    aws.String(objectKey),
+// DownloadFile fails with ErrChecksumMismatch when the body doesn't match the checksum
+// stored with the object
+func (manager BaseS3API) DownloadFile(ctx context.Context, bucketName string, objectKey string) ([]byte, error) {
+	result, err := manager.client.GetObject(ctx, &s3SDK.GetObjectInput{
+		Bucket:       aws.String(bucketName),
+		Key:          aws.String(objectKey),
+		ChecksumMode: types.ChecksumModeEnabled,
 	})
 	if err != nil {
 		return nil, err
@@ -255,48 +488,456 @@ func (manager BaseS3API) DownloadFile(bucketName string, objectKey string) ([]by
 
 	body, err := io.ReadAll(result.Body)
 	if err != nil {
+		return nil, convertChecksumError(err)
+	}
+	if err := verifyChecksum(body, aws.ToString(result.ChecksumSHA256)); err != nil {
 		return nil, err
 	}
 	return body, nil
 }
 
-func (manager BaseS3API) CopyObject(sourceBucket string, sourceKey string, destBucket string, destKey string) error {
-	_, err := manager.client.CopyObject(manager.context, &s3SDK.CopyObjectInput{
-		Bucket:     aws.String(destBucket),
-		CopySource: aws.String(sourceBucket + "/" + sourceKey),
-		Key:        aws.String(destKey),
-	})
+func (manager BaseS3API) GetObjectStream(ctx context.Context, bucketName string, objectKey string, options GetObjectOptions) (*ObjectStream, error) {
+	input := &s3SDK.GetObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(objectKey),
+	}
+	if options.Range != "" {
+		input.Range = aws.String(options.Range)
+	}
+	if options.IfNoneMatch != "" {
+		input.IfNoneMatch = aws.String(options.IfNoneMatch)
+	}
+	if options.VersionID != "" {
+		input.VersionId = aws.String(options.VersionID)
+	}
+	// S3 only has a checksum of the whole object, a range can't be verified
+	if options.Range == "" {
+		input.ChecksumMode = types.ChecksumModeEnabled
+	}
+
+	result, err := manager.client.GetObject(ctx, input)
+	if err != nil {
+		return nil, convertObjectError(err)
+	}
+
+	stream := &ObjectStream{
+		Body:          result.Body,
+		ContentType:   aws.ToString(result.ContentType),
+		ContentLength: aws.ToInt64(result.ContentLength),
+		ContentRange:  aws.ToString(result.ContentRange),
+		ETag:          aws.ToString(result.ETag),
+		VersionID:     aws.ToString(result.VersionId),
+		LastModified:  aws.ToTime(result.LastModified),
+	}
+	if options.Range == "" {
+		stream.ChecksumSHA256 = aws.ToString(result.ChecksumSHA256)
+		stream.Body = verifiedBody(result.Body, stream.ChecksumSHA256)
+	}
+	if stream.ContentType == "" {
+		stream.ContentType = "application/octet-stream"
+	}
+	return stream, nil
+}
+
+// convertObjectError maps the HTTP status S3 answered with onto the package errors,
+// so callers don't need to know about the SDK error types
+func convertObjectError(err error) error {
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return ErrObjectNotFound
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchVersion" {
+		return ErrVersionNotFound
+	}
+
+	var responseErr *awshttp.ResponseError
+	if errors.As(err, &responseErr) {
+		switch responseErr.HTTPStatusCode() {
+		case http.StatusNotModified:
+			return &NotModifiedError{ETag: responseErr.Response.Header.Get("ETag")}
+		case http.StatusNotFound:
+			return ErrObjectNotFound
+		case http.StatusRequestedRangeNotSatisfiable:
+			return ErrInvalidRange
+		}
+	}
+
+	logger.Error.Printf("S3 object request fail, %+v\n", err)
 	return err
 }
 
-func (manager BaseS3API) ListObjects(bucketName string) (interface{}, error) {
-	output, err := manager.client.ListObjectsV2(manager.context, &s3SDK.ListObjectsV2Input{
+// copySource builds the URL-encoded CopySource of a copy request
+func copySource(bucketName string, objectKey string, versionID string) string {
+	segments := strings.Split(objectKey, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	source := bucketName + "/" + strings.Join(segments, "/")
+	if versionID != "" {
+		source += "?versionId=" + url.QueryEscape(versionID)
+	}
+	return source
+}
+
+// CopyObject copies an object. The source content type and metadata are kept unless
+// options sets either of them, in which case both are replaced.
+func (manager BaseS3API) CopyObject(ctx context.Context, sourceBucket string, sourceKey string, destBucket string, destKey string, options ObjectOptions) error {
+	input := &s3SDK.CopyObjectInput{
+		Bucket:               aws.String(destBucket),
+		CopySource:           aws.String(copySource(sourceBucket, sourceKey, "")),
+		Key:                  aws.String(destKey),
+		StorageClass:         types.StorageClass(options.StorageClass),
+		ACL:                  types.ObjectCannedACL(options.ACL),
+		ServerSideEncryption: options.serverSideEncryption(),
+		SSEKMSKeyId:          optionalString(options.SSEKMSKeyID),
+	}
+	if options.ContentType != "" || options.Metadata != nil {
+		input.MetadataDirective = types.MetadataDirectiveReplace
+		input.ContentType = optionalString(options.ContentType)
+		input.Metadata = options.Metadata
+	}
+
+	_, err := manager.client.CopyObject(ctx, input)
+	return err
+}
+
+func (manager BaseS3API) ListObjects(ctx context.Context, bucketName string, prefix string, delimiter string, continuationToken string, maxKeys int32) (*ListObjectsResult, error) {
+	input := &s3SDK.ListObjectsV2Input{
 		Bucket: aws.String(bucketName),
-	})
+	}
+	if prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+	if delimiter != "" {
+		input.Delimiter = aws.String(delimiter)
+	}
+	if continuationToken != "" {
+		input.ContinuationToken = aws.String(continuationToken)
+	}
+	if maxKeys > 0 {
+		input.MaxKeys = aws.Int32(maxKeys)
+	}
+
+	output, err := manager.client.ListObjectsV2(ctx, input)
 	if err != nil {
+		var noSuchBucket *types.NoSuchBucket
+		if errors.As(err, &noSuchBucket) {
+			return nil, ErrBucketNotFound
+		}
 		logger.Error.Printf("ListObjects fail, %+v\n", err)
 		return nil, err
 	}
-	logger.Info.Printf("ListObjects success, %+v\n", output.Contents)
-	return output.Contents, nil
-}
 
-func (manager BaseS3API) DeleteObjectsFromBucket(bucketName string, objectKeys []string) error {
-	var objectIds []types.ObjectIdentifier
-	for _, key := range objectKeys {
-		objectIds = append(objectIds, types.ObjectIdentifier{Key: aws.String(key)})
+	result := &ListObjectsResult{
+		Objects:               make([]ObjectSummary, 0, len(output.Contents)),
+		CommonPrefixes:        make([]string, 0, len(output.CommonPrefixes)),
+		NextContinuationToken: aws.ToString(output.NextContinuationToken),
+		IsTruncated:           aws.ToBool(output.IsTruncated),
+	}
+	for _, object := range output.Contents {
+		result.Objects = append(result.Objects, ObjectSummary{
+			Key:          aws.ToString(object.Key),
+			Size:         aws.ToInt64(object.Size),
+			ETag:         aws.ToString(object.ETag),
+			StorageClass: string(object.StorageClass),
+			LastModified: aws.ToTime(object.LastModified),
+		})
 	}
+	for _, commonPrefix := range output.CommonPrefixes {
+		result.CommonPrefixes = append(result.CommonPrefixes, aws.ToString(commonPrefix.Prefix))
+	}
+	return result, nil
+}
 
-	_, err := manager.client.DeleteObjects(manager.context, &s3SDK.DeleteObjectsInput{
+func (manager BaseS3API) DeleteBucket(ctx context.Context, bucketName string) error {
+	_, err := manager.client.DeleteBucket(ctx, &s3SDK.DeleteBucketInput{
 		Bucket: aws.String(bucketName),
-		Delete: &types.Delete{Objects: objectIds},
 	})
 	return err
 }
 
-func (manager BaseS3API) DeleteBucket(bucketName string) error {
-	_, err := manager.client.DeleteBucket(manager.context, &s3SDK.DeleteBucketInput{
-		Bucket: aws.String(bucketName),
+func (manager BaseS3API) CreateMultipartUpload(ctx context.Context, bucketName string, objectKey string, options ObjectOptions) (string, error) {
+	input := &s3SDK.CreateMultipartUploadInput{
+		Bucket:               aws.String(bucketName),
+		Key:                  aws.String(objectKey),
+		ContentType:          optionalString(options.ContentType),
+		Metadata:             options.Metadata,
+		StorageClass:         types.StorageClass(options.StorageClass),
+		ACL:                  types.ObjectCannedACL(options.ACL),
+		ServerSideEncryption: options.serverSideEncryption(),
+		SSEKMSKeyId:          optionalString(options.SSEKMSKeyID),
+	}
+
+	output, err := manager.client.CreateMultipartUpload(ctx, input)
+	if err != nil {
+		logger.Error.Printf("CreateMultipartUpload fail, %+v\n", err)
+		return "", err
+	}
+	return aws.ToString(output.UploadId), nil
+}
+
+func (manager BaseS3API) PresignUploadPartURL(ctx context.Context, bucketName string, objectKey string, uploadID string, partNumber int32) (string, error) {
+	psClient := s3SDK.NewPresignClient(manager.client)
+	resp, err := psClient.PresignUploadPart(
+		ctx,
+		&s3SDK.UploadPartInput{
+			Bucket:     aws.String(bucketName),
+			Key:        aws.String(objectKey),
+			UploadId:   aws.String(uploadID),
+			PartNumber: aws.Int32(partNumber),
+		},
+		s3SDK.WithPresignExpires(PresignURLExpiry),
+	)
+	if err != nil {
+		logger.Error.Printf("PresignUploadPart fail, %+v\n", err)
+		return "", err
+	}
+	return resp.URL, nil
+}
+
+func (manager BaseS3API) ListParts(ctx context.Context, bucketName string, objectKey string, uploadID string) ([]types.Part, error) {
+	var parts []types.Part
+	paginator := s3SDK.NewListPartsPaginator(manager.client, &s3SDK.ListPartsInput{
+		Bucket:   aws.String(bucketName),
+		Key:      aws.String(objectKey),
+		UploadId: aws.String(uploadID),
 	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, convertMultipartError(err)
+		}
+		parts = append(parts, output.Parts...)
+	}
+	return parts, nil
+}
+
+func (manager BaseS3API) CompleteMultipartUpload(ctx context.Context, bucketName string, objectKey string, uploadID string, parts []types.CompletedPart) error {
+	_, err := manager.client.CompleteMultipartUpload(ctx, &s3SDK.CompleteMultipartUploadInput{
+		Bucket:          aws.String(bucketName),
+		Key:             aws.String(objectKey),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		return convertMultipartError(err)
+	}
+	return nil
+}
+
+func (manager BaseS3API) AbortMultipartUpload(ctx context.Context, bucketName string, objectKey string, uploadID string) error {
+	_, err := manager.client.AbortMultipartUpload(ctx, &s3SDK.AbortMultipartUploadInput{
+		Bucket:   aws.String(bucketName),
+		Key:      aws.String(objectKey),
+		UploadId: aws.String(uploadID),
+	})
+	if err != nil {
+		return convertMultipartError(err)
+	}
+	return nil
+}
+
+func (manager BaseS3API) ListMultipartUploads(ctx context.Context, bucketName string) ([]types.MultipartUpload, error) {
+	var uploads []types.MultipartUpload
+	input := &s3SDK.ListMultipartUploadsInput{
+		Bucket: aws.String(bucketName),
+	}
+	for {
+		output, err := manager.client.ListMultipartUploads(ctx, input)
+		if err != nil {
+			logger.Error.Printf("ListMultipartUploads fail, %+v\n", err)
+			return nil, err
+		}
+		uploads = append(uploads, output.Uploads...)
+		if !aws.ToBool(output.IsTruncated) {
+			break
+		}
+		input.KeyMarker = output.NextKeyMarker
+		input.UploadIdMarker = output.NextUploadIdMarker
+	}
+	return uploads, nil
+}
+
+func convertMultipartError(err error) error {
+	var noSuchUpload *types.NoSuchUpload
+	if errors.As(err, &noSuchUpload) {
+		return ErrUploadNotFound
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchUpload" {
+		return ErrUploadNotFound
+	}
+
+	logger.Error.Printf("multipart upload fail, %+v\n", err)
 	return err
 }
+
+// optionalString leaves empty values unset, so S3 applies its own default
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return aws.String(value)
+}
+
+func (manager BaseS3API) GetObjectTagging(ctx context.Context, bucketName string, objectKey string, versionID string) (map[string]string, error) {
+	output, err := manager.client.GetObjectTagging(ctx, &s3SDK.GetObjectTaggingInput{
+		Bucket:    aws.String(bucketName),
+		Key:       aws.String(objectKey),
+		VersionId: optionalString(versionID),
+	})
+	if err != nil {
+		return nil, convertObjectError(err)
+	}
+
+	tags := make(map[string]string, len(output.TagSet))
+	for _, tag := range output.TagSet {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return tags, nil
+}
+
+// PutObjectTagging replaces the whole tag set of an object
+func (manager BaseS3API) PutObjectTagging(ctx context.Context, bucketName string, objectKey string, versionID string, tags map[string]string) error {
+	tagSet := make([]types.Tag, 0, len(tags))
+	for key, value := range tags {
+		tagSet = append(tagSet, types.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+
+	_, err := manager.client.PutObjectTagging(ctx, &s3SDK.PutObjectTaggingInput{
+		Bucket:    aws.String(bucketName),
+		Key:       aws.String(objectKey),
+		VersionId: optionalString(versionID),
+		Tagging:   &types.Tagging{TagSet: tagSet},
+	})
+	if err != nil {
+		return convertObjectError(err)
+	}
+	return nil
+}
+
+func (manager BaseS3API) DeleteObjectTagging(ctx context.Context, bucketName string, objectKey string, versionID string) error {
+	_, err := manager.client.DeleteObjectTagging(ctx, &s3SDK.DeleteObjectTaggingInput{
+		Bucket:    aws.String(bucketName),
+		Key:       aws.String(objectKey),
+		VersionId: optionalString(versionID),
+	})
+	if err != nil {
+		return convertObjectError(err)
+	}
+	return nil
+}
+
+func (manager BaseS3API) ListObjectVersions(ctx context.Context, bucketName string, prefix string, keyMarker string, versionIDMarker string, maxKeys int32) (*ListObjectVersionsResult, error) {
+	input := &s3SDK.ListObjectVersionsInput{
+		Bucket:          aws.String(bucketName),
+		Prefix:          optionalString(prefix),
+		KeyMarker:       optionalString(keyMarker),
+		VersionIdMarker: optionalString(versionIDMarker),
+	}
+	if maxKeys > 0 {
+		input.MaxKeys = aws.Int32(maxKeys)
+	}
+
+	output, err := manager.client.ListObjectVersions(ctx, input)
+	if err != nil {
+		var noSuchBucket *types.NoSuchBucket
+		if errors.As(err, &noSuchBucket) {
+			return nil, ErrBucketNotFound
+		}
+		logger.Error.Printf("ListObjectVersions fail, %+v\n", err)
+		return nil, err
+	}
+
+	result := &ListObjectVersionsResult{
+		Versions:            make([]ObjectVersion, 0, len(output.Versions)+len(output.DeleteMarkers)),
+		NextKeyMarker:       aws.ToString(output.NextKeyMarker),
+		NextVersionIDMarker: aws.ToString(output.NextVersionIdMarker),
+		IsTruncated:         aws.ToBool(output.IsTruncated),
+	}
+	for _, version := range output.Versions {
+		result.Versions = append(result.Versions, ObjectVersion{
+			Key:          aws.ToString(version.Key),
+			VersionID:    aws.ToString(version.VersionId),
+			IsLatest:     aws.ToBool(version.IsLatest),
+			Size:         aws.ToInt64(version.Size),
+			ETag:         aws.ToString(version.ETag),
+			StorageClass: string(version.StorageClass),
+			LastModified: aws.ToTime(version.LastModified),
+		})
+	}
+	for _, marker := range output.DeleteMarkers {
+		result.Versions = append(result.Versions, ObjectVersion{
+			Key:            aws.ToString(marker.Key),
+			VersionID:      aws.ToString(marker.VersionId),
+			IsLatest:       aws.ToBool(marker.IsLatest),
+			IsDeleteMarker: true,
+			LastModified:   aws.ToTime(marker.LastModified),
+		})
+	}
+	// S3 returns versions and delete markers in separate lists, merge them back in key order, newest first
+	sort.SliceStable(result.Versions, func(i, j int) bool {
+		if result.Versions[i].Key != result.Versions[j].Key {
+			return result.Versions[i].Key < result.Versions[j].Key
+		}
+		return result.Versions[i].LastModified.After(result.Versions[j].LastModified)
+	})
+	return result, nil
+}
+
+// DeleteObjectVersion permanently deletes one version, or removes a delete marker
+func (manager BaseS3API) DeleteObjectVersion(ctx context.Context, bucketName string, objectKey string, versionID string) error {
+	_, err := manager.client.DeleteObject(ctx, &s3SDK.DeleteObjectInput{
+		Bucket:    aws.String(bucketName),
+		Key:       aws.String(objectKey),
+		VersionId: aws.String(versionID),
+	})
+	if err != nil {
+		return convertObjectError(err)
+	}
+	return nil
+}
+
+// RestoreObjectVersion copies an older version over the latest one and returns the id of
+// the version the copy created
+func (manager BaseS3API) RestoreObjectVersion(ctx context.Context, bucketName string, objectKey string, versionID string) (string, error) {
+	output, err := manager.client.CopyObject(ctx, &s3SDK.CopyObjectInput{
+		Bucket:     aws.String(bucketName),
+		Key:        aws.String(objectKey),
+		CopySource: aws.String(copySource(bucketName, objectKey, versionID)),
+	})
+	if err != nil {
+		return "", convertObjectError(err)
+	}
+	return aws.ToString(output.VersionId), nil
+}
+
+// GetBucketVersioning returns "Enabled", "Suspended", or "" for a bucket that was never versioned
+func (manager BaseS3API) GetBucketVersioning(ctx context.Context, bucketName string) (string, error) {
+	output, err := manager.client.GetBucketVersioning(ctx, &s3SDK.GetBucketVersioningInput{
+		Bucket: aws.String(bucketName),
+	})
+	if err != nil {
+		logger.Error.Printf("GetBucketVersioning fail, %+v\n", err)
+		return "", err
+	}
+	return string(output.Status), nil
+}
+
+// PutBucketVersioning enables versioning, or suspends it when enabled is false
+func (manager BaseS3API) PutBucketVersioning(ctx context.Context, bucketName string, enabled bool) error {
+	status := types.BucketVersioningStatusSuspended
+	if enabled {
+		status = types.BucketVersioningStatusEnabled
+	}
+	_, err := manager.client.PutBucketVersioning(ctx, &s3SDK.PutBucketVersioningInput{
+		Bucket:                  aws.String(bucketName),
+		VersioningConfiguration: &types.VersioningConfiguration{Status: status},
+	})
+	if err != nil {
+		logger.Error.Printf("PutBucketVersioning fail, %+v\n", err)
+		return err
+	}
+	return nil
+}
@@ -1,24 +1,1155 @@
 package s3
 
 import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3SDK "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 )
 
-// Mock S3API 實現，用於測試
+var _ S3API = (*MockS3API)(nil)
+
+// MockS3API keeps buckets, objects and multipart uploads in memory. The zero value is
+// ready to use and is safe for concurrent use.
+//
+// Failures can be injected for every method with ShouldFail, for a single method with
+// FailOn, or for a single key with FailKey (reported per key by the batch deletes).
 type MockS3API struct {
 	ShouldFail bool
+
+	mu          sync.Mutex
+	buckets     map[string]*mockBucket
+	uploads     map[string]*mockUpload
+	failures    map[string]error
+	keyFailures map[string]error
+	uploadSeq   int
+	versionSeq  int
 }
 
-func (m *MockS3API) PresignGetURL(key string) (string, error) {
-	if m.ShouldFail {
-		return "", fmt.Errorf("mock S3 error")
+type mockBucket struct {
+	region     string
+	createdAt  time.Time
+	versioning string // "", "Enabled" or "Suspended"
+	lifecycle  []LifecycleRule
+	cors       []CORSRule
+	access     PublicAccessBlock
+	objects    map[string]*mockObject   // latest version of every key that isn't deleted
+	versions   map[string][]*mockObject // every version and delete marker, oldest first
+}
+
+type mockObject struct {
+	data         []byte
+	contentType  string
+	metadata     map[string]string
+	storageClass string
+	acl          string
+	sseKMSKeyID  string
+	tags         map[string]string
+	versionID    string // "" while the bucket has never been versioned, "null" while suspended
+	deleteMarker bool
+	etag         string
+	lastModified time.Time
+	checksum     string // base64 SHA-256, as stored with x-amz-checksum-sha256
+}
+
+type mockUpload struct {
+	bucketName string
+	objectKey  string
+	options    ObjectOptions
+	initiated  time.Time
+	parts      map[int32]*mockObject
+}
+
+// FailOn makes every call to method (an S3API method name, e.g. "UploadFile") return err.
+// Passing a nil err removes the failure.
+func (m *MockS3API) FailOn(method string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failures == nil {
+		m.failures = map[string]error{}
+	}
+	if err == nil {
+		delete(m.failures, method)
+		return
+	}
+	m.failures[method] = err
+}
+
+// FailKey makes every operation on objectKey fail with err. Passing a nil err removes the failure.
+func (m *MockS3API) FailKey(objectKey string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.keyFailures == nil {
+		m.keyFailures = map[string]error{}
+	}
+	if err == nil {
+		delete(m.keyFailures, objectKey)
+		return
+	}
+	m.keyFailures[objectKey] = err
+}
+
+// ClearFailures removes every injected failure, including ShouldFail
+func (m *MockS3API) ClearFailures() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ShouldFail = false
+	m.failures = nil
+	m.keyFailures = nil
+}
+
+// PutObject seeds an object, creating the bucket when needed
+func (m *MockS3API) PutObject(bucketName string, objectKey string, data []byte, contentType string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.put(m.bucket(bucketName, true), objectKey, newMockObject(data, contentType))
+}
+
+// CorruptObject replaces the content of an object but keeps its checksum and ETag, as if
+// the bytes had been damaged after the upload
+func (m *MockS3API) CorruptObject(bucketName string, objectKey string, data []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if object, err := m.object(bucketName, objectKey); err == nil {
+		object.data = append([]byte(nil), data...)
+	}
+}
+
+// Object returns the stored content of an object
+func (m *MockS3API) Object(bucketName string, objectKey string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bucket := m.bucket(bucketName, false)
+	if bucket == nil {
+		return nil, false
+	}
+	object, ok := bucket.objects[objectKey]
+	if !ok {
+		return nil, false
+	}
+	return append([]byte(nil), object.data...), true
+}
+
+// ObjectOptions returns the options an object was written with
+func (m *MockS3API) ObjectOptions(bucketName string, objectKey string) (ObjectOptions, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	object, err := m.object(bucketName, objectKey)
+	if err != nil {
+		return ObjectOptions{}, false
+	}
+	return ObjectOptions{
+		ContentType:    object.contentType,
+		Metadata:       copyMetadata(object.metadata),
+		StorageClass:   object.storageClass,
+		ACL:            object.acl,
+		SSEKMSKeyID:    object.sseKMSKeyID,
+		ChecksumSHA256: object.checksum,
+	}, true
+}
+
+// UploadPart stores a part the way a client PUT to a presigned part URL would, and returns its ETag
+func (m *MockS3API) UploadPart(uploadID string, partNumber int32, data []byte) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	upload, ok := m.uploads[uploadID]
+	if !ok {
+		return "", ErrUploadNotFound
+	}
+	part := newMockObject(data, "")
+	upload.parts[partNumber] = part
+	return part.etag, nil
+}
+
+// SetUploadInitiated overrides when a multipart upload was started
+func (m *MockS3API) SetUploadInitiated(uploadID string, initiated time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if upload, ok := m.uploads[uploadID]; ok {
+		upload.initiated = initiated
+	}
+}
+
+func (m *MockS3API) PresignGetURL(ctx context.Context, bucketName string, key string) (string, error) {
+	if err := m.fail(ctx, "PresignGetURL"); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("https://%s.s3.amazonaws.com/%s?presigned=true", bucketName, key), nil
+}
+
+func (m *MockS3API) PresignPutURL(ctx context.Context, bucketName string, key string, options ObjectOptions) (string, error) {
+	if err := m.fail(ctx, "PresignPutURL"); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("https://%s.s3.amazonaws.com/%s?presigned=true&content-type=%s", bucketName, key, options.ContentType), nil
+}
+
+func (m *MockS3API) PresignPostObject(ctx context.Context, bucketName string, objectKey string, policy PostPolicy) (*PresignedPost, error) {
+	if err := m.fail(ctx, "PresignPostObject"); err != nil {
+		return nil, err
+	}
+	expiry := policy.Expiry
+	if expiry <= 0 {
+		expiry = PresignURLExpiry
+	}
+	expiresAt := time.Now().Add(expiry)
+
+	// The policy field is the unsigned policy document, base64 encoded like the real one,
+	// so tests can check the signed conditions
+	objectKey, fields, conditions := policy.conditions(objectKey)
+	conditions = append(conditions, map[string]string{"bucket": bucketName}, map[string]string{"key": objectKey})
+	document, err := json.Marshal(map[string]interface{}{
+		"expiration": expiresAt.UTC().Format(time.RFC3339),
+		"conditions": conditions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	fields["key"] = objectKey
+	fields["policy"] = base64.StdEncoding.EncodeToString(document)
+
+	return &PresignedPost{
+		URL:       fmt.Sprintf("https://%s.s3.amazonaws.com/", bucketName),
+		Fields:    fields,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (m *MockS3API) GetHeadObject(ctx context.Context, bucketName string, key string) (*s3SDK.HeadObjectOutput, error) {
+	if err := m.fail(ctx, "GetHeadObject", key); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	object, err := m.object(bucketName, key)
+	if err != nil {
+		return nil, &types.NotFound{}
+	}
+	output := &s3SDK.HeadObjectOutput{
+		ContentLength:  aws.Int64(int64(len(object.data))),
+		ContentType:    aws.String(object.contentType),
+		ETag:           aws.String(object.etag),
+		LastModified:   aws.Time(object.lastModified),
+		Metadata:       copyMetadata(object.metadata),
+		StorageClass:   types.StorageClass(object.storageClass),
+		ChecksumSHA256: aws.String(object.checksum),
+	}
+	if object.sseKMSKeyID != "" {
+		output.ServerSideEncryption = types.ServerSideEncryptionAwsKms
+		output.SSEKMSKeyId = aws.String(object.sseKMSKeyID)
+	}
+	return output, nil
+}
+
+// DeleteObjects goes through the same batching as the real client, so each call to the
+// "DeleteObjects" failure hook or FailKey applies per batch and per key
+func (m *MockS3API) DeleteObjects(ctx context.Context, bucketName string, keys []string) (*DeleteObjectsResult, error) {
+	return deleteInBatches(ctx, keys, func(ctx context.Context, batch []string) (*DeleteObjectsResult, error) {
+		if err := m.fail(ctx, "DeleteObjects"); err != nil {
+			return nil, err
+		}
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if len(batch) > MaxDeleteBatchSize {
+			return nil, &smithy.GenericAPIError{Code: "MalformedXML", Message: "too many keys in one request"}
+		}
+		bucket := m.bucket(bucketName, false)
+		if bucket == nil {
+			return nil, ErrBucketNotFound
+		}
+		result := &DeleteObjectsResult{}
+		for _, key := range batch {
+			if err, ok := m.keyFailures[key]; ok {
+				result.Errors = append(result.Errors, DeleteError{Key: key, Code: errorCode(err), Message: err.Error()})
+				continue
+			}
+			m.remove(bucket, key)
+			result.Deleted = append(result.Deleted, key)
+		}
+		return result, nil
+	})
+}
+
+func (m *MockS3API) CheckObjectExists(ctx context.Context, bucketName string, key string) (bool, error) {
+	if err := m.fail(ctx, "CheckObjectExists", key); err != nil {
+		return false, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, err := m.object(bucketName, key)
+	return err == nil, nil
+}
+
+func (m *MockS3API) ListBuckets(ctx context.Context) ([]types.Bucket, error) {
+	if err := m.fail(ctx, "ListBuckets"); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.buckets))
+	for name := range m.buckets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buckets := make([]types.Bucket, 0, len(names))
+	for _, name := range names {
+		buckets = append(buckets, types.Bucket{
+			Name:         aws.String(name),
+			CreationDate: aws.Time(m.buckets[name].createdAt),
+		})
+	}
+	return buckets, nil
+}
+
+func (m *MockS3API) BucketExists(ctx context.Context, bucketName string) (bool, error) {
+	if err := m.fail(ctx, "BucketExists"); err != nil {
+		return false, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.bucket(bucketName, false) != nil, nil
+}
+
+func (m *MockS3API) CreateBucket(ctx context.Context, name string, region string) error {
+	if err := m.fail(ctx, "CreateBucket"); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.bucket(name, false) != nil {
+		return &types.BucketAlreadyOwnedByYou{Message: aws.String("bucket already exists")}
+	}
+	m.bucket(name, true).region = region
+	return nil
+}
+
+func (m *MockS3API) UploadFile(ctx context.Context, bucketName string, objectKey string, fileContent []byte, options ObjectOptions) error {
+	if err := m.fail(ctx, "UploadFile", objectKey); err != nil {
+		return err
+	}
+	if options.ChecksumSHA256 != "" && options.ChecksumSHA256 != ChecksumSHA256(fileContent) {
+		return ErrChecksumMismatch
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bucket := m.bucket(bucketName, false)
+	if bucket == nil {
+		return &types.NoSuchBucket{Message: aws.String(bucketName)}
+	}
+	m.put(bucket, objectKey, newMockObjectWithOptions(fileContent, options))
+	return nil
+}
+
+func (m *MockS3API) DownloadFile(ctx context.Context, bucketName string, objectKey string) ([]byte, error) {
+	if err := m.fail(ctx, "DownloadFile", objectKey); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	object, err := m.object(bucketName, objectKey)
+	if err != nil {
+		return nil, &types.NoSuchKey{Message: aws.String(objectKey)}
+	}
+	if err := verifyChecksum(object.data, object.checksum); err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), object.data...), nil
+}
+
+// matchesETag reports whether an If-None-Match header, a list of ETags or *, matches etag
+func matchesETag(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if candidate = strings.TrimSpace(candidate); candidate == "*" || (candidate != "" && candidate == etag) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MockS3API) GetObjectStream(ctx context.Context, bucketName string, objectKey string, options GetObjectOptions) (*ObjectStream, error) {
+	if err := m.fail(ctx, "GetObjectStream", objectKey); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	object, err := m.version(bucketName, objectKey, options.VersionID)
+	if err != nil {
+		return nil, err
+	}
+	if matchesETag(options.IfNoneMatch, object.etag) {
+		return nil, &NotModifiedError{ETag: object.etag}
+	}
+
+	size := int64(len(object.data))
+	data := object.data
+	contentRange := ""
+	if options.Range != "" {
+		start, end, err := parseMockRange(options.Range, size)
+		if err != nil {
+			return nil, err
+		}
+		data = object.data[start : end+1]
+		contentRange = fmt.Sprintf("bytes %d-%d/%d", start, end, size)
+	}
+
+	stream := &ObjectStream{
+		Body:          io.NopCloser(bytes.NewReader(append([]byte(nil), data...))),
+		ContentType:   object.contentType,
+		ContentLength: int64(len(data)),
+		ContentRange:  contentRange,
+		ETag:          object.etag,
+		VersionID:     object.versionID,
+		LastModified:  object.lastModified,
+	}
+	if options.Range == "" {
+		stream.ChecksumSHA256 = object.checksum
+		stream.Body = verifiedBody(stream.Body, object.checksum)
+	}
+	return stream, nil
+}
+
+func (m *MockS3API) CreateMultipartUpload(ctx context.Context, bucketName string, objectKey string, options ObjectOptions) (string, error) {
+	if err := m.fail(ctx, "CreateMultipartUpload", objectKey); err != nil {
+		return "", err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.bucket(bucketName, false) == nil {
+		return "", &types.NoSuchBucket{Message: aws.String(bucketName)}
+	}
+	if m.uploads == nil {
+		m.uploads = map[string]*mockUpload{}
+	}
+	m.uploadSeq++
+	uploadID := fmt.Sprintf("mock-upload-%d", m.uploadSeq)
+	m.uploads[uploadID] = &mockUpload{
+		bucketName: bucketName,
+		objectKey:  objectKey,
+		options:    options,
+		initiated:  time.Now(),
+		parts:      map[int32]*mockObject{},
+	}
+	return uploadID, nil
+}
+
+func (m *MockS3API) PresignUploadPartURL(ctx context.Context, bucketName string, objectKey string, uploadID string, partNumber int32) (string, error) {
+	if err := m.fail(ctx, "PresignUploadPartURL", objectKey); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("https://%s.s3.amazonaws.com/%s?partNumber=%d&uploadId=%s&presigned=true", bucketName, objectKey, partNumber, uploadID), nil
+}
+
+func (m *MockS3API) ListParts(ctx context.Context, bucketName string, objectKey string, uploadID string) ([]types.Part, error) {
+	if err := m.fail(ctx, "ListParts", objectKey); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	upload, err := m.upload(bucketName, objectKey, uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]types.Part, 0, len(upload.parts))
+	for partNumber, part := range upload.parts {
+		parts = append(parts, types.Part{
+			PartNumber:   aws.Int32(partNumber),
+			ETag:         aws.String(part.etag),
+			Size:         aws.Int64(int64(len(part.data))),
+			LastModified: aws.Time(part.lastModified),
+		})
+	}
+	sort.Slice(parts, func(i, j int) bool {
+		return aws.ToInt32(parts[i].PartNumber) < aws.ToInt32(parts[j].PartNumber)
+	})
+	return parts, nil
+}
+
+func (m *MockS3API) CompleteMultipartUpload(ctx context.Context, bucketName string, objectKey string, uploadID string, parts []types.CompletedPart) error {
+	if err := m.fail(ctx, "CompleteMultipartUpload", objectKey); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	upload, err := m.upload(bucketName, objectKey, uploadID)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	for _, completed := range parts {
+		part, ok := upload.parts[aws.ToInt32(completed.PartNumber)]
+		if !ok || part.etag != aws.ToString(completed.ETag) {
+			return &smithy.GenericAPIError{Code: "InvalidPart", Message: "one or more of the specified parts could not be found"}
+		}
+		data = append(data, part.data...)
+	}
+
+	m.put(m.bucket(bucketName, true), objectKey, newMockObjectWithOptions(data, upload.options))
+	delete(m.uploads, uploadID)
+	return nil
+}
+
+func (m *MockS3API) AbortMultipartUpload(ctx context.Context, bucketName string, objectKey string, uploadID string) error {
+	if err := m.fail(ctx, "AbortMultipartUpload", objectKey); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, err := m.upload(bucketName, objectKey, uploadID); err != nil {
+		return err
+	}
+	delete(m.uploads, uploadID)
+	return nil
+}
+
+func (m *MockS3API) ListMultipartUploads(ctx context.Context, bucketName string) ([]types.MultipartUpload, error) {
+	if err := m.fail(ctx, "ListMultipartUploads"); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var uploads []types.MultipartUpload
+	for uploadID, upload := range m.uploads {
+		if upload.bucketName != bucketName {
+			continue
+		}
+		uploads = append(uploads, types.MultipartUpload{
+			Key:       aws.String(upload.objectKey),
+			UploadId:  aws.String(uploadID),
+			Initiated: aws.Time(upload.initiated),
+		})
+	}
+	sort.Slice(uploads, func(i, j int) bool {
+		return aws.ToString(uploads[i].UploadId) < aws.ToString(uploads[j].UploadId)
+	})
+	return uploads, nil
+}
+
+func (m *MockS3API) CopyObject(ctx context.Context, sourceBucket string, sourceKey string, destBucket string, destKey string, options ObjectOptions) error {
+	if err := m.fail(ctx, "CopyObject", sourceKey, destKey); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	source, err := m.object(sourceBucket, sourceKey)
+	if err != nil {
+		return &types.NoSuchKey{Message: aws.String(sourceKey)}
+	}
+	dest := m.bucket(destBucket, false)
+	if dest == nil {
+		return &types.NoSuchBucket{Message: aws.String(destBucket)}
+	}
+	copied := newMockObject(source.data, source.contentType)
+	copied.metadata = copyMetadata(source.metadata)
+	if options.ContentType != "" || options.Metadata != nil {
+		// MetadataDirective REPLACE
+		copied.contentType = options.ContentType
+		if copied.contentType == "" {
+			copied.contentType = "application/octet-stream"
+		}
+		copied.metadata = copyMetadata(options.Metadata)
+	}
+	copied.storageClass = options.StorageClass
+	copied.acl = options.ACL
+	copied.sseKMSKeyID = options.SSEKMSKeyID
+	copied.tags = copyMetadata(source.tags)
+	m.put(dest, destKey, copied)
+	return nil
+}
+
+// CopyLargeObject behaves like CopyObject, the parts of a multipart copy aren't modelled
+func (m *MockS3API) CopyLargeObject(ctx context.Context, sourceBucket string, sourceKey string, destBucket string, destKey string, size int64, options ObjectOptions) error {
+	if err := m.fail(ctx, "CopyLargeObject", sourceKey, destKey); err != nil {
+		return err
+	}
+	return m.CopyObject(ctx, sourceBucket, sourceKey, destBucket, destKey, options)
+}
+
+func (m *MockS3API) ListObjects(ctx context.Context, bucketName string, prefix string, delimiter string, continuationToken string, maxKeys int32) (*ListObjectsResult, error) {
+	if err := m.fail(ctx, "ListObjects"); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bucket := m.bucket(bucketName, false)
+	if bucket == nil {
+		return nil, ErrBucketNotFound
+	}
+	if maxKeys <= 0 {
+		maxKeys = 1000
 	}
-	return fmt.Sprintf("https://mock-bucket.s3.amazonaws.com/%s?presigned=true", key), nil
+
+	keys := make([]string, 0, len(bucket.objects))
+	for key := range bucket.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	// The continuation token is the last key or common prefix of the previous page
+	result := &ListObjectsResult{
+		Objects:        []ObjectSummary{},
+		CommonPrefixes: []string{},
+	}
+	count := int32(0)
+	last := ""
+	for _, key := range keys {
+		if continuationToken != "" && (key <= continuationToken || (delimiter != "" && strings.HasSuffix(continuationToken, delimiter) && strings.HasPrefix(key, continuationToken))) {
+			continue
+		}
+
+		entry := key
+		commonPrefix := ""
+		if delimiter != "" {
+			if index := strings.Index(key[len(prefix):], delimiter); index >= 0 {
+				commonPrefix = key[:len(prefix)+index+len(delimiter)]
+				entry = commonPrefix
+			}
+		}
+		if commonPrefix != "" && commonPrefix == last {
+			continue
+		}
+
+		if count == maxKeys {
+			result.IsTruncated = true
+			result.NextContinuationToken = last
+			break
+		}
+		if commonPrefix != "" {
+			result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix)
+		} else {
+			object := bucket.objects[key]
+			result.Objects = append(result.Objects, ObjectSummary{
+				Key:          key,
+				Size:         int64(len(object.data)),
+				ETag:         object.etag,
+				StorageClass: object.storageClassOrDefault(),
+				LastModified: object.lastModified,
+			})
+		}
+		last = entry
+		count++
+	}
+	return result, nil
+}
+
+func (m *MockS3API) DeleteBucket(ctx context.Context, bucketName string) error {
+	if err := m.fail(ctx, "DeleteBucket"); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bucket := m.bucket(bucketName, false)
+	if bucket == nil {
+		return &types.NoSuchBucket{Message: aws.String(bucketName)}
+	}
+	if len(bucket.versions) > 0 {
+		return &smithy.GenericAPIError{Code: "BucketNotEmpty", Message: "the bucket you tried to delete is not empty"}
+	}
+	delete(m.buckets, bucketName)
+	return nil
+}
+
+func (m *MockS3API) GetObjectTagging(ctx context.Context, bucketName string, objectKey string, versionID string) (map[string]string, error) {
+	if err := m.fail(ctx, "GetObjectTagging", objectKey); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	object, err := m.version(bucketName, objectKey, versionID)
+	if err != nil {
+		return nil, err
+	}
+	tags := copyMetadata(object.tags)
+	if tags == nil {
+		tags = map[string]string{}
+	}
+	return tags, nil
+}
+
+func (m *MockS3API) PutObjectTagging(ctx context.Context, bucketName string, objectKey string, versionID string, tags map[string]string) error {
+	if err := m.fail(ctx, "PutObjectTagging", objectKey); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	object, err := m.version(bucketName, objectKey, versionID)
+	if err != nil {
+		return err
+	}
+	object.tags = copyMetadata(tags)
+	return nil
+}
+
+func (m *MockS3API) DeleteObjectTagging(ctx context.Context, bucketName string, objectKey string, versionID string) error {
+	if err := m.fail(ctx, "DeleteObjectTagging", objectKey); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	object, err := m.version(bucketName, objectKey, versionID)
+	if err != nil {
+		return err
+	}
+	object.tags = nil
+	return nil
+}
+
+func (m *MockS3API) ListObjectVersions(ctx context.Context, bucketName string, prefix string, keyMarker string, versionIDMarker string, maxKeys int32) (*ListObjectVersionsResult, error) {
+	if err := m.fail(ctx, "ListObjectVersions"); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bucket := m.bucket(bucketName, false)
+	if bucket == nil {
+		return nil, ErrBucketNotFound
+	}
+	if maxKeys <= 0 {
+		maxKeys = 1000
+	}
+
+	keys := make([]string, 0, len(bucket.versions))
+	for key := range bucket.versions {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	// Every version in listing order: by key, newest first
+	var all []ObjectVersion
+	for _, key := range keys {
+		versions := bucket.versions[key]
+		for i := len(versions) - 1; i >= 0; i-- {
+			version := versions[i]
+			objectVersion := ObjectVersion{
+				Key:            key,
+				VersionID:      version.versionID,
+				IsLatest:       i == len(versions)-1,
+				IsDeleteMarker: version.deleteMarker,
+				LastModified:   version.lastModified,
+			}
+			if objectVersion.VersionID == "" {
+				objectVersion.VersionID = "null"
+			}
+			if !version.deleteMarker {
+				objectVersion.Size = int64(len(version.data))
+				objectVersion.ETag = version.etag
+				objectVersion.StorageClass = version.storageClassOrDefault()
+			}
+			all = append(all, objectVersion)
+		}
+	}
+
+	// Without a version marker the page starts after keyMarker, with one it starts after that version
+	start := 0
+	if keyMarker != "" {
+		start = len(all)
+		for i, version := range all {
+			if versionIDMarker == "" && version.Key > keyMarker {
+				start = i
+				break
+			}
+			if versionIDMarker != "" && version.Key == keyMarker && version.VersionID == versionIDMarker {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	end := min(start+int(maxKeys), len(all))
+	result := &ListObjectVersionsResult{Versions: append([]ObjectVersion{}, all[start:end]...)}
+	if end < len(all) {
+		last := all[end-1]
+		result.IsTruncated = true
+		result.NextKeyMarker = last.Key
+		result.NextVersionIDMarker = last.VersionID
+	}
+	return result, nil
+}
+
+func (m *MockS3API) DeleteObjectVersion(ctx context.Context, bucketName string, objectKey string, versionID string) error {
+	if err := m.fail(ctx, "DeleteObjectVersion", objectKey); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bucket := m.bucket(bucketName, false)
+	if bucket == nil {
+		return &types.NoSuchBucket{Message: aws.String(bucketName)}
+	}
+	versions := bucket.versions[objectKey]
+	index := findMockVersion(versions, versionID)
+	if index < 0 {
+		return ErrVersionNotFound
+	}
+	versions = append(versions[:index:index], versions[index+1:]...)
+
+	// The newest remaining version becomes the latest, unless it is a delete marker
+	if len(versions) == 0 {
+		delete(bucket.versions, objectKey)
+		delete(bucket.objects, objectKey)
+		return nil
+	}
+	bucket.versions[objectKey] = versions
+	if latest := versions[len(versions)-1]; latest.deleteMarker {
+		delete(bucket.objects, objectKey)
+	} else {
+		bucket.objects[objectKey] = latest
+	}
+	return nil
 }
 
-func (m *MockS3API) PresignPutURL(key string, contentType string) (string, error) {
+func (m *MockS3API) RestoreObjectVersion(ctx context.Context, bucketName string, objectKey string, versionID string) (string, error) {
+	if err := m.fail(ctx, "RestoreObjectVersion", objectKey); err != nil {
+		return "", err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	source, err := m.version(bucketName, objectKey, versionID)
+	if err != nil {
+		return "", err
+	}
+	restored := newMockObject(source.data, source.contentType)
+	restored.metadata = copyMetadata(source.metadata)
+	restored.tags = copyMetadata(source.tags)
+	m.put(m.bucket(bucketName, false), objectKey, restored)
+	return restored.versionID, nil
+}
+
+func (m *MockS3API) GetBucketVersioning(ctx context.Context, bucketName string) (string, error) {
+	if err := m.fail(ctx, "GetBucketVersioning"); err != nil {
+		return "", err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bucket := m.bucket(bucketName, false)
+	if bucket == nil {
+		return "", &types.NoSuchBucket{Message: aws.String(bucketName)}
+	}
+	return bucket.versioning, nil
+}
+
+func (m *MockS3API) PutBucketVersioning(ctx context.Context, bucketName string, enabled bool) error {
+	if err := m.fail(ctx, "PutBucketVersioning"); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bucket := m.bucket(bucketName, false)
+	if bucket == nil {
+		return &types.NoSuchBucket{Message: aws.String(bucketName)}
+	}
+	bucket.versioning = string(types.BucketVersioningStatusSuspended)
+	if enabled {
+		bucket.versioning = string(types.BucketVersioningStatusEnabled)
+	}
+	return nil
+}
+
+func (m *MockS3API) GetBucketLifecycle(ctx context.Context, bucketName string) ([]LifecycleRule, error) {
+	if err := m.fail(ctx, "GetBucketLifecycle"); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bucket := m.bucket(bucketName, false)
+	if bucket == nil {
+		return nil, ErrBucketNotFound
+	}
+	return append([]LifecycleRule{}, bucket.lifecycle...), nil
+}
+
+func (m *MockS3API) PutBucketLifecycle(ctx context.Context, bucketName string, rules []LifecycleRule) error {
+	if err := m.fail(ctx, "PutBucketLifecycle"); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bucket := m.bucket(bucketName, false)
+	if bucket == nil {
+		return ErrBucketNotFound
+	}
+	bucket.lifecycle = append([]LifecycleRule(nil), rules...)
+	return nil
+}
+
+func (m *MockS3API) GetBucketCORS(ctx context.Context, bucketName string) ([]CORSRule, error) {
+	if err := m.fail(ctx, "GetBucketCORS"); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bucket := m.bucket(bucketName, false)
+	if bucket == nil {
+		return nil, ErrBucketNotFound
+	}
+	return append([]CORSRule{}, bucket.cors...), nil
+}
+
+func (m *MockS3API) PutBucketCORS(ctx context.Context, bucketName string, rules []CORSRule) error {
+	if err := m.fail(ctx, "PutBucketCORS"); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bucket := m.bucket(bucketName, false)
+	if bucket == nil {
+		return ErrBucketNotFound
+	}
+	bucket.cors = append([]CORSRule(nil), rules...)
+	return nil
+}
+
+func (m *MockS3API) GetPublicAccessBlock(ctx context.Context, bucketName string) (*PublicAccessBlock, error) {
+	if err := m.fail(ctx, "GetPublicAccessBlock"); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bucket := m.bucket(bucketName, false)
+	if bucket == nil {
+		return nil, ErrBucketNotFound
+	}
+	block := bucket.access
+	return &block, nil
+}
+
+func (m *MockS3API) PutPublicAccessBlock(ctx context.Context, bucketName string, block PublicAccessBlock) error {
+	if err := m.fail(ctx, "PutPublicAccessBlock"); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bucket := m.bucket(bucketName, false)
+	if bucket == nil {
+		return ErrBucketNotFound
+	}
+	bucket.access = block
+	return nil
+}
+
+// fail returns the context error, the injected failure for method, or the one for the first failing key
+func (m *MockS3API) fail(ctx context.Context, method string, keys ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.ShouldFail {
-		return "", fmt.Errorf("mock S3 error")
+		return fmt.Errorf("mock S3 error")
+	}
+	if err, ok := m.failures[method]; ok {
+		return err
+	}
+	for _, key := range keys {
+		if err, ok := m.keyFailures[key]; ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// bucket must be called with mu held
+func (m *MockS3API) bucket(bucketName string, create bool) *mockBucket {
+	if m.buckets == nil {
+		m.buckets = map[string]*mockBucket{}
+	}
+	bucket, ok := m.buckets[bucketName]
+	if !ok && create {
+		bucket = &mockBucket{createdAt: time.Now(), objects: map[string]*mockObject{}, versions: map[string][]*mockObject{}}
+		m.buckets[bucketName] = bucket
+	}
+	return bucket
+}
+
+// object must be called with mu held
+func (m *MockS3API) object(bucketName string, objectKey string) (*mockObject, error) {
+	bucket := m.bucket(bucketName, false)
+	if bucket == nil {
+		return nil, ErrObjectNotFound
+	}
+	object, ok := bucket.objects[objectKey]
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+	return object, nil
+}
+
+// version returns the latest version when versionID is empty. Must be called with mu held.
+func (m *MockS3API) version(bucketName string, objectKey string, versionID string) (*mockObject, error) {
+	if versionID == "" {
+		return m.object(bucketName, objectKey)
+	}
+	bucket := m.bucket(bucketName, false)
+	if bucket == nil {
+		return nil, ErrVersionNotFound
+	}
+	versions := bucket.versions[objectKey]
+	index := findMockVersion(versions, versionID)
+	if index < 0 {
+		return nil, ErrVersionNotFound
+	}
+	if versions[index].deleteMarker {
+		return nil, ErrObjectNotFound
+	}
+	return versions[index], nil
+}
+
+// put stores object as the latest version of objectKey. Must be called with mu held.
+func (m *MockS3API) put(bucket *mockBucket, objectKey string, object *mockObject) {
+	object.versionID = m.nextVersionID(bucket)
+	bucket.addVersion(objectKey, object)
+	bucket.objects[objectKey] = object
+}
+
+// remove deletes objectKey the way a DELETE without a version does: the object is gone from
+// an unversioned bucket, a versioned bucket gets a delete marker. Must be called with mu held.
+func (m *MockS3API) remove(bucket *mockBucket, objectKey string) {
+	delete(bucket.objects, objectKey)
+	if bucket.versioning == "" {
+		delete(bucket.versions, objectKey)
+		return
+	}
+	marker := &mockObject{deleteMarker: true, lastModified: time.Now().UTC().Truncate(time.Second)}
+	marker.versionID = m.nextVersionID(bucket)
+	bucket.addVersion(objectKey, marker)
+}
+
+// nextVersionID must be called with mu held
+func (m *MockS3API) nextVersionID(bucket *mockBucket) string {
+	switch bucket.versioning {
+	case string(types.BucketVersioningStatusEnabled):
+		m.versionSeq++
+		return fmt.Sprintf("mock-version-%d", m.versionSeq)
+	case string(types.BucketVersioningStatusSuspended):
+		return "null"
+	}
+	return ""
+}
+
+// addVersion appends a version. A null version replaces the previous null version, like S3 does.
+func (bucket *mockBucket) addVersion(objectKey string, object *mockObject) {
+	versions := bucket.versions[objectKey]
+	if object.versionID == "" || object.versionID == "null" {
+		kept := versions[:0:0]
+		for _, version := range versions {
+			if version.versionID != "" && version.versionID != "null" {
+				kept = append(kept, version)
+			}
+		}
+		versions = kept
+	}
+	bucket.versions[objectKey] = append(versions, object)
+}
+
+func findMockVersion(versions []*mockObject, versionID string) int {
+	for i, version := range versions {
+		if version.versionID == versionID || (versionID == "null" && version.versionID == "") {
+			return i
+		}
+	}
+	return -1
+}
+
+// upload must be called with mu held
+func (m *MockS3API) upload(bucketName string, objectKey string, uploadID string) (*mockUpload, error) {
+	upload, ok := m.uploads[uploadID]
+	if !ok || upload.bucketName != bucketName || upload.objectKey != objectKey {
+		return nil, ErrUploadNotFound
+	}
+	return upload, nil
+}
+
+func newMockObject(data []byte, contentType string) *mockObject {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	sum := md5.Sum(data)
+	return &mockObject{
+		data:         append([]byte(nil), data...),
+		contentType:  contentType,
+		etag:         `"` + hex.EncodeToString(sum[:]) + `"`,
+		lastModified: time.Now().UTC().Truncate(time.Second),
+		checksum:     ChecksumSHA256(data),
+	}
+}
+
+func newMockObjectWithOptions(data []byte, options ObjectOptions) *mockObject {
+	object := newMockObject(data, options.ContentType)
+	object.metadata = copyMetadata(options.Metadata)
+	object.storageClass = options.StorageClass
+	object.acl = options.ACL
+	object.sseKMSKeyID = options.SSEKMSKeyID
+	return object
+}
+
+func (object *mockObject) storageClassOrDefault() string {
+	if object.storageClass == "" {
+		return string(types.ObjectStorageClassStandard)
+	}
+	return object.storageClass
+}
+
+func copyMetadata(metadata map[string]string) map[string]string {
+	if metadata == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		copied[k] = v
+	}
+	return copied
+}
+
+// parseMockRange supports the single-range forms S3 accepts: "bytes=a-b", "bytes=a-" and "bytes=-n"
+func parseMockRange(header string, size int64) (int64, int64, error) {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return 0, size - 1, nil
+	}
+	startText, endText, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, size - 1, nil
+	}
+
+	if startText == "" {
+		suffix, err := strconv.ParseInt(endText, 10, 64)
+		if err != nil || suffix <= 0 || size == 0 {
+			return 0, 0, ErrInvalidRange
+		}
+		return max(size-suffix, 0), size - 1, nil
+	}
+
+	start, err := strconv.ParseInt(startText, 10, 64)
+	if err != nil || start >= size {
+		return 0, 0, ErrInvalidRange
+	}
+	end := size - 1
+	if endText != "" {
+		end, err = strconv.ParseInt(endText, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, ErrInvalidRange
+		}
+		end = min(end, size-1)
 	}
-	return fmt.Sprintf("https://mock-bucket.s3.amazonaws.com/%s?presigned=true&content-type=%s", key, contentType), nil
+	return start, end, nil
 }
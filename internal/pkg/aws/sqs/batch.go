@@ -0,0 +1,216 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go-base/internal/pkg/logger"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+const (
+	// MaxBatchEntries is the most messages SQS accepts in one SendMessageBatch
+	MaxBatchEntries = 10
+	// MaxBatchSize is the most bytes SQS accepts in one message or one batch
+	MaxBatchSize = 256 * 1024
+	// MaxDelaySeconds is the longest DelaySeconds SQS accepts
+	MaxDelaySeconds int32 = 900
+
+	maxBatchRetries = 3
+)
+
+var (
+	ErrMessageTooLarge = errors.New("message exceeds 256 KB")
+	// batchRetryBackoff is multiplied by the attempt before failed entries are retried
+	batchRetryBackoff = 100 * time.Millisecond
+)
+
+// OutgoingMessage is a message to send with its optional settings. DelaySeconds only
+// applies to standard queues, MessageGroupID and MessageDeduplicationID to FIFO queues.
+type OutgoingMessage struct {
+	Body                   string
+	Attributes             map[string]string // sent as String message attributes
+	DelaySeconds           int32
+	MessageGroupID         string
+	MessageDeduplicationID string
+}
+
+// SendResult is the outcome of one message of a batch, Err is nil when it was sent
+type SendResult struct {
+	MessageID string
+	Err       error
+}
+
+// BatchEntryError is the failure SQS reported for a single entry of a batch. Entries
+// failed by the sender, e.g. an invalid attribute, are never retried.
+type BatchEntryError struct {
+	Code        string
+	Message     string
+	SenderFault bool
+}
+
+func (e *BatchEntryError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// size is what the message counts against MaxBatchSize
+func (m OutgoingMessage) size() int {
+	size := len(m.Body)
+	for name, value := range m.Attributes {
+		size += len(name) + len("String") + len(value)
+	}
+	return size
+}
+
+// batchSender sends at most MaxBatchEntries messages, no larger than MaxBatchSize together,
+// in one request and returns a result for each of them
+type batchSender func(ctx context.Context, messages []OutgoingMessage) []SendResult
+
+// sendMessageBatch splits messages into batches SQS accepts and retries the entries that
+// failed without being the sender's fault. Results are in the order of messages.
+func sendMessageBatch(ctx context.Context, messages []OutgoingMessage, send batchSender) []SendResult {
+	results := make([]SendResult, len(messages))
+	var pending []int
+	for i, message := range messages {
+		if message.size() > MaxBatchSize {
+			results[i].Err = ErrMessageTooLarge
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	for attempt := 0; len(pending) > 0; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				for _, i := range pending {
+					results[i].Err = ctx.Err()
+				}
+				return results
+			case <-time.After(batchRetryBackoff * time.Duration(attempt)):
+			}
+		}
+
+		var retry []int
+		for _, chunk := range chunkBatch(messages, pending) {
+			batch := make([]OutgoingMessage, len(chunk))
+			for j, i := range chunk {
+				batch[j] = messages[i]
+			}
+			for j, result := range send(ctx, batch) {
+				results[chunk[j]] = result
+				if result.Err != nil && retryable(result.Err) && attempt < maxBatchRetries {
+					retry = append(retry, chunk[j])
+				}
+			}
+		}
+		if len(retry) > 0 {
+			logger.Warn.Printf("retrying %d of %d messages, attempt %d", len(retry), len(pending), attempt+1)
+		}
+		pending = retry
+	}
+	return results
+}
+
+// chunkBatch groups the indexes of messages into batches of at most MaxBatchEntries
+// entries and MaxBatchSize bytes, keeping their order
+func chunkBatch(messages []OutgoingMessage, indexes []int) [][]int {
+	var chunks [][]int
+	var chunk []int
+	chunkSize := 0
+	for _, i := range indexes {
+		size := messages[i].size()
+		if len(chunk) == MaxBatchEntries || (len(chunk) > 0 && chunkSize+size > MaxBatchSize) {
+			chunks = append(chunks, chunk)
+			chunk, chunkSize = nil, 0
+		}
+		chunk = append(chunk, i)
+		chunkSize += size
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+func retryable(err error) bool {
+	var entryErr *BatchEntryError
+	if errors.As(err, &entryErr) {
+		return !entryErr.SenderFault
+	}
+	return !errors.Is(err, ErrMessageTooLarge) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+func (manager *BaseSQSAPI) SendMessageBatch(ctx context.Context, messages []OutgoingMessage) []SendResult {
+	return sendMessageBatch(ctx, messages, manager.sendBatch)
+}
+
+func (manager *BaseSQSAPI) sendBatch(ctx context.Context, messages []OutgoingMessage) []SendResult {
+	results := make([]SendResult, len(messages))
+	entries := make([]types.SendMessageBatchRequestEntry, len(messages))
+	for i, message := range messages {
+		entries[i] = types.SendMessageBatchRequestEntry{
+			Id:                     aws.String(strconv.Itoa(i)),
+			MessageBody:            aws.String(message.Body),
+			DelaySeconds:           message.DelaySeconds,
+			MessageAttributes:      messageAttributes(message.Attributes),
+			MessageGroupId:         optionalString(message.MessageGroupID),
+			MessageDeduplicationId: optionalString(message.MessageDeduplicationID),
+		}
+	}
+
+	output, err := manager.client.SendMessageBatch(ctx, &sqs.SendMessageBatchInput{
+		QueueUrl: manager.queueURL,
+		Entries:  entries,
+	})
+	if err != nil {
+		logger.Error.Printf("send message batch fail, %+v", err)
+		for i := range results {
+			results[i].Err = err
+		}
+		return results
+	}
+
+	for _, entry := range output.Successful {
+		if i, err := strconv.Atoi(aws.ToString(entry.Id)); err == nil && i < len(results) {
+			results[i].MessageID = aws.ToString(entry.MessageId)
+		}
+	}
+	for _, entry := range output.Failed {
+		if i, err := strconv.Atoi(aws.ToString(entry.Id)); err == nil && i < len(results) {
+			results[i].Err = &BatchEntryError{
+				Code:        aws.ToString(entry.Code),
+				Message:     aws.ToString(entry.Message),
+				SenderFault: entry.SenderFault,
+			}
+		}
+	}
+	return results
+}
+
+func messageAttributes(attributes map[string]string) map[string]types.MessageAttributeValue {
+	if len(attributes) == 0 {
+		return nil
+	}
+	values := make(map[string]types.MessageAttributeValue, len(attributes))
+	for name, value := range attributes {
+		values[name] = types.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(value),
+		}
+	}
+	return values
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return aws.String(value)
+}
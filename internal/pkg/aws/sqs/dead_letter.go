@@ -0,0 +1,81 @@
+package sqs
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Failure metadata added to messages moved to a dead-letter queue
+const (
+	DeadLetterSourceQueueAttribute  = "dlq.source-queue"
+	DeadLetterErrorAttribute        = "dlq.error"
+	DeadLetterReceiveCountAttribute = "dlq.receive-count"
+	DeadLetterFailedAtAttribute     = "dlq.failed-at"
+
+	deadLetterAttributePrefix = "dlq."
+	// MaxMessageAttributes is the most message attributes SQS accepts on a message
+	MaxMessageAttributes     = 10
+	maxDeadLetterErrorLength = 1024
+)
+
+// DeadLetter returns the message to send to a dead-letter queue for a message of
+// sourceQueue that failed with cause. The failure metadata replaces original attributes
+// that wouldn't fit within MaxMessageAttributes, the last ones by name.
+func DeadLetter(message Message, sourceQueue string, cause error) OutgoingMessage {
+	reason := cause.Error()
+	if len(reason) > maxDeadLetterErrorLength {
+		reason = reason[:maxDeadLetterErrorLength]
+	}
+	metadata := map[string]string{
+		DeadLetterSourceQueueAttribute:  sourceQueue,
+		DeadLetterErrorAttribute:        reason,
+		DeadLetterReceiveCountAttribute: strconv.Itoa(message.ReceiveCount),
+		DeadLetterFailedAtAttribute:     time.Now().UTC().Format(time.RFC3339),
+	}
+
+	attributes := make(map[string]string, MaxMessageAttributes)
+	for _, name := range sortedAttributeNames(message.Attributes) {
+		if len(attributes) == MaxMessageAttributes-len(metadata) {
+			break
+		}
+		if !IsDeadLetterAttribute(name) {
+			attributes[name] = message.Attributes[name]
+		}
+	}
+	for name, value := range metadata {
+		attributes[name] = value
+	}
+	return OutgoingMessage{Body: message.Body, Attributes: attributes}
+}
+
+// Redrive returns a dead-lettered message as it should be sent back to its source queue,
+// without the failure metadata
+func Redrive(message Message) OutgoingMessage {
+	var attributes map[string]string
+	for name, value := range message.Attributes {
+		if IsDeadLetterAttribute(name) {
+			continue
+		}
+		if attributes == nil {
+			attributes = make(map[string]string, len(message.Attributes))
+		}
+		attributes[name] = value
+	}
+	return OutgoingMessage{Body: message.Body, Attributes: attributes}
+}
+
+// IsDeadLetterAttribute reports whether name is failure metadata added by DeadLetter
+func IsDeadLetterAttribute(name string) bool {
+	return strings.HasPrefix(name, deadLetterAttributePrefix)
+}
+
+func sortedAttributeNames(attributes map[string]string) []string {
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,24 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+
+	"go-base/internal/pkg/health"
+)
+
+// HealthChecker reports whether queueName can be reached with GetQueueAttributes
+func HealthChecker(queueName string) health.HealthChecker {
+	return health.NewChecker("sqs", func(ctx context.Context) error {
+		queues := GetInstance()
+		if queues == nil {
+			return errors.New("sqs not set up")
+		}
+		queue, err := queues.Queue(ctx, queueName)
+		if err != nil {
+			return err
+		}
+		_, err = queue.GetQueueAttributes(ctx, "ApproximateNumberOfMessages")
+		return err
+	})
+}
@@ -0,0 +1,112 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"go-base/internal/pkg/aws/endpoint"
+	"go-base/internal/pkg/logger"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/aws/smithy-go"
+)
+
+var _ QueueAPI = (*Registry)(nil)
+
+var (
+	ErrQueueNotAllowed = errors.New("queue not allowed")
+	ErrQueueNotFound   = errors.New("queue not found")
+)
+
+// Registry resolves queue names in its allow-list to queue URLs and caches them, so
+// every queue shares one client and GetQueueUrl is called once per queue
+type Registry struct {
+	client    *sqs.Client
+	allowed   map[string]bool
+	mu        sync.RWMutex
+	queueURLs map[string]string
+}
+
+type RegistryConfig struct {
+	Region     string
+	Endpoint   endpoint.Config
+	QueueNames []string // allow-list, queues not in it are rejected
+}
+
+func NewRegistry(setupConfig RegistryConfig) (*Registry, error) {
+	cfg, err := config.LoadDefaultConfig(context.TODO(), setupConfig.Endpoint.LoadOptions()...)
+	if err != nil {
+		return nil, err
+	}
+
+	client := sqs.NewFromConfig(cfg, func(o *sqs.Options) {
+		o.Region = setupConfig.Region
+		o.BaseEndpoint = setupConfig.Endpoint.BaseEndpoint()
+		o.EndpointOptions.DisableHTTPS = setupConfig.Endpoint.DisableTLS
+	})
+
+	allowed := make(map[string]bool, len(setupConfig.QueueNames))
+	for _, queueName := range setupConfig.QueueNames {
+		if queueName = strings.TrimSpace(queueName); queueName != "" {
+			allowed[queueName] = true
+		}
+	}
+
+	return &Registry{
+		client:    client,
+		allowed:   allowed,
+		queueURLs: make(map[string]string),
+	}, nil
+}
+
+func (r *Registry) Queue(ctx context.Context, queueName string) (SQSAPI, error) {
+	queueURL, err := r.queueURL(ctx, queueName)
+	if err != nil {
+		return nil, err
+	}
+	return &BaseSQSAPI{queueURL: &queueURL, client: r.client}, nil
+}
+
+func (r *Registry) queueURL(ctx context.Context, queueName string) (string, error) {
+	if !r.allowed[queueName] {
+		return "", ErrQueueNotAllowed
+	}
+
+	r.mu.RLock()
+	queueURL, ok := r.queueURLs[queueName]
+	r.mu.RUnlock()
+	if ok {
+		return queueURL, nil
+	}
+
+	result, err := r.client.GetQueueUrl(ctx, &sqs.GetQueueUrlInput{
+		QueueName: &queueName,
+	})
+	if err != nil {
+		if isQueueDoesNotExist(err) {
+			return "", ErrQueueNotFound
+		}
+		logger.Error.Printf("get queue url of %s fail, %+v", queueName, err)
+		return "", err
+	}
+
+	r.mu.Lock()
+	r.queueURLs[queueName] = *result.QueueUrl
+	r.mu.Unlock()
+	return *result.QueueUrl, nil
+}
+
+// isQueueDoesNotExist also matches the query protocol code some SQS compatible services
+// still return
+func isQueueDoesNotExist(err error) bool {
+	var notExist *types.QueueDoesNotExist
+	if errors.As(err, &notExist) {
+		return true
+	}
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "AWS.SimpleQueueService.NonExistentQueue"
+}
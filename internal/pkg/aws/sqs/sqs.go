@@ -2,6 +2,10 @@ package sqs
 
 import (
 	"context"
+	"strconv"
+	"time"
+
+	"go-base/internal/pkg/aws/endpoint"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -12,18 +16,55 @@ import (
 type Message struct {
 	Body          string
 	ReceiptHandle string
+	MessageID     string
+	Attributes    map[string]string // message attributes, binary ones are left out
+	ReceiveCount  int               // ApproximateReceiveCount, 1 on the first delivery
+	SentTimestamp time.Time
 }
 
 type SQSAPI interface {
-	SendMessage(ctx context.Context, body string) error
+	// SendMessage returns the MessageId SQS assigned
+	SendMessage(ctx context.Context, message OutgoingMessage) (string, error)
+	// SendMessageBatch sends messages in batches of 10 and at most 256 KB, retrying the
+	// entries that failed. It returns a result for each message, in order.
+	SendMessageBatch(ctx context.Context, messages []OutgoingMessage) []SendResult
 	ReceiveMessage(ctx context.Context, waitTime, visibilityTimeout int32) (hasMessage bool, message Message, err error)
+	// ReceiveMessages returns up to maxMessages (at most 10) messages, none when the wait
+	// time passed without any
+	ReceiveMessages(ctx context.Context, maxMessages, waitTime, visibilityTimeout int32) ([]Message, error)
+	// ChangeMessageVisibility hides a received message for visibilityTimeout seconds from
+	// now, 0 makes it visible again right away
+	ChangeMessageVisibility(ctx context.Context, receiptHandle string, visibilityTimeout int32) error
 	DeleteMessage(ctx context.Context, receiptHandle string) error
+	// DeleteMessageBatch deletes messages in batches of 10 and returns an error for each
+	// receipt handle, in order, nil when it was deleted
+	DeleteMessageBatch(ctx context.Context, receiptHandles []string) []error
+	// PurgeQueue deletes every message in the queue
+	PurgeQueue(ctx context.Context) error
+	// GetQueueAttributes returns the attributes of the queue by name, all of them when no
+	// attributeNames are given
+	GetQueueAttributes(ctx context.Context, attributeNames ...string) (map[string]string, error)
+}
+
+// QueueAPI resolves SQS queues by name
+type QueueAPI interface {
+	// Queue returns a client bound to queueName, ErrQueueNotAllowed when it isn't in the
+	// allow-list and ErrQueueNotFound when it doesn't exist
+	Queue(ctx context.Context, queueName string) (SQSAPI, error)
 }
 
 var (
-	TestSQS SQSAPI
+	instance QueueAPI
 )
 
+func SetInstance(m QueueAPI) {
+	instance = m
+}
+
+func GetInstance() QueueAPI {
+	return instance
+}
+
 const MaxVisibilityTimeout int32 = 43200 - 5
 
 type BaseSQSAPI struct {
@@ -34,19 +75,22 @@ type BaseSQSAPI struct {
 type Config struct {
 	QueueName string
 	Region    string
+	Endpoint  endpoint.Config // LocalStack or another SQS compatible service
 }
 
 func NewBaseManager(setupConfig Config) (BaseSQSAPI, error) {
 	ctx := context.Background()
 	manager := BaseSQSAPI{}
 
-	cfg, err := config.LoadDefaultConfig(context.TODO())
+	cfg, err := config.LoadDefaultConfig(context.TODO(), setupConfig.Endpoint.LoadOptions()...)
 	if err != nil {
 		return manager, err
 	}
 
 	client := sqs.NewFromConfig(cfg, func(o *sqs.Options) {
 		o.Region = setupConfig.Region
+		o.BaseEndpoint = setupConfig.Endpoint.BaseEndpoint()
+		o.EndpointOptions.DisableHTTPS = setupConfig.Endpoint.DisableTLS
 	})
 
 	result, err := client.GetQueueUrl(ctx, &sqs.GetQueueUrlInput{
@@ -63,32 +107,87 @@ func NewBaseManager(setupConfig Config) (BaseSQSAPI, error) {
 	return manager, nil
 }
 
-func (manager *BaseSQSAPI) SendMessage(ctx context.Context, body string) error {
-	_, err := manager.client.SendMessage(ctx, &sqs.SendMessageInput{
-		MessageBody: aws.String(body),
-		QueueUrl:    manager.queueURL,
+func (manager *BaseSQSAPI) SendMessage(ctx context.Context, message OutgoingMessage) (string, error) {
+	if message.size() > MaxBatchSize {
+		return "", ErrMessageTooLarge
+	}
+	output, err := manager.client.SendMessage(ctx, &sqs.SendMessageInput{
+		MessageBody:            aws.String(message.Body),
+		QueueUrl:               manager.queueURL,
+		DelaySeconds:           message.DelaySeconds,
+		MessageAttributes:      messageAttributes(message.Attributes),
+		MessageGroupId:         optionalString(message.MessageGroupID),
+		MessageDeduplicationId: optionalString(message.MessageDeduplicationID),
 	})
-	return err
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(output.MessageId), nil
 }
 
 func (manager *BaseSQSAPI) ReceiveMessage(ctx context.Context, waitTimeSeconds, visibilityTimeout int32) (bool, Message, error) {
+	messages, err := manager.ReceiveMessages(ctx, 1, waitTimeSeconds, visibilityTimeout)
+	if err != nil || len(messages) == 0 {
+		return false, Message{}, err
+	}
+	return true, messages[0], nil
+}
+
+func (manager *BaseSQSAPI) ReceiveMessages(ctx context.Context, maxMessages, waitTimeSeconds, visibilityTimeout int32) ([]Message, error) {
 	msgOutput, err := manager.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
 		MessageAttributeNames: []string{
 			string(types.QueueAttributeNameAll),
 		},
+		MessageSystemAttributeNames: []types.MessageSystemAttributeName{
+			types.MessageSystemAttributeNameApproximateReceiveCount,
+			types.MessageSystemAttributeNameSentTimestamp,
+		},
 		QueueUrl:            manager.queueURL,
-		MaxNumberOfMessages: 1,
+		MaxNumberOfMessages: max(1, min(maxMessages, MaxBatchEntries)),
 		WaitTimeSeconds:     waitTimeSeconds,
 		VisibilityTimeout:   min(visibilityTimeout, MaxVisibilityTimeout),
 	})
 	if err != nil {
-		return false, Message{}, err
+		return nil, err
 	}
-	if len(msgOutput.Messages) == 0 {
-		return false, Message{}, nil
-	} else {
-		return true, Message{Body: *msgOutput.Messages[0].Body, ReceiptHandle: *msgOutput.Messages[0].ReceiptHandle}, nil
+
+	messages := make([]Message, len(msgOutput.Messages))
+	for i, message := range msgOutput.Messages {
+		messages[i] = toMessage(message)
 	}
+	return messages, nil
+}
+
+func toMessage(message types.Message) Message {
+	result := Message{
+		Body:          aws.ToString(message.Body),
+		ReceiptHandle: aws.ToString(message.ReceiptHandle),
+		MessageID:     aws.ToString(message.MessageId),
+	}
+	for name, value := range message.MessageAttributes {
+		if value.StringValue != nil {
+			if result.Attributes == nil {
+				result.Attributes = make(map[string]string, len(message.MessageAttributes))
+			}
+			result.Attributes[name] = *value.StringValue
+		}
+	}
+	if count, err := strconv.Atoi(message.Attributes[string(types.MessageSystemAttributeNameApproximateReceiveCount)]); err == nil {
+		result.ReceiveCount = count
+	}
+	if millis, err := strconv.ParseInt(message.Attributes[string(types.MessageSystemAttributeNameSentTimestamp)], 10, 64); err == nil {
+		result.SentTimestamp = time.UnixMilli(millis)
+	}
+	return result
+}
+
+func (manager *BaseSQSAPI) ChangeMessageVisibility(ctx context.Context, receiptHandle string, visibilityTimeout int32) error {
+	_, err := manager.client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          manager.queueURL,
+		ReceiptHandle:     aws.String(receiptHandle),
+		VisibilityTimeout: max(0, min(visibilityTimeout, MaxVisibilityTimeout)),
+	})
+	return err
 }
 
 func (manager *BaseSQSAPI) DeleteMessage(ctx context.Context, receiptHandle string) error {
@@ -98,3 +197,64 @@ func (manager *BaseSQSAPI) DeleteMessage(ctx context.Context, receiptHandle stri
 	})
 	return err
 }
+
+func (manager *BaseSQSAPI) DeleteMessageBatch(ctx context.Context, receiptHandles []string) []error {
+	errs := make([]error, len(receiptHandles))
+	for start := 0; start < len(receiptHandles); start += MaxBatchEntries {
+		end := min(start+MaxBatchEntries, len(receiptHandles))
+		entries := make([]types.DeleteMessageBatchRequestEntry, 0, end-start)
+		for i := start; i < end; i++ {
+			entries = append(entries, types.DeleteMessageBatchRequestEntry{
+				Id:            aws.String(strconv.Itoa(i)),
+				ReceiptHandle: aws.String(receiptHandles[i]),
+			})
+		}
+
+		output, err := manager.client.DeleteMessageBatch(ctx, &sqs.DeleteMessageBatchInput{
+			QueueUrl: manager.queueURL,
+			Entries:  entries,
+		})
+		if err != nil {
+			for i := start; i < end; i++ {
+				errs[i] = err
+			}
+			continue
+		}
+		for _, entry := range output.Failed {
+			if i, err := strconv.Atoi(aws.ToString(entry.Id)); err == nil && i >= start && i < end {
+				errs[i] = &BatchEntryError{
+					Code:        aws.ToString(entry.Code),
+					Message:     aws.ToString(entry.Message),
+					SenderFault: entry.SenderFault,
+				}
+			}
+		}
+	}
+	return errs
+}
+
+func (manager *BaseSQSAPI) PurgeQueue(ctx context.Context) error {
+	_, err := manager.client.PurgeQueue(ctx, &sqs.PurgeQueueInput{
+		QueueUrl: manager.queueURL,
+	})
+	return err
+}
+
+func (manager *BaseSQSAPI) GetQueueAttributes(ctx context.Context, attributeNames ...string) (map[string]string, error) {
+	names := []types.QueueAttributeName{types.QueueAttributeNameAll}
+	if len(attributeNames) > 0 {
+		names = make([]types.QueueAttributeName, 0, len(attributeNames))
+		for _, name := range attributeNames {
+			names = append(names, types.QueueAttributeName(name))
+		}
+	}
+
+	result, err := manager.client.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl:       manager.queueURL,
+		AttributeNames: names,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.Attributes, nil
+}
@@ -0,0 +1,411 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var _ QueueAPI = (*MockSQSAPI)(nil)
+
+// MockSQSAPI keeps queues in memory. Queues must be in QueueNames to be used and are
+// created on first use, unless they were removed with DeleteQueue. It is safe for
+// concurrent use.
+//
+// Batches enforce the SQS limits, and single messages can be failed with FailMessage.
+type MockSQSAPI struct {
+	QueueNames []string
+	ShouldFail bool
+
+	mu       sync.Mutex
+	queues   map[string]*mockQueue
+	deleted  map[string]bool
+	failures map[string]*mockFailure
+	seq      int
+
+	visibilityErr error
+	emptyReceives int
+}
+
+type mockQueue struct {
+	mock              *MockSQSAPI
+	messages          []mockMessage
+	inFlight          map[string]mockMessage
+	batchSizes        []int
+	deleteBatchSizes  []int
+	visibilityChanges []MockVisibilityChange
+}
+
+// MockVisibilityChange is a ChangeMessageVisibility call the mock received
+type MockVisibilityChange struct {
+	Body              string
+	VisibilityTimeout int32
+}
+
+type mockMessage struct {
+	Message
+	sent OutgoingMessage
+}
+
+type mockFailure struct {
+	err   error
+	times int
+}
+
+func (m *MockSQSAPI) Queue(ctx context.Context, queueName string) (SQSAPI, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	allowed := false
+	for _, name := range m.QueueNames {
+		allowed = allowed || name == queueName
+	}
+	if !allowed {
+		return nil, ErrQueueNotAllowed
+	}
+	if m.deleted[queueName] {
+		return nil, ErrQueueNotFound
+	}
+	return m.queue(queueName), nil
+}
+
+// DeleteQueue removes a queue, later lookups fail with ErrQueueNotFound
+func (m *MockSQSAPI) DeleteQueue(queueName string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.deleted == nil {
+		m.deleted = make(map[string]bool)
+	}
+	m.deleted[queueName] = true
+	delete(m.queues, queueName)
+}
+
+// FailMessage fails the next times sends of a message with body, or every send when
+// times is 0
+func (m *MockSQSAPI) FailMessage(body string, err error, times int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.failures == nil {
+		m.failures = make(map[string]*mockFailure)
+	}
+	m.failures[body] = &mockFailure{err: err, times: times}
+}
+
+// Messages returns the bodies waiting in a queue, oldest first
+func (m *MockSQSAPI) Messages(queueName string) []string {
+	var bodies []string
+	for _, message := range m.SentMessages(queueName) {
+		bodies = append(bodies, message.Body)
+	}
+	return bodies
+}
+
+// SentMessages returns the messages waiting in a queue with their settings, oldest first
+func (m *MockSQSAPI) SentMessages(queueName string) []OutgoingMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var messages []OutgoingMessage
+	if queue, ok := m.queues[queueName]; ok {
+		for _, message := range queue.messages {
+			messages = append(messages, message.sent)
+		}
+	}
+	return messages
+}
+
+// BatchSizes returns the number of entries of every batch request sent to a queue
+func (m *MockSQSAPI) BatchSizes(queueName string) []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if queue, ok := m.queues[queueName]; ok {
+		return append([]int(nil), queue.batchSizes...)
+	}
+	return nil
+}
+
+// failure returns the injected error of a message, if any. The caller holds mu.
+func (m *MockSQSAPI) failure(body string) error {
+	failure, ok := m.failures[body]
+	if !ok {
+		return nil
+	}
+	if failure.times > 0 {
+		if failure.times--; failure.times == 0 {
+			delete(m.failures, body)
+		}
+	}
+	return failure.err
+}
+
+func (m *MockSQSAPI) queue(queueName string) *mockQueue {
+	if m.queues == nil {
+		m.queues = make(map[string]*mockQueue)
+	}
+	queue, ok := m.queues[queueName]
+	if !ok {
+		queue = &mockQueue{mock: m, inFlight: make(map[string]mockMessage)}
+		m.queues[queueName] = queue
+	}
+	return queue
+}
+
+func (q *mockQueue) SendMessage(ctx context.Context, message OutgoingMessage) (string, error) {
+	q.mock.mu.Lock()
+	defer q.mock.mu.Unlock()
+
+	if q.mock.ShouldFail {
+		return "", errors.New("mock error")
+	}
+	if message.size() > MaxBatchSize {
+		return "", ErrMessageTooLarge
+	}
+	if err := q.mock.failure(message.Body); err != nil {
+		return "", err
+	}
+	return q.push(message), nil
+}
+
+func (q *mockQueue) SendMessageBatch(ctx context.Context, messages []OutgoingMessage) []SendResult {
+	return sendMessageBatch(ctx, messages, q.sendBatch)
+}
+
+func (q *mockQueue) sendBatch(ctx context.Context, messages []OutgoingMessage) []SendResult {
+	q.mock.mu.Lock()
+	defer q.mock.mu.Unlock()
+
+	results := make([]SendResult, len(messages))
+	size := 0
+	for _, message := range messages {
+		size += message.size()
+	}
+	var err error
+	switch {
+	case q.mock.ShouldFail:
+		err = errors.New("mock error")
+	case len(messages) > MaxBatchEntries:
+		err = &BatchEntryError{Code: "TooManyEntriesInBatchRequest", SenderFault: true}
+	case size > MaxBatchSize:
+		err = &BatchEntryError{Code: "BatchRequestTooLong", SenderFault: true}
+	}
+	if err != nil {
+		for i := range results {
+			results[i].Err = err
+		}
+		return results
+	}
+
+	q.batchSizes = append(q.batchSizes, len(messages))
+	for i, message := range messages {
+		if err := q.mock.failure(message.Body); err != nil {
+			results[i].Err = err
+			continue
+		}
+		results[i].MessageID = q.push(message)
+	}
+	return results
+}
+
+// push queues a message and returns its MessageId. The caller holds mu.
+func (q *mockQueue) push(message OutgoingMessage) string {
+	q.mock.seq++
+	messageID := fmt.Sprintf("mock-message-%d", q.mock.seq)
+	q.messages = append(q.messages, mockMessage{
+		Message: Message{
+			Body:          message.Body,
+			MessageID:     messageID,
+			Attributes:    message.Attributes,
+			SentTimestamp: time.Now(),
+		},
+		sent: message,
+	})
+	return messageID
+}
+
+func (q *mockQueue) ReceiveMessage(ctx context.Context, waitTime, visibilityTimeout int32) (bool, Message, error) {
+	messages, err := q.ReceiveMessages(ctx, 1, waitTime, visibilityTimeout)
+	if err != nil || len(messages) == 0 {
+		return false, Message{}, err
+	}
+	return true, messages[0], nil
+}
+
+// ReceiveMessages hands out up to maxMessages waiting messages. They stay in flight until
+// deleted or made visible with a visibility timeout of 0, the mock never expires them.
+// Receiving with a visibility timeout of 0 hides them too, like SQS, which applies the
+// queue's default then.
+func (q *mockQueue) ReceiveMessages(ctx context.Context, maxMessages, waitTime, visibilityTimeout int32) ([]Message, error) {
+	q.mock.mu.Lock()
+	defer q.mock.mu.Unlock()
+
+	if q.mock.ShouldFail {
+		return nil, errors.New("mock error")
+	}
+	if q.mock.emptyReceives > 0 {
+		q.mock.emptyReceives--
+		return nil, nil
+	}
+	count := min(int(max(1, min(maxMessages, MaxBatchEntries))), len(q.messages))
+	messages := make([]Message, count)
+	for i := range q.messages[:count] {
+		q.mock.seq++
+		q.messages[i].ReceiveCount++
+		message := q.messages[i]
+		message.ReceiptHandle = fmt.Sprintf("receipt-%d", q.mock.seq)
+		q.inFlight[message.ReceiptHandle] = message
+		messages[i] = message.Message
+	}
+	q.messages = q.messages[count:]
+	return messages, nil
+}
+
+// ChangeMessageVisibility records the change, the message stays in flight unless the
+// visibility timeout is 0
+func (q *mockQueue) ChangeMessageVisibility(ctx context.Context, receiptHandle string, visibilityTimeout int32) error {
+	q.mock.mu.Lock()
+	defer q.mock.mu.Unlock()
+
+	if q.mock.ShouldFail {
+		return errors.New("mock error")
+	}
+	if q.mock.visibilityErr != nil {
+		return q.mock.visibilityErr
+	}
+	message, ok := q.inFlight[receiptHandle]
+	if !ok {
+		return fmt.Errorf("receipt handle %s is invalid", receiptHandle)
+	}
+	q.visibilityChanges = append(q.visibilityChanges, MockVisibilityChange{Body: message.Body, VisibilityTimeout: visibilityTimeout})
+	if visibilityTimeout == 0 {
+		delete(q.inFlight, receiptHandle)
+		q.messages = append(q.messages, message)
+	}
+	return nil
+}
+
+func (q *mockQueue) DeleteMessage(ctx context.Context, receiptHandle string) error {
+	q.mock.mu.Lock()
+	defer q.mock.mu.Unlock()
+
+	if q.mock.ShouldFail {
+		return errors.New("mock error")
+	}
+	delete(q.inFlight, receiptHandle)
+	return nil
+}
+
+func (q *mockQueue) DeleteMessageBatch(ctx context.Context, receiptHandles []string) []error {
+	q.mock.mu.Lock()
+	for start := 0; start < len(receiptHandles); start += MaxBatchEntries {
+		q.deleteBatchSizes = append(q.deleteBatchSizes, min(MaxBatchEntries, len(receiptHandles)-start))
+	}
+	q.mock.mu.Unlock()
+
+	errs := make([]error, len(receiptHandles))
+	for i, receiptHandle := range receiptHandles {
+		errs[i] = q.DeleteMessage(ctx, receiptHandle)
+	}
+	return errs
+}
+
+// InFlight returns the number of messages received from a queue and not deleted yet
+func (m *MockSQSAPI) InFlight(queueName string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if queue, ok := m.queues[queueName]; ok {
+		return len(queue.inFlight)
+	}
+	return 0
+}
+
+// DeleteBatchSizes returns the number of entries of every batch delete request of a queue
+func (m *MockSQSAPI) DeleteBatchSizes(queueName string) []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if queue, ok := m.queues[queueName]; ok {
+		return append([]int(nil), queue.deleteBatchSizes...)
+	}
+	return nil
+}
+
+// EmptyReceives makes the next n receives return no messages, like SQS can when only some
+// of its servers are sampled
+func (m *MockSQSAPI) EmptyReceives(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.emptyReceives = n
+}
+
+// FailVisibilityChanges makes every later ChangeMessageVisibility fail with err, nil
+// restores them
+func (m *MockSQSAPI) FailVisibilityChanges(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.visibilityErr = err
+}
+
+// VisibilityChanges returns the ChangeMessageVisibility calls of a queue, oldest first
+func (m *MockSQSAPI) VisibilityChanges(queueName string) []MockVisibilityChange {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if queue, ok := m.queues[queueName]; ok {
+		return append([]MockVisibilityChange(nil), queue.visibilityChanges...)
+	}
+	return nil
+}
+
+func (q *mockQueue) PurgeQueue(ctx context.Context) error {
+	q.mock.mu.Lock()
+	defer q.mock.mu.Unlock()
+
+	if q.mock.ShouldFail {
+		return errors.New("mock error")
+	}
+	q.messages = nil
+	q.inFlight = make(map[string]mockMessage)
+	return nil
+}
+
+// GetQueueAttributes returns the approximate message counts of the queue
+func (q *mockQueue) GetQueueAttributes(ctx context.Context, attributeNames ...string) (map[string]string, error) {
+	q.mock.mu.Lock()
+	defer q.mock.mu.Unlock()
+
+	if q.mock.ShouldFail {
+		return nil, errors.New("mock error")
+	}
+	attributes := map[string]string{
+		"ApproximateNumberOfMessages":           strconv.Itoa(len(q.messages)),
+		"ApproximateNumberOfMessagesNotVisible": strconv.Itoa(len(q.inFlight)),
+	}
+	if len(attributeNames) == 0 {
+		return attributes, nil
+	}
+	selected := make(map[string]string, len(attributeNames))
+	for _, name := range attributeNames {
+		if value, ok := attributes[name]; ok {
+			selected[name] = value
+		}
+	}
+	return selected, nil
+}
+
+// SendTo queues a message as if another producer had sent it, e.g. to fill a dead-letter
+// queue. The queue doesn't have to be in QueueNames.
+func (m *MockSQSAPI) SendTo(queueName string, message OutgoingMessage) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.queue(queueName).push(message)
+}
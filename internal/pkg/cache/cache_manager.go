@@ -2,7 +2,11 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"net/url"
+	"time"
+
+	"go-base/internal/pkg/health"
 )
 
 var (
@@ -12,18 +16,29 @@ var (
 
 type Manager struct {
 	Driver
+	tracker health.Tracker
 }
 
 type Config struct {
-	Type         string
-	EndpointList []string
-	Password     string
+	Type            string
+	EndpointList    []string
+	Password        string
+	MaxRetries      int           // of a failed command, with backoff between MinRetryBackoff and MaxRetryBackoff, driver default when 0
+	MinRetryBackoff time.Duration // driver default when 0
+	MaxRetryBackoff time.Duration // driver default when 0
 }
 
+var _ health.HealthChecker = (*Manager)(nil)
+
 type Driver interface {
 	SetUp(config Config) error
+	Ping(ctx context.Context) error
 	Get(key string) (string, error)
 	Set(key string, value interface{}) error
+	SetWithTTL(key string, value interface{}, ttl time.Duration) error
+	SetNX(key string, value interface{}, ttl time.Duration) (bool, error)
+	Del(key string) error
+	Close() error
 }
 
 func GetInstance() *Manager {
@@ -45,6 +60,7 @@ func (manager *Manager) Setup(config Config) error {
 
 	err := driver.SetUp(config)
 	manager.Driver = driver
+	manager.tracker.Observe(err)
 
 	return err
 }
@@ -60,3 +76,40 @@ type ThirdPartyLoginInfo struct {
 	Id    string
 	State string
 }
+
+// Close closes the connections of the driver, if it was set up
+func (manager *Manager) Close() error {
+	if manager.Driver == nil {
+		return nil
+	}
+	return manager.Driver.Close()
+}
+
+// Name identifies the cache in health reports
+func (manager *Manager) Name() string {
+	return "redis"
+}
+
+// Ping checks the connection to Redis until ctx is done. The driver reconnects by itself
+// on the next command.
+func (manager *Manager) Ping(ctx context.Context) error {
+	if manager.Driver == nil {
+		return manager.tracker.Observe(errors.New("cache not set up"))
+	}
+	return manager.tracker.Observe(manager.Driver.Ping(ctx))
+}
+
+// State returns the state of the connection as of the last ping
+func (manager *Manager) State() health.State {
+	return manager.tracker.State()
+}
+
+// LastError returns the last error of a ping
+func (manager *Manager) LastError() error {
+	return manager.tracker.LastError()
+}
+
+// LastErrorAt returns when the last ping failed
+func (manager *Manager) LastErrorAt() time.Time {
+	return manager.tracker.LastErrorAt()
+}
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -14,8 +15,11 @@ type DriverRedisCluster struct {
 
 func (d *DriverRedisCluster) SetUp(config Config) error {
 	d.client = redis.NewClusterClient(&redis.ClusterOptions{
-		Addrs:    config.EndpointList,
-		Password: config.Password,
+		Addrs:           config.EndpointList,
+		Password:        config.Password,
+		MaxRetries:      config.MaxRetries,
+		MinRetryBackoff: config.MinRetryBackoff,
+		MaxRetryBackoff: config.MaxRetryBackoff,
 	})
 
 	_, err := d.client.Ping(d.client.Context()).Result()
@@ -47,3 +51,26 @@ func (d *DriverRedisCluster) Get(key string) (data string, err error) {
 func (d *DriverRedisCluster) Set(key string, value interface{}) error {
 	return d.client.Set(ctx, key, value, time.Hour/2).Err()
 }
+
+// SetWithTTL sets key to value for ttl, or without expiry when ttl is 0
+func (d *DriverRedisCluster) SetWithTTL(key string, value interface{}, ttl time.Duration) error {
+	return d.client.Set(ctx, key, value, ttl).Err()
+}
+
+// SetNX sets key to value for ttl unless key exists, and reports whether it was set
+func (d *DriverRedisCluster) SetNX(key string, value interface{}, ttl time.Duration) (bool, error) {
+	return d.client.SetNX(ctx, key, value, ttl).Result()
+}
+
+func (d *DriverRedisCluster) Del(key string) error {
+	return d.client.Del(ctx, key).Err()
+}
+
+// Ping checks the connection until ctx is done
+func (d *DriverRedisCluster) Ping(ctx context.Context) error {
+	return d.client.Ping(ctx).Err()
+}
+
+func (d *DriverRedisCluster) Close() error {
+	return d.client.Close()
+}
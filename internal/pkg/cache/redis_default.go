@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -14,8 +15,11 @@ type DriverRedisDefault struct {
 
 func (d *DriverRedisDefault) SetUp(config Config) error {
 	d.client = redis.NewClient(&redis.Options{
-		Addr:     config.EndpointList[0],
-		Password: config.Password,
+		Addr:            config.EndpointList[0],
+		Password:        config.Password,
+		MaxRetries:      config.MaxRetries,
+		MinRetryBackoff: config.MinRetryBackoff,
+		MaxRetryBackoff: config.MaxRetryBackoff,
 	})
 
 	_, err := d.client.Ping(d.client.Context()).Result()
@@ -47,3 +51,26 @@ func (d *DriverRedisDefault) Get(key string) (data string, err error) {
 func (d *DriverRedisDefault) Set(key string, value interface{}) error {
 	return d.client.Set(ctx, key, value, time.Hour/2).Err()
 }
+
+// SetWithTTL sets key to value for ttl, or without expiry when ttl is 0
+func (d *DriverRedisDefault) SetWithTTL(key string, value interface{}, ttl time.Duration) error {
+	return d.client.Set(ctx, key, value, ttl).Err()
+}
+
+// SetNX sets key to value for ttl unless key exists, and reports whether it was set
+func (d *DriverRedisDefault) SetNX(key string, value interface{}, ttl time.Duration) (bool, error) {
+	return d.client.SetNX(ctx, key, value, ttl).Result()
+}
+
+func (d *DriverRedisDefault) Del(key string) error {
+	return d.client.Del(ctx, key).Err()
+}
+
+// Ping checks the connection until ctx is done
+func (d *DriverRedisDefault) Ping(ctx context.Context) error {
+	return d.client.Ping(ctx).Err()
+}
+
+func (d *DriverRedisDefault) Close() error {
+	return d.client.Close()
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
+
+	"go-base/internal/pkg/aws/endpoint"
 
 	"github.com/caarlos0/env/v6"
 )
@@ -59,12 +62,41 @@ type EnvVariable struct {
 	//PostgresMaxConnSize             int32    `env:"POSTGRES_MAX_CONN_SIZE" envDefault:"64"`
 	//PostgresMaxConnIdleTimeBySecond int64    `env:"POSTGRES_CONN_IDLE_TIME_BY_SECOND" envDefault:"1"`
 	//PostgresMaxConnLifeTimeBySecond int64    `env:"POSTGRES_CONN_LIFE_TIME_BY_SECOND" envDefault:"60"`
-	VendorServiceHost   string `env:"VENDOR_SERVICE_HOST,required"`
-	AWSS3Bucket         string `env:"AWS_S3_BUCKET,required"`
-	AWSS3Region         string `env:"AWS_S3_REGION" envDefault:"us-west-2"`
-	IsEnabledAccelerate bool   `env:"AWS_S3_ACCELERATE" envDefault:"false"`
-	AWSSQSRegion        string `env:"AWS_SQS_REGION" envDefault:"us-west-2"`
-	AWSSQSQueueName     string `env:"AWS_SQS_QUEUE_NAME" envDefault:"default-queue"`
+	VendorServiceHost             string        `env:"VENDOR_SERVICE_HOST,required"`
+	AWSS3Bucket                   string        `env:"AWS_S3_BUCKET,required"`
+	AWSS3Buckets                  []string      `env:"AWS_S3_BUCKETS" envSeparator:","` // name=bucket pairs, e.g. icons=my-icons,exports=my-exports
+	AWSS3Region                   string        `env:"AWS_S3_REGION" envDefault:"us-west-2"`
+	IsEnabledAccelerate           bool          `env:"AWS_S3_ACCELERATE" envDefault:"false"`
+	AWSS3MultipartCleanupInterval time.Duration `env:"AWS_S3_MULTIPART_CLEANUP_INTERVAL" envDefault:"1h"`
+	AWSS3MultipartUploadMaxAge    time.Duration `env:"AWS_S3_MULTIPART_UPLOAD_MAX_AGE" envDefault:"24h"`
+	AWSS3Endpoint                 string        `env:"AWS_S3_ENDPOINT"` // S3 compatible service, e.g. MinIO, empty uses AWS
+	AWSS3UsePathStyle             bool          `env:"AWS_S3_USE_PATH_STYLE" envDefault:"false"`
+	AWSS3AccessKeyID              string        `env:"AWS_S3_ACCESS_KEY_ID"` // static credentials, empty uses the default credential chain
+	AWSS3SecretAccessKey          string        `env:"AWS_S3_SECRET_ACCESS_KEY"`
+	AWSS3DisableTLS               bool          `env:"AWS_S3_DISABLE_TLS" envDefault:"false"`
+	AWSSQSRegion                  string        `env:"AWS_SQS_REGION" envDefault:"us-west-2"`
+	AWSSQSQueueName               string        `env:"AWS_SQS_QUEUE_NAME" envDefault:"default-queue"`
+	AWSSQSQueueNames              []string      `env:"AWS_SQS_QUEUE_NAMES" envSeparator:","`             // other queues /sqs may send to, besides AWS_SQS_QUEUE_NAME
+	AWSSQSWorkerConcurrency       int           `env:"AWS_SQS_WORKER_CONCURRENCY" envDefault:"4"`        // messages of AWS_SQS_QUEUE_NAME processed at the same time
+	AWSSQSIconQueueName           string        `env:"AWS_SQS_ICON_QUEUE_NAME"`                          // S3 ObjectCreated events of the icon bucket, empty disables processing
+	AWSSQSIconDeadLetterQueueName string        `env:"AWS_SQS_ICON_DEAD_LETTER_QUEUE_NAME"`              // icon events that keep failing, empty keeps redelivering them
+	AWSSQSDeadLetterQueueNames    []string      `env:"AWS_SQS_DEAD_LETTER_QUEUE_NAMES" envSeparator:","` // other dead-letter queues /sqs/dlq may manage
+	AWSSQSEndpoint                string        `env:"AWS_SQS_ENDPOINT"`                                 // SQS compatible service, e.g. LocalStack, empty uses AWS
+	AWSSQSAccessKeyID             string        `env:"AWS_SQS_ACCESS_KEY_ID"`                            // static credentials, empty uses the default credential chain
+	AWSSQSSecretAccessKey         string        `env:"AWS_SQS_SECRET_ACCESS_KEY"`
+	AWSSQSDisableTLS              bool          `env:"AWS_SQS_DISABLE_TLS" envDefault:"false"`
+	IconVariantSizes              []int         `env:"ICON_VARIANT_SIZES" envSeparator:"," envDefault:"32,64,128,256"`
+	IconMaxDimension              int           `env:"ICON_MAX_DIMENSION" envDefault:"4096"`
+	IconMaxFileSize               int64         `env:"ICON_MAX_FILE_SIZE" envDefault:"10485760"`
+	IconWorkerCount               int           `env:"ICON_WORKER_COUNT" envDefault:"2"`                             // pollers of AWS_SQS_ICON_QUEUE_NAME
+	IconWorkerConcurrency         int           `env:"ICON_WORKER_CONCURRENCY" envDefault:"4"`                       // icons processed at the same time
+	IconMaxReceiveCount           int           `env:"ICON_MAX_RECEIVE_COUNT" envDefault:"5"`                        // deliveries before an icon event is dead-lettered
+	NatsUrl                       string        `env:"NATS_URL"`                                                     // empty disables NATS
+	NatsStreams                   []string      `env:"NATS_STREAMS" envSeparator:","`                                // name=subject|subject pairs of the JetStream streams to provision, e.g. EVENTS=events.>
+	ShutdownTimeout               time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"30s"`                            // to drain requests and messages on SIGINT/SIGTERM
+	ReadinessTimeout              time.Duration `env:"READINESS_TIMEOUT" envDefault:"2s"`                            // of every dependency check of /readyz
+	ReadinessCacheTTL             time.Duration `env:"READINESS_CACHE_TTL" envDefault:"5s"`                          // how long /readyz reuses its last checks
+	ReadinessNonCritical          []string      `env:"READINESS_NON_CRITICAL" envSeparator:"," envDefault:"s3,auth"` // checks that only degrade /readyz when they fail
 }
 
 func (env EnvVariable) Validate() (err error) {
@@ -81,10 +113,134 @@ func (env EnvVariable) Validate() (err error) {
 		err = errors.New("required environment variable \"DEPLOY_ENVIRONMENT\" should be \"DEVELOP|STAGE|PRODUCTION\"")
 		return
 	}
+	for _, pair := range env.AWSS3Buckets {
+		if name, bucket, ok := strings.Cut(pair, "="); !ok || strings.TrimSpace(name) == "" || strings.TrimSpace(bucket) == "" {
+			err = errors.New("environment variable \"AWS_S3_BUCKETS\" should be a list of \"name=bucket\"")
+			return
+		}
+	}
+	if (env.AWSS3AccessKeyID == "") != (env.AWSS3SecretAccessKey == "") {
+		err = errors.New("environment variables \"AWS_S3_ACCESS_KEY_ID\" and \"AWS_S3_SECRET_ACCESS_KEY\" should be set together")
+		return
+	}
+	if (env.AWSSQSAccessKeyID == "") != (env.AWSSQSSecretAccessKey == "") {
+		err = errors.New("environment variables \"AWS_SQS_ACCESS_KEY_ID\" and \"AWS_SQS_SECRET_ACCESS_KEY\" should be set together")
+		return
+	}
+	if env.IconWorkerCount <= 0 {
+		err = errors.New("environment variable \"ICON_WORKER_COUNT\" should be positive")
+		return
+	}
+	if env.IconMaxReceiveCount <= 0 {
+		err = errors.New("environment variable \"ICON_MAX_RECEIVE_COUNT\" should be positive")
+		return
+	}
+	for _, pair := range env.NatsStreams {
+		if name, subjects, ok := strings.Cut(pair, "="); !ok || strings.TrimSpace(name) == "" || strings.TrimSpace(subjects) == "" {
+			err = errors.New("environment variable \"NATS_STREAMS\" should be a list of \"name=subject|subject\"")
+			return
+		}
+	}
+	for _, size := range env.IconVariantSizes {
+		if size <= 0 {
+			err = errors.New("environment variable \"ICON_VARIANT_SIZES\" should be a list of positive sizes")
+			return
+		}
+	}
 
 	return
 }
 
+// S3Buckets returns AWS_S3_BUCKETS as a map from bucket name to real bucket
+func (env EnvVariable) S3Buckets() map[string]string {
+	buckets := make(map[string]string, len(env.AWSS3Buckets))
+	for _, pair := range env.AWSS3Buckets {
+		if name, bucket, ok := strings.Cut(pair, "="); ok {
+			buckets[strings.TrimSpace(name)] = strings.TrimSpace(bucket)
+		}
+	}
+	return buckets
+}
+
+// NatsStreamSubjects returns NATS_STREAMS as a map from stream name to its subjects
+func (env EnvVariable) NatsStreamSubjects() map[string][]string {
+	streams := make(map[string][]string, len(env.NatsStreams))
+	for _, pair := range env.NatsStreams {
+		name, subjects, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		name = strings.TrimSpace(name)
+		for _, subject := range strings.Split(subjects, "|") {
+			if subject = strings.TrimSpace(subject); subject != "" {
+				streams[name] = append(streams[name], subject)
+			}
+		}
+	}
+	return streams
+}
+
+// IsCriticalCheck returns whether /readyz fails when the dependency check name fails,
+// i.e. it's not in READINESS_NON_CRITICAL
+func (env EnvVariable) IsCriticalCheck(name string) bool {
+	for _, nonCritical := range env.ReadinessNonCritical {
+		if strings.TrimSpace(nonCritical) == name {
+			return false
+		}
+	}
+	return true
+}
+
+// SQSSendQueueNames returns the queues /sqs may send to, AWS_SQS_QUEUE_NAME first
+func (env EnvVariable) SQSSendQueueNames() []string {
+	return uniqueNames(append([]string{env.AWSSQSQueueName}, env.AWSSQSQueueNames...))
+}
+
+// SQSQueueNames returns every queue the service may resolve: the send queues, and the icon
+// queue and dead-letter queues, which only the workers and the dead-letter redrive use
+func (env EnvVariable) SQSQueueNames() []string {
+	names := append(env.SQSSendQueueNames(), env.AWSSQSIconQueueName)
+	return uniqueNames(append(names, env.SQSDeadLetterQueueNames()...))
+}
+
+// SQSDeadLetterQueueNames returns the dead-letter queues /sqs/dlq may manage
+func (env EnvVariable) SQSDeadLetterQueueNames() []string {
+	return uniqueNames(append([]string{env.AWSSQSIconDeadLetterQueueName}, env.AWSSQSDeadLetterQueueNames...))
+}
+
+// uniqueNames trims names and drops the empty and repeated ones, keeping their order
+func uniqueNames(names []string) []string {
+	var unique []string
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" && !seen[name] {
+			seen[name] = true
+			unique = append(unique, name)
+		}
+	}
+	return unique
+}
+
+// S3Endpoint returns the endpoint and credentials of the S3 client
+func (env EnvVariable) S3Endpoint() endpoint.Config {
+	return endpoint.Config{
+		URL:             env.AWSS3Endpoint,
+		AccessKeyID:     env.AWSS3AccessKeyID,
+		SecretAccessKey: env.AWSS3SecretAccessKey,
+		DisableTLS:      env.AWSS3DisableTLS,
+	}
+}
+
+// SQSEndpoint returns the endpoint and credentials of the SQS clients
+func (env EnvVariable) SQSEndpoint() endpoint.Config {
+	return endpoint.Config{
+		URL:             env.AWSSQSEndpoint,
+		AccessKeyID:     env.AWSSQSAccessKeyID,
+		SecretAccessKey: env.AWSSQSSecretAccessKey,
+		DisableTLS:      env.AWSSQSDisableTLS,
+	}
+}
+
 func IsProduction() bool {
 	return strings.ToLower(Env.DeployEnvironment) == deployEnvProduction
 }
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-base/internal/pkg/logger"
+	model "go-base/internal/pkg/model/db"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// UpsertIcon replaces the record of icon.Bucket/icon.Key
+func UpsertIcon(icon model.Icon) (err error) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"bucket": icon.Bucket, "key": icon.Key}
+	_, err = iconCollection.ReplaceOne(ctx, filter, icon, options.Replace().SetUpsert(true))
+	if err != nil {
+		logger.Error.Printf("[UpsertIcon] ReplaceOne Failed: %v", err)
+		return fmt.Errorf("[UpsertIcon] %s", err.Error())
+	}
+
+	return
+}
+
+// GetIcon returns ERROR_DATA_NOT_FOUND when the icon has not been processed
+func GetIcon(bucket string, key string) (icon model.Icon, err error) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	err = iconCollection.FindOne(ctx, bson.M{"bucket": bucket, "key": key}).Decode(&icon)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.Icon{}, ERROR_DATA_NOT_FOUND
+	}
+	if err != nil {
+		logger.Error.Printf("[GetIcon] FindOne Failed: %v", err)
+		return model.Icon{}, fmt.Errorf("[GetIcon] %s", err.Error())
+	}
+
+	return
+}
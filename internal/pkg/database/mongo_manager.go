@@ -5,16 +5,25 @@ import (
 	"errors"
 	"time"
 
+	"go-base/internal/pkg/health"
+	"go-base/internal/pkg/logger"
+
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
 )
 
+var mongoClient *mongo.Client
 var todoCollection *mongo.Collection
+var iconCollection *mongo.Collection
 
 var ERROR_DATA_NOT_FOUND = errors.New("data not found")
 
+// tracker follows the heartbeats the driver sends to MongoDB in the background
+var tracker health.Tracker
+
 func Setup(uri string) (err error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -25,19 +34,32 @@ func Setup(uri string) (err error) {
 	}
 
 	databaseName := cs.Database
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri).SetServerMonitor(&event.ServerMonitor{
+		ServerHeartbeatSucceeded: func(*event.ServerHeartbeatSucceededEvent) {
+			tracker.Set(health.StateUp, nil)
+		},
+		// the driver keeps sending heartbeats and reconnects when one succeeds
+		ServerHeartbeatFailed: func(e *event.ServerHeartbeatFailedEvent) {
+			if tracker.State() != health.StateReconnecting {
+				logger.Warn.Printf("mongo heartbeat to %s failed, reconnecting: %v", e.ConnectionID, e.Failure)
+			}
+			tracker.Set(health.StateReconnecting, e.Failure)
+		},
+	}))
 
 	if err != nil {
 		return
 	}
 
-	err = client.Ping(ctx, readpref.Primary())
+	err = tracker.Observe(client.Ping(ctx, readpref.Primary()))
 
 	if err != nil {
 		return
 	}
 
+	mongoClient = client
 	todoCollection = client.Database(databaseName).Collection("validation")
+	iconCollection = client.Database(databaseName).Collection("icons")
 
 	return
 }
@@ -46,5 +68,50 @@ func Drop() (err error) {
 	if err = todoCollection.Drop(context.TODO()); err != nil {
 		return
 	}
+	if err = iconCollection.Drop(context.TODO()); err != nil {
+		return
+	}
 	return
 }
+
+// Close disconnects from MongoDB, waiting for the operations in progress until ctx is done
+func Close(ctx context.Context) error {
+	if mongoClient == nil {
+		return nil
+	}
+	return mongoClient.Disconnect(ctx)
+}
+
+// HealthChecker reports the health of the connection to MongoDB
+func HealthChecker() health.HealthChecker {
+	return mongoHealthChecker{}
+}
+
+type mongoHealthChecker struct{}
+
+func (mongoHealthChecker) Name() string {
+	return "mongo"
+}
+
+// Ping checks the connection to the primary until ctx is done
+func (mongoHealthChecker) Ping(ctx context.Context) error {
+	if mongoClient == nil {
+		return tracker.Observe(errors.New("database not set up"))
+	}
+	return tracker.Observe(mongoClient.Ping(ctx, readpref.Primary()))
+}
+
+// State returns the state of the connection as of the last ping or heartbeat
+func (mongoHealthChecker) State() health.State {
+	return tracker.State()
+}
+
+// LastError returns the last error of a ping or heartbeat
+func (mongoHealthChecker) LastError() error {
+	return tracker.LastError()
+}
+
+// LastErrorAt returns when the last ping or heartbeat failed
+func (mongoHealthChecker) LastErrorAt() time.Time {
+	return tracker.LastErrorAt()
+}
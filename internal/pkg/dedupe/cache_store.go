@@ -0,0 +1,62 @@
+package dedupe
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	valueInProgress = "in-progress"
+	valueDone       = "done"
+)
+
+// Cache is the part of cache.Manager CacheStore uses
+type Cache interface {
+	Get(key string) (string, error)
+	SetWithTTL(key string, value interface{}, ttl time.Duration) error
+	SetNX(key string, value interface{}, ttl time.Duration) (bool, error)
+	Del(key string) error
+}
+
+// CacheStore is a Store in Redis, shared by every instance of the service. An id is kept
+// under Prefix+id, "in-progress" for InProgressTTL once claimed and "done" for DoneTTL.
+type CacheStore struct {
+	cache Cache
+	cfg   Config
+}
+
+var _ Store = (*CacheStore)(nil)
+
+// NewCacheStore creates a new Store on top of cache, usually cache.GetInstance()
+func NewCacheStore(cache Cache, cfg Config) *CacheStore {
+	return &CacheStore{cache: cache, cfg: cfg.withDefaults()}
+}
+
+func (s *CacheStore) Claim(ctx context.Context, id string) (Status, error) {
+	key := s.cfg.Prefix + id
+	claimed, err := s.cache.SetNX(key, valueInProgress, s.cfg.InProgressTTL)
+	if err != nil {
+		return InProgress, err
+	}
+	if claimed {
+		return Claimed, nil
+	}
+
+	value, err := s.cache.Get(key)
+	if err != nil {
+		return InProgress, err
+	}
+	if value == valueDone {
+		return Done, nil
+	}
+	// Also when the claim expired in between, the next delivery claims it
+	return InProgress, nil
+}
+
+func (s *CacheStore) Complete(ctx context.Context, id string) error {
+	return s.cache.SetWithTTL(s.cfg.Prefix+id, valueDone, s.cfg.DoneTTL)
+}
+
+func (s *CacheStore) Release(ctx context.Context, id string) error {
+	return s.cache.Del(s.cfg.Prefix + id)
+}
@@ -0,0 +1,88 @@
+package dedupe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-base/internal/pkg/logger"
+)
+
+// ErrInProgress is returned by Once while another consumer handles the same id
+var ErrInProgress = errors.New("already in progress")
+
+// Status is the state of an id in a Store
+type Status int
+
+const (
+	// Claimed means the caller claimed the id and should handle it
+	Claimed Status = iota
+	// InProgress means another consumer claimed the id and didn't finish yet
+	InProgress
+	// Done means the id was already handled
+	Done
+)
+
+// Store remembers the ids being handled and the ones that were, so messages delivered
+// more than once are handled once
+type Store interface {
+	// Claim marks id in progress unless it already is or is done, atomically
+	Claim(ctx context.Context, id string) (Status, error)
+	// Complete marks a claimed id done
+	Complete(ctx context.Context, id string) error
+	// Release forgets a claimed id, so it can be claimed again
+	Release(ctx context.Context, id string) error
+}
+
+// Config holds how long a Store remembers ids
+type Config struct {
+	Prefix        string        // of the keys, "processed:" by default
+	InProgressTTL time.Duration // longer than handling an id takes, 5 minutes by default
+	DoneTTL       time.Duration // longer than redeliveries may happen, 24 hours by default
+}
+
+func (cfg Config) withDefaults() Config {
+	if cfg.Prefix == "" {
+		cfg.Prefix = "processed:"
+	}
+	if cfg.InProgressTTL <= 0 {
+		cfg.InProgressTTL = 5 * time.Minute
+	}
+	if cfg.DoneTTL <= 0 {
+		cfg.DoneTTL = 24 * time.Hour
+	}
+	return cfg
+}
+
+// Once calls fn unless id was already handled, and returns ErrInProgress without calling
+// it while another consumer handles id. id is marked done when fn succeeds and released
+// when it fails, so a redelivery tries again. When the store fails fn is called anyway,
+// handling an id twice is better than never.
+func Once(ctx context.Context, store Store, id string, fn func(ctx context.Context) error) error {
+	status, err := store.Claim(ctx, id)
+	if err != nil {
+		logger.Warn.Printf("dedupe failed to claim %s, handling it anyway: %v", id, err)
+		return fn(ctx)
+	}
+	switch status {
+	case Done:
+		logger.Info.Printf("dedupe skipped %s, already handled", id)
+		return nil
+	case InProgress:
+		return fmt.Errorf("%w: %s", ErrInProgress, id)
+	}
+
+	// Settle the claim even when ctx is cancelled, or it blocks id until it expires
+	settleCtx := context.WithoutCancel(ctx)
+	if err := fn(ctx); err != nil {
+		if releaseErr := store.Release(settleCtx, id); releaseErr != nil {
+			logger.Warn.Printf("dedupe failed to release %s: %v", id, releaseErr)
+		}
+		return err
+	}
+	if err := store.Complete(settleCtx, id); err != nil {
+		logger.Warn.Printf("dedupe failed to mark %s done: %v", id, err)
+	}
+	return nil
+}
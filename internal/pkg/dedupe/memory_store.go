@@ -0,0 +1,67 @@
+package dedupe
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a Store in memory. It only deduplicates the messages handled by the same
+// process, e.g. in tests or when a single instance consumes a queue.
+type MemoryStore struct {
+	cfg       Config
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+	mu        sync.Mutex
+}
+
+// memorySweepInterval is how often Claim drops the expired entries, instead of on every call
+const memorySweepInterval = time.Minute
+
+type memoryEntry struct {
+	status Status
+	expiry time.Time
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore creates a new in-memory Store, Prefix is ignored
+func NewMemoryStore(cfg Config) *MemoryStore {
+	return &MemoryStore{cfg: cfg.withDefaults(), entries: make(map[string]memoryEntry)}
+}
+
+func (s *MemoryStore) Claim(ctx context.Context, id string) (Status, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) >= memorySweepInterval {
+		for entryID, entry := range s.entries {
+			if !now.Before(entry.expiry) {
+				delete(s.entries, entryID)
+			}
+		}
+		s.lastSweep = now
+	}
+	if entry, ok := s.entries[id]; ok && now.Before(entry.expiry) {
+		return entry.status, nil
+	}
+	s.entries[id] = memoryEntry{status: InProgress, expiry: now.Add(s.cfg.InProgressTTL)}
+	return Claimed, nil
+}
+
+func (s *MemoryStore) Complete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[id] = memoryEntry{status: Done, expiry: time.Now().Add(s.cfg.DoneTTL)}
+	return nil
+}
+
+func (s *MemoryStore) Release(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, id)
+	return nil
+}
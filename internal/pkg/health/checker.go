@@ -0,0 +1,53 @@
+package health
+
+import (
+	"context"
+	"time"
+)
+
+var _ HealthChecker = (*FuncChecker)(nil)
+
+// FuncChecker checks a dependency without a connection of its own, e.g. an AWS service,
+// with a round trip function. Its state is the result of the last ping.
+type FuncChecker struct {
+	name    string
+	ping    func(ctx context.Context) error
+	tracker Tracker
+}
+
+// NewChecker creates a checker named name that pings with ping
+func NewChecker(name string, ping func(ctx context.Context) error) *FuncChecker {
+	return &FuncChecker{name: name, ping: ping}
+}
+
+func (c *FuncChecker) Name() string {
+	return c.name
+}
+
+// Ping calls the ping function, and returns ctx.Err() when ctx is done before it returns,
+// for functions that don't take ctx into account
+func (c *FuncChecker) Ping(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- c.ping(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return c.tracker.Observe(err)
+	case <-ctx.Done():
+		return c.tracker.Observe(ctx.Err())
+	}
+}
+
+func (c *FuncChecker) State() State {
+	return c.tracker.State()
+}
+
+func (c *FuncChecker) LastError() error {
+	return c.tracker.LastError()
+}
+
+func (c *FuncChecker) LastErrorAt() time.Time {
+	return c.tracker.LastErrorAt()
+}
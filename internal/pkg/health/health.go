@@ -0,0 +1,92 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// State is the state of a connection
+type State string
+
+const (
+	// StateUnknown means the connection wasn't checked yet
+	StateUnknown State = "unknown"
+	// StateUp means the connection works
+	StateUp State = "up"
+	// StateReconnecting means the connection was lost and the driver is reconnecting
+	StateReconnecting State = "reconnecting"
+	// StateDown means the connection doesn't work
+	StateDown State = "down"
+)
+
+// HealthChecker reports the health of a connection, e.g. of a manager to a database
+type HealthChecker interface {
+	// Name identifies the connection in reports, e.g. "mongo"
+	Name() string
+	// Ping checks the connection with a round trip, until ctx is done
+	Ping(ctx context.Context) error
+	// State returns the state of the connection as last seen, without a round trip
+	State() State
+	// LastError returns the last error of the connection, nil when there was none
+	LastError() error
+}
+
+// Tracker keeps the state and last error of a connection, from pings and driver events.
+// The zero value is ready to use, in StateUnknown.
+type Tracker struct {
+	state       State
+	lastErr     error
+	lastErrorAt time.Time
+	mu          sync.Mutex
+}
+
+// Observe records the result of a ping, up when err is nil and down otherwise, and
+// returns err
+func (t *Tracker) Observe(err error) error {
+	if err != nil {
+		t.Set(StateDown, err)
+	} else {
+		t.Set(StateUp, nil)
+	}
+	return err
+}
+
+// Set records state, and err as the last error when it's not nil
+func (t *Tracker) Set(state State, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.state = state
+	if err != nil {
+		t.lastErr = err
+		t.lastErrorAt = time.Now()
+	}
+}
+
+// State returns the recorded state
+func (t *Tracker) State() State {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.state == "" {
+		return StateUnknown
+	}
+	return t.state
+}
+
+// LastError returns the last recorded error
+func (t *Tracker) LastError() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.lastErr
+}
+
+// LastErrorAt returns when the last error was recorded
+func (t *Tracker) LastErrorAt() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.lastErrorAt
+}
@@ -0,0 +1,190 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const defaultTimeout = 2 * time.Second
+
+var (
+	instance *Registry
+)
+
+// GetInstance returns the registry the managers are registered in at startup
+func GetInstance() *Registry {
+	if instance == nil {
+		instance = NewRegistry(RegistryConfig{})
+	}
+	return instance
+}
+
+// SetInstance replaces the registry returned by GetInstance, e.g. with one configured at
+// startup
+func SetInstance(r *Registry) {
+	instance = r
+}
+
+// Recorder receives the result of every check, e.g. to export it as metrics
+type Recorder interface {
+	RecordCheck(name string, state State, latency time.Duration, err error)
+}
+
+// Result is the health of one connection
+type Result struct {
+	Name        string     `json:"name"`
+	Critical    bool       `json:"critical"` // the report isn't healthy when it's not up
+	State       State      `json:"state"`
+	LatencyMs   int64      `json:"latency_ms"`
+	Error       string     `json:"error,omitempty"`         // of the ping
+	LastError   string     `json:"last_error,omitempty"`    // of the connection, e.g. a disconnection since recovered
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"` // when LastError happened, if the checker knows
+}
+
+// Report is the health of every registered connection
+type Report struct {
+	Healthy   bool      `json:"healthy"`  // every critical connection is up
+	Degraded  bool      `json:"degraded"` // a non-critical connection isn't up
+	CheckedAt time.Time `json:"checked_at"`
+	Checks    []Result  `json:"checks"` // in registration order
+}
+
+// CheckOptions holds how a checker is checked
+type CheckOptions struct {
+	Critical bool          // the report isn't healthy when it's not up, otherwise only degraded
+	Timeout  time.Duration // of the ping, the timeout of the registry by default
+}
+
+type entry struct {
+	checker HealthChecker
+	options CheckOptions
+}
+
+// Registry aggregates the HealthCheckers of the managers
+type Registry struct {
+	entries  []entry
+	timeout  time.Duration
+	cacheTTL time.Duration
+	recorder Recorder
+	mu       sync.RWMutex
+
+	cacheMu sync.Mutex
+	cached  *Report
+}
+
+// RegistryConfig holds configuration for Registry
+type RegistryConfig struct {
+	Timeout  time.Duration // of every ping, 2 seconds by default
+	CacheTTL time.Duration // how long Check returns the last report, 0 checks every time
+	Recorder Recorder      // optional
+}
+
+// NewRegistry creates a new registry
+func NewRegistry(cfg RegistryConfig) *Registry {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	return &Registry{
+		timeout:  cfg.Timeout,
+		cacheTTL: cfg.CacheTTL,
+		recorder: cfg.Recorder,
+	}
+}
+
+// Register adds critical checkers to the registry
+func (r *Registry) Register(checkers ...HealthChecker) {
+	for _, checker := range checkers {
+		r.RegisterCheck(checker, CheckOptions{Critical: true})
+	}
+}
+
+// RegisterCheck adds a checker to the registry with options
+func (r *Registry) RegisterCheck(checker HealthChecker, options CheckOptions) {
+	if options.Timeout <= 0 {
+		options.Timeout = r.timeout
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = append(r.entries, entry{checker: checker, options: options})
+}
+
+// Check pings every connection at the same time, each until its timeout or ctx is done.
+// Within the cache TTL it returns the last report instead, and concurrent calls share
+// the pings, so probes don't hit the dependencies on every request.
+func (r *Registry) Check(ctx context.Context) Report {
+	if r.cacheTTL <= 0 {
+		return r.checkAll(ctx)
+	}
+
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
+
+	if r.cached != nil && time.Since(r.cached.CheckedAt) < r.cacheTTL {
+		return *r.cached
+	}
+	// a caller going away must not leave a cancelled report in the cache
+	report := r.checkAll(context.WithoutCancel(ctx))
+	r.cached = &report
+	return report
+}
+
+func (r *Registry) checkAll(ctx context.Context) Report {
+	r.mu.RLock()
+	entries := r.entries
+	r.mu.RUnlock()
+
+	report := Report{Healthy: true, CheckedAt: time.Now(), Checks: make([]Result, len(entries))}
+	var wg sync.WaitGroup
+	for i, e := range entries {
+		wg.Add(1)
+		go func(i int, e entry) {
+			defer wg.Done()
+			report.Checks[i] = r.check(ctx, e)
+		}(i, e)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.State == StateUp {
+			continue
+		}
+		if result.Critical {
+			report.Healthy = false
+		} else {
+			report.Degraded = true
+		}
+	}
+	return report
+}
+
+// check pings a connection and reports its state, down when the ping fails
+func (r *Registry) check(ctx context.Context, e entry) Result {
+	ctx, cancel := context.WithTimeout(ctx, e.options.Timeout)
+	defer cancel()
+
+	checker := e.checker
+	start := time.Now()
+	err := checker.Ping(ctx)
+	latency := time.Since(start)
+
+	result := Result{Name: checker.Name(), Critical: e.options.Critical, State: checker.State(), LatencyMs: latency.Milliseconds()}
+	if err != nil {
+		result.State = StateDown
+		result.Error = err.Error()
+	}
+	if lastErr := checker.LastError(); lastErr != nil {
+		result.LastError = lastErr.Error()
+	}
+	if t, ok := checker.(interface{ LastErrorAt() time.Time }); ok && result.LastError != "" {
+		lastErrorAt := t.LastErrorAt()
+		result.LastErrorAt = &lastErrorAt
+	}
+
+	if r.recorder != nil {
+		r.recorder.RecordCheck(result.Name, result.State, latency, err)
+	}
+	return result
+}
@@ -0,0 +1,170 @@
+package icon
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+// Supported source formats. Variants are always PNG, there is no WebP encoder in the
+// standard library or golang.org/x/image.
+const (
+	ContentTypePNG  = "image/png"
+	ContentTypeJPEG = "image/jpeg"
+	ContentTypeGIF  = "image/gif"
+	ContentTypeWebP = "image/webp"
+)
+
+// RejectError means the upload is not an acceptable icon. Retrying won't help.
+type RejectError struct {
+	Reason string
+}
+
+func (e *RejectError) Error() string {
+	return "icon rejected: " + e.Reason
+}
+
+// Options controls what Process accepts and produces
+type Options struct {
+	MaxDimension int   // widest or tallest side allowed, in pixels
+	Sizes        []int // variant bounding boxes, e.g. 32, 64, 128, 256
+}
+
+// Result is a processed icon
+type Result struct {
+	ContentType string // sniffed from the bytes, not taken from the upload
+	Width       int
+	Height      int
+	Sanitized   []byte // the original re-encoded without EXIF or other metadata
+	Variants    []Variant
+}
+
+// Variant is the icon scaled to fit a Size x Size box, as PNG
+type Variant struct {
+	Size   int
+	Width  int
+	Height int
+	Data   []byte
+}
+
+// Process sniffs, validates and decodes data, then re-encodes it without metadata and
+// renders one variant per size. Sizes larger than the icon itself are skipped.
+func Process(data []byte, options Options) (*Result, error) {
+	contentType := http.DetectContentType(data)
+	decodeConfig, decode, ok := decoders(contentType)
+	if !ok {
+		return nil, &RejectError{Reason: fmt.Sprintf("unsupported content type %s", contentType)}
+	}
+
+	// Check the header first so oversized images are never decoded into memory
+	config, err := decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, &RejectError{Reason: fmt.Sprintf("invalid %s: %v", contentType, err)}
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, &RejectError{Reason: "empty image"}
+	}
+	if options.MaxDimension > 0 && (config.Width > options.MaxDimension || config.Height > options.MaxDimension) {
+		return nil, &RejectError{Reason: fmt.Sprintf("%dx%d exceeds the %dpx limit", config.Width, config.Height, options.MaxDimension)}
+	}
+
+	img, err := decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, &RejectError{Reason: fmt.Sprintf("invalid %s: %v", contentType, err)}
+	}
+
+	sanitized, err := encode(img, contentType)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{
+		ContentType: contentType,
+		Width:       config.Width,
+		Height:      config.Height,
+		Sanitized:   sanitized,
+	}
+
+	for _, size := range options.Sizes {
+		if size <= 0 || (size > config.Width && size > config.Height) {
+			continue
+		}
+		width, height := fit(config.Width, config.Height, size)
+		scaled := image.NewNRGBA(image.Rect(0, 0, width, height))
+		draw.CatmullRom.Scale(scaled, scaled.Bounds(), img, img.Bounds(), draw.Src, nil)
+
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, scaled); err != nil {
+			return nil, err
+		}
+		result.Variants = append(result.Variants, Variant{Size: size, Width: width, Height: height, Data: buf.Bytes()})
+	}
+	return result, nil
+}
+
+func decoders(contentType string) (func(r *bytes.Reader) (image.Config, error), func(r *bytes.Reader) (image.Image, error), bool) {
+	switch contentType {
+	case ContentTypePNG:
+		return func(r *bytes.Reader) (image.Config, error) { return png.DecodeConfig(r) },
+			func(r *bytes.Reader) (image.Image, error) { return png.Decode(r) }, true
+	case ContentTypeJPEG:
+		return func(r *bytes.Reader) (image.Config, error) { return jpeg.DecodeConfig(r) },
+			func(r *bytes.Reader) (image.Image, error) { return jpeg.Decode(r) }, true
+	case ContentTypeGIF:
+		return func(r *bytes.Reader) (image.Config, error) { return gif.DecodeConfig(r) },
+			func(r *bytes.Reader) (image.Image, error) { return gif.Decode(r) }, true
+	case ContentTypeWebP:
+		return func(r *bytes.Reader) (image.Config, error) { return webp.DecodeConfig(r) },
+			func(r *bytes.Reader) (image.Image, error) { return webp.Decode(r) }, true
+	}
+	return nil, nil, false
+}
+
+// encode writes img back in its own format. Decoding dropped every metadata chunk, EXIF
+// included. WebP can't be encoded, so it comes back as PNG.
+func encode(img image.Image, contentType string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch contentType {
+	case ContentTypeJPEG:
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95})
+	case ContentTypeGIF:
+		err = gif.Encode(&buf, img, nil)
+	default:
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fit scales width x height down to fit a size x size box, keeping the aspect ratio
+func fit(width int, height int, size int) (int, int) {
+	if width >= height {
+		scaledHeight := height * size / width
+		if scaledHeight < 1 {
+			scaledHeight = 1
+		}
+		return size, scaledHeight
+	}
+	scaledWidth := width * size / height
+	if scaledWidth < 1 {
+		scaledWidth = 1
+	}
+	return scaledWidth, size
+}
+
+// SanitizedContentType is the content type of Result.Sanitized for a source content type
+func SanitizedContentType(contentType string) string {
+	if contentType == ContentTypeWebP {
+		return ContentTypePNG
+	}
+	return contentType
+}
@@ -0,0 +1,158 @@
+package job
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-base/internal/pkg/logger"
+	"go-base/internal/pkg/util"
+)
+
+type Status string
+
+const (
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// retention is how long finished jobs stay queryable
+const retention = 24 * time.Hour
+
+// Job is a snapshot of a background operation
+type Job struct {
+	ID         string
+	Type       string
+	Status     Status
+	Total      int64 // 0 while the amount of work isn't known yet
+	Processed  int64
+	Failed     int64
+	Result     interface{}
+	Error      string
+	CreatedAt  time.Time
+	FinishedAt time.Time
+}
+
+// Func is the work of a job. Its result is stored on the job once it returns.
+type Func func(ctx context.Context, progress *Progress) (interface{}, error)
+
+// Progress lets a running job report how far it got. Updates to a nil Progress are dropped,
+// so the same code can run inside and outside a job.
+type Progress struct {
+	entry *entry
+}
+
+type entry struct {
+	mu   sync.Mutex
+	job  Job
+	done chan struct{}
+}
+
+var (
+	jobsMu sync.Mutex
+	jobs   = map[string]*entry{}
+)
+
+// Start runs fn in the background and returns the job tracking it. The job outlives the
+// request that started it, so fn gets a context of its own.
+func Start(jobType string, fn Func) Job {
+	e := &entry{
+		job: Job{
+			ID:        util.GenUUID(),
+			Type:      jobType,
+			Status:    StatusRunning,
+			CreatedAt: time.Now(),
+		},
+		done: make(chan struct{}),
+	}
+
+	jobsMu.Lock()
+	prune(time.Now())
+	jobs[e.job.ID] = e
+	jobsMu.Unlock()
+
+	logger.Info.Printf("Job %s (%s) started", e.job.ID, jobType)
+	go func() {
+		defer close(e.done)
+		result, err := fn(context.Background(), &Progress{entry: e})
+
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		e.job.Result = result
+		e.job.FinishedAt = time.Now()
+		if err != nil {
+			e.job.Status = StatusFailed
+			e.job.Error = err.Error()
+			logger.Error.Printf("Job %s (%s) failed: %v", e.job.ID, jobType, err)
+			return
+		}
+		e.job.Status = StatusSucceeded
+		logger.Info.Printf("Job %s (%s) finished, processed=%d, failed=%d", e.job.ID, jobType, e.job.Processed, e.job.Failed)
+	}()
+
+	return e.snapshot()
+}
+
+// Get returns the current state of a job
+func Get(id string) (Job, bool) {
+	jobsMu.Lock()
+	e, ok := jobs[id]
+	jobsMu.Unlock()
+	if !ok {
+		return Job{}, false
+	}
+	return e.snapshot(), true
+}
+
+// Wait blocks until the job finishes or ctx is done, and returns its latest state
+func Wait(ctx context.Context, id string) (Job, bool) {
+	jobsMu.Lock()
+	e, ok := jobs[id]
+	jobsMu.Unlock()
+	if !ok {
+		return Job{}, false
+	}
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+	}
+	return e.snapshot(), true
+}
+
+// AddTotal grows the amount of work the job expects to do
+func (p *Progress) AddTotal(n int) {
+	if p == nil {
+		return
+	}
+	p.entry.mu.Lock()
+	defer p.entry.mu.Unlock()
+	p.entry.job.Total += int64(n)
+}
+
+// Add records processed items, failed ones included
+func (p *Progress) Add(processed int, failed int) {
+	if p == nil {
+		return
+	}
+	p.entry.mu.Lock()
+	defer p.entry.mu.Unlock()
+	p.entry.job.Processed += int64(processed)
+	p.entry.job.Failed += int64(failed)
+}
+
+func (e *entry) snapshot() Job {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.job
+}
+
+// prune drops jobs that finished more than retention ago, must be called with jobsMu held
+func prune(now time.Time) {
+	for id, e := range jobs {
+		job := e.snapshot()
+		if job.Status != StatusRunning && now.Sub(job.FinishedAt) > retention {
+			delete(jobs, id)
+		}
+	}
+}
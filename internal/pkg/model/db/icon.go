@@ -0,0 +1,29 @@
+package database
+
+const (
+	IconStatusReady    = "ready"
+	IconStatusRejected = "rejected"
+)
+
+// Icon is the processing record of an uploaded icon
+type Icon struct {
+	Bucket      string        `bson:"bucket" json:"bucket"`
+	Key         string        `bson:"key" json:"key"`
+	Status      string        `bson:"status" json:"status"`
+	Reason      string        `bson:"reason,omitempty" json:"reason,omitempty"`
+	ContentType string        `bson:"content_type" json:"content_type"`
+	Width       int           `bson:"width" json:"width"`
+	Height      int           `bson:"height" json:"height"`
+	Variants    []IconVariant `bson:"variants" json:"variants"`
+	ProcessedAt int64         `bson:"processed_at" json:"processed_at"`
+}
+
+// IconVariant is one resized copy of an icon
+type IconVariant struct {
+	Size   int    `bson:"size" json:"size"`
+	Key    string `bson:"key" json:"key"`
+	Format string `bson:"format" json:"format"`
+	Width  int    `bson:"width" json:"width"`
+	Height int    `bson:"height" json:"height"`
+	Bytes  int64  `bson:"bytes" json:"bytes"`
+}
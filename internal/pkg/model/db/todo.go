@@ -8,4 +8,13 @@ type Todo struct {
 	Completed   bool               `bson:"completed" json:"completed"`
 	CreatedAt   int64          	   `bson:"created_at" json:"created_at"`
 	UpdatedAt   int64              `bson:"updated_at" json:"updated_at"`
+	Attachments []TodoAttachment   `bson:"attachments,omitempty" json:"attachments,omitempty"`
+}
+
+// TodoAttachment is an S3 object attached to a todo, with the SHA-256 it had when attached
+type TodoAttachment struct {
+	BucketName     string `bson:"bucket_name" json:"bucket_name"`
+	ObjectKey      string `bson:"object_key" json:"object_key"`
+	ChecksumSHA256 string `bson:"checksum_sha256" json:"checksum_sha256"`
+	Size           int64  `bson:"size" json:"size"`
 }
\ No newline at end of file
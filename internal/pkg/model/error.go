@@ -22,6 +22,7 @@ type serviceError struct {
 	BadRequestError       func(string) ServiceResp
 	ForbiddenError        func(string) ServiceResp
 	NotFoundError         ServiceResp
+	RangeNotSatisfiable   func(string) ServiceResp
 	FailedDependencyError func(string) ServiceResp
 	InternalServiceError  func(string) ServiceResp
 }
@@ -51,6 +52,9 @@ var ServiceError = serviceError{
 	NotFoundError: ServiceResp{
 		http.StatusNotFound, ServiceErrCode{http.StatusText(http.StatusNotFound)},
 	},
+	RangeNotSatisfiable: func(code string) ServiceResp {
+		return ServiceResp{http.StatusRequestedRangeNotSatisfiable, ServiceErrCode{code}}
+	},
 	FailedDependencyError: func(code string) ServiceResp {
 		return ServiceResp{http.StatusFailedDependency, ServiceErrCode{code}}
 	},
@@ -78,3 +82,50 @@ const ExternalDeleteVendorFail = "2015"
 
 // HTTP
 const HttpMethodInvalid = "3001"
+
+// S3
+const S3GetObjectFail = "4001"
+const S3ObjectNotModified = "4002"
+const S3InvalidRange = "4003"
+const S3CreateMultipartUploadFail = "4004"
+const S3PresignUploadPartFail = "4005"
+const S3ListPartsFail = "4006"
+const S3CompleteMultipartUploadFail = "4007"
+const S3AbortMultipartUploadFail = "4008"
+const S3MultipartUploadNoParts = "4009"
+const S3PresignPostFail = "4010"
+const S3PresignPostInvalidPolicy = "4011"
+const S3GetObjectTaggingFail = "4012"
+const S3PutObjectTaggingFail = "4013"
+const S3DeleteObjectTaggingFail = "4014"
+const S3ReplaceObjectMetadataFail = "4015"
+const S3ListObjectVersionsFail = "4016"
+const S3DeleteObjectVersionFail = "4017"
+const S3RestoreObjectVersionFail = "4018"
+const S3GetBucketVersioningFail = "4019"
+const S3PutBucketVersioningFail = "4020"
+const S3GetBucketLifecycleFail = "4021"
+const S3PutBucketLifecycleFail = "4022"
+const S3InvalidLifecycleConfiguration = "4023"
+const S3GetBucketCORSFail = "4024"
+const S3PutBucketCORSFail = "4025"
+const S3InvalidCORSConfiguration = "4026"
+const S3GetPublicAccessBlockFail = "4027"
+const S3PutPublicAccessBlockFail = "4028"
+const S3DeleteObjectsFail = "4029"
+const S3DeleteObjectsByPrefixFail = "4030"
+const S3InvalidTransfer = "4031"
+const S3TransferObjectsFail = "4032"
+const S3ChecksumMismatch = "4033"
+const S3InvalidChecksum = "4034"
+const S3VerifyObjectFail = "4035"
+
+// SQS
+const SQSQueueNotAllowed = "5001"
+const SQSQueueNotFound = "5002"
+const SQSSendMessageFail = "5003"
+const SQSMessageTooLarge = "5004"
+const SQSNotDeadLetterQueue = "5005"
+const SQSReceiveMessagesFail = "5006"
+const SQSRedriveMessagesFail = "5007"
+const SQSPurgeQueueFail = "5008"
@@ -0,0 +1,95 @@
+package model
+
+// LifecycleRule is one bucket lifecycle rule, a rule needs at least one action
+type LifecycleRule struct {
+	ID                                 string                `json:"id" binding:"required,max=255"`
+	Prefix                             string                `json:"prefix"`
+	Status                             string                `json:"status" binding:"required,oneof=Enabled Disabled"`
+	ExpirationDays                     int32                 `json:"expiration_days" binding:"min=0"`
+	NoncurrentVersionExpirationDays    int32                 `json:"noncurrent_version_expiration_days" binding:"min=0"`
+	AbortIncompleteMultipartUploadDays int32                 `json:"abort_incomplete_multipart_upload_days" binding:"min=0"`
+	Transitions                        []LifecycleTransition `json:"transitions" binding:"omitempty,max=6,dive"`
+}
+
+type LifecycleTransition struct {
+	Days         int32  `json:"days" binding:"min=0"`
+	StorageClass string `json:"storage_class" binding:"required,oneof=STANDARD_IA ONEZONE_IA INTELLIGENT_TIERING GLACIER_IR GLACIER DEEP_ARCHIVE"`
+}
+
+// GetBucketLifecycle Request and Response
+type GetBucketLifecycleRequest struct {
+	BucketName string `json:"bucket_name" form:"bucket_name" binding:"required"`
+}
+
+type GetBucketLifecycleResponse struct {
+	Rules []LifecycleRule `json:"rules"`
+}
+
+// PutBucketLifecycle Request and Response, an empty rule list removes the configuration
+type PutBucketLifecycleRequest struct {
+	BucketName string          `json:"bucket_name" binding:"required"`
+	Rules      []LifecycleRule `json:"rules" binding:"max=1000,dive"`
+}
+
+type PutBucketLifecycleResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// CORSRule is one bucket CORS rule
+type CORSRule struct {
+	ID             string   `json:"id" binding:"max=255"`
+	AllowedOrigins []string `json:"allowed_origins" binding:"required,min=1,dive,required"`
+	AllowedMethods []string `json:"allowed_methods" binding:"required,min=1,dive,oneof=GET PUT POST DELETE HEAD"`
+	AllowedHeaders []string `json:"allowed_headers" binding:"omitempty,dive,required"`
+	ExposeHeaders  []string `json:"expose_headers" binding:"omitempty,dive,required"`
+	MaxAgeSeconds  int32    `json:"max_age_seconds" binding:"min=0"`
+}
+
+// GetBucketCORS Request and Response
+type GetBucketCORSRequest struct {
+	BucketName string `json:"bucket_name" form:"bucket_name" binding:"required"`
+}
+
+type GetBucketCORSResponse struct {
+	Rules []CORSRule `json:"rules"`
+}
+
+// PutBucketCORS Request and Response, an empty rule list removes the configuration
+type PutBucketCORSRequest struct {
+	BucketName string     `json:"bucket_name" binding:"required"`
+	Rules      []CORSRule `json:"rules" binding:"max=100,dive"`
+}
+
+type PutBucketCORSResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// PublicAccessBlock settings of a bucket
+type PublicAccessBlock struct {
+	BlockPublicAcls       bool `json:"block_public_acls"`
+	IgnorePublicAcls      bool `json:"ignore_public_acls"`
+	BlockPublicPolicy     bool `json:"block_public_policy"`
+	RestrictPublicBuckets bool `json:"restrict_public_buckets"`
+}
+
+// GetPublicAccessBlock Request and Response
+type GetPublicAccessBlockRequest struct {
+	BucketName string `json:"bucket_name" form:"bucket_name" binding:"required"`
+}
+
+type GetPublicAccessBlockResponse struct {
+	PublicAccessBlock
+}
+
+// PutPublicAccessBlock Request and Response
+type PutPublicAccessBlockRequest struct {
+	BucketName string `json:"bucket_name" binding:"required"`
+	PublicAccessBlock
+}
+
+type PutPublicAccessBlockResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
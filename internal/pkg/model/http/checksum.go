@@ -0,0 +1,16 @@
+package model
+
+// Verify Object Request and Response
+type VerifyObjectRequest struct {
+	BucketName     string `json:"bucket_name" form:"bucket_name" binding:"required"`
+	ObjectKey      string `json:"object_key" form:"object_key" binding:"required"`
+	VersionID      string `json:"version_id" form:"version_id"`           // defaults to the latest version
+	ChecksumSHA256 string `json:"checksum_sha256" form:"checksum_sha256"` // base64, optional checksum the caller expects
+}
+
+type VerifyObjectResponse struct {
+	Valid                bool   `json:"valid"`                  // the recomputed hash matches every checksum there is to compare
+	ChecksumSHA256       string `json:"checksum_sha256"`        // recomputed from the object body
+	StoredChecksumSHA256 string `json:"stored_checksum_sha256"` // what S3 stored at upload, empty if none
+	Size                 int64  `json:"size"`
+}
@@ -0,0 +1,62 @@
+package model
+
+// ListDeadLetterMessagesRequest represents the query for inspecting a dead-letter queue
+type ListDeadLetterMessagesRequest struct {
+	QueueName   string `json:"queue_name" form:"queue_name" binding:"required" example:"icon-dlq"`
+	MaxMessages int32  `json:"max_messages" form:"max_messages" binding:"omitempty,min=1,max=10" example:"10"` // defaults to 10
+}
+
+// ListDeadLetterMessagesResponse represents the response for inspecting a dead-letter queue
+type ListDeadLetterMessagesResponse struct {
+	Messages []DeadLetterMessage `json:"messages"`
+}
+
+// DeadLetterMessage is a message of a dead-letter queue with the failure that moved it
+type DeadLetterMessage struct {
+	MessageID       string            `json:"message_id" example:"12345-67890-abcdef"`
+	Body            string            `json:"body" example:"Hello World"`
+	Attributes      map[string]string `json:"attributes,omitempty"` // without the failure metadata
+	SourceQueueName string            `json:"source_queue_name,omitempty" example:"icon-queue"`
+	Error           string            `json:"error,omitempty" example:"processing failed"`
+	ReceiveCount    int               `json:"receive_count" example:"1"` // deliveries from the dead-letter queue
+	FailedAt        string            `json:"failed_at,omitempty" example:"2024-01-01T00:00:00Z"`
+	SentTimestamp   string            `json:"sent_timestamp" example:"2024-01-01T00:00:00Z"`
+}
+
+// RedriveDeadLetterMessagesRequest represents the request body for sending messages of a
+// dead-letter queue back to the queue they failed in. Without filters every message is
+// redriven, with several filters a message has to match all of them.
+type RedriveDeadLetterMessagesRequest struct {
+	QueueName       string            `json:"queue_name" binding:"required" example:"icon-dlq"`
+	SourceQueueName string            `json:"source_queue_name,omitempty" example:"icon-queue"` // overrides the queue the messages failed in
+	MessageIDs      []string          `json:"message_ids,omitempty"`
+	Attributes      map[string]string `json:"attributes,omitempty" example:"event:todo.created"` // attributes the messages must have
+	BodyContains    string            `json:"body_contains,omitempty" example:"icons/"`
+	MaxMessages     int               `json:"max_messages" binding:"omitempty,min=1" example:"100"` // messages to inspect, defaults to 1000
+}
+
+// RedriveDeadLetterMessagesResponse represents the response for redriving a dead-letter queue
+type RedriveDeadLetterMessagesResponse struct {
+	Redriven int                    `json:"redriven" example:"2"`
+	Skipped  int                    `json:"skipped" example:"1"` // messages that didn't match the filters
+	Failed   []RedriveMessageResult `json:"failed,omitempty"`
+	// Remaining is the approximate number of messages left in the dead-letter queue without
+	// being inspected, e.g. past max_messages. Absent when SQS didn't report it.
+	Remaining *int `json:"remaining,omitempty" example:"0"`
+}
+
+// RedriveMessageResult is a message that couldn't be redriven, it stays in the dead-letter queue
+type RedriveMessageResult struct {
+	MessageID string `json:"message_id" example:"12345-67890-abcdef"`
+	Error     string `json:"error" example:"unknown source queue"`
+}
+
+// PurgeDeadLetterQueueRequest represents the query for deleting every message of a dead-letter queue
+type PurgeDeadLetterQueueRequest struct {
+	QueueName string `json:"queue_name" form:"queue_name" binding:"required" example:"icon-dlq"`
+}
+
+// PurgeDeadLetterQueueResponse represents the response for purging a dead-letter queue
+type PurgeDeadLetterQueueResponse struct {
+	Success bool `json:"success" example:"true"`
+}
@@ -0,0 +1,15 @@
+package model
+
+// DeleteObjectsByPrefix Request and Response. A prefix holding more than 1000 objects is
+// deleted by a background job and the response only carries its job_id.
+type DeleteObjectsByPrefixRequest struct {
+	BucketName string `json:"bucket_name" binding:"required"`
+	Prefix     string `json:"prefix" binding:"required"`
+}
+
+type DeleteObjectsByPrefixResponse struct {
+	JobID        string              `json:"job_id,omitempty"`
+	Success      bool                `json:"success"`
+	DeletedCount int                 `json:"deleted_count"`
+	Errors       []DeleteObjectError `json:"errors,omitempty"`
+}
@@ -0,0 +1,19 @@
+package model
+
+// GetJob Request and Response
+type GetJobRequest struct {
+	JobID string `uri:"job_id" binding:"required"`
+}
+
+type GetJobResponse struct {
+	JobID      string      `json:"job_id"`
+	Type       string      `json:"type"`
+	Status     string      `json:"status"` // running, succeeded or failed
+	Total      int64       `json:"total"`  // 0 while unknown
+	Processed  int64       `json:"processed"`
+	Failed     int64       `json:"failed"`
+	Result     interface{} `json:"result,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	CreatedAt  string      `json:"created_at"`
+	FinishedAt string      `json:"finished_at,omitempty"`
+}
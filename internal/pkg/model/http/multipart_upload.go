@@ -0,0 +1,82 @@
+package model
+
+// CreateMultipartUpload Request and Response
+type CreateMultipartUploadRequest struct {
+	BucketName  string `json:"bucket_name" binding:"required"`
+	ObjectKey   string `json:"object_key" binding:"required"`
+	ContentType string `json:"content_type"`
+	ObjectOptions
+}
+
+type CreateMultipartUploadResponse struct {
+	BucketName string `json:"bucket_name"`
+	ObjectKey  string `json:"object_key"`
+	UploadID   string `json:"upload_id"`
+}
+
+// PresignUploadParts Request and Response
+type PresignUploadPartsRequest struct {
+	BucketName  string  `json:"bucket_name" binding:"required"`
+	ObjectKey   string  `json:"object_key" binding:"required"`
+	UploadID    string  `json:"upload_id" binding:"required"`
+	PartNumbers []int32 `json:"part_numbers" binding:"required,min=1,max=1000,dive,min=1,max=10000"`
+}
+
+type PresignUploadPartsResponse struct {
+	Parts     []PresignedPart `json:"parts"`
+	ExpiresAt string          `json:"expires_at"`
+}
+
+type PresignedPart struct {
+	PartNumber   int32  `json:"part_number"`
+	PresignedURL string `json:"presigned_url"`
+}
+
+// ListParts Request and Response
+type ListPartsRequest struct {
+	BucketName string `json:"bucket_name" form:"bucket_name" binding:"required"`
+	ObjectKey  string `json:"object_key" form:"object_key" binding:"required"`
+	UploadID   string `json:"upload_id" form:"upload_id" binding:"required"`
+}
+
+type ListPartsResponse struct {
+	Parts []UploadedPart `json:"parts"`
+}
+
+type UploadedPart struct {
+	PartNumber   int32  `json:"part_number"`
+	ETag         string `json:"etag"`
+	Size         int64  `json:"size"`
+	LastModified string `json:"last_modified"`
+}
+
+// CompleteMultipartUpload Request and Response
+type CompleteMultipartUploadRequest struct {
+	BucketName string          `json:"bucket_name" binding:"required"`
+	ObjectKey  string          `json:"object_key" binding:"required"`
+	UploadID   string          `json:"upload_id" binding:"required"`
+	Parts      []CompletedPart `json:"parts" binding:"dive"` // optional, defaults to every part S3 has received
+}
+
+type CompletedPart struct {
+	PartNumber int32  `json:"part_number" binding:"required,min=1,max=10000"`
+	ETag       string `json:"etag" binding:"required"`
+}
+
+type CompleteMultipartUploadResponse struct {
+	Success   bool   `json:"success"`
+	PartCount int    `json:"part_count"`
+	Message   string `json:"message"`
+}
+
+// AbortMultipartUpload Request and Response
+type AbortMultipartUploadRequest struct {
+	BucketName string `json:"bucket_name" binding:"required"`
+	ObjectKey  string `json:"object_key" binding:"required"`
+	UploadID   string `json:"upload_id" binding:"required"`
+}
+
+type AbortMultipartUploadResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
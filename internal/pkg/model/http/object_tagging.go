@@ -0,0 +1,50 @@
+package model
+
+// GetObjectTagging Request and Response
+type GetObjectTaggingRequest struct {
+	BucketName string `json:"bucket_name" form:"bucket_name" binding:"required"`
+	ObjectKey  string `json:"object_key" form:"object_key" binding:"required"`
+	VersionID  string `json:"version_id" form:"version_id"` // defaults to the latest version
+}
+
+type GetObjectTaggingResponse struct {
+	Tags map[string]string `json:"tags"`
+}
+
+// PutObjectTagging Request and Response, the tags replace the whole tag set
+type PutObjectTaggingRequest struct {
+	BucketName string            `json:"bucket_name" binding:"required"`
+	ObjectKey  string            `json:"object_key" binding:"required"`
+	VersionID  string            `json:"version_id"`
+	Tags       map[string]string `json:"tags" binding:"required,max=10,dive,keys,min=1,max=128,endkeys,max=256"`
+}
+
+type PutObjectTaggingResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// DeleteObjectTagging Request and Response
+type DeleteObjectTaggingRequest struct {
+	BucketName string `json:"bucket_name" binding:"required"`
+	ObjectKey  string `json:"object_key" binding:"required"`
+	VersionID  string `json:"version_id"`
+}
+
+type DeleteObjectTaggingResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// ReplaceObjectMetadata Request and Response
+type ReplaceObjectMetadataRequest struct {
+	BucketName  string            `json:"bucket_name" binding:"required"`
+	ObjectKey   string            `json:"object_key" binding:"required"`
+	ContentType string            `json:"content_type"` // defaults to the current content type
+	Metadata    map[string]string `json:"metadata" binding:"required"`
+}
+
+type ReplaceObjectMetadataResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,73 @@
+package model
+
+// ListObjectVersions Request and Response
+type ListObjectVersionsRequest struct {
+	BucketName      string `json:"bucket_name" form:"bucket_name" binding:"required"`
+	Prefix          string `json:"prefix" form:"prefix"`
+	KeyMarker       string `json:"key_marker" form:"key_marker"`
+	VersionIDMarker string `json:"version_id_marker" form:"version_id_marker"`
+	MaxKeys         int32  `json:"max_keys" form:"max_keys" binding:"omitempty,min=1,max=1000"`
+}
+
+type ListObjectVersionsResponse struct {
+	Versions            []ObjectVersionInfo `json:"versions"`
+	NextKeyMarker       string              `json:"next_key_marker,omitempty"`
+	NextVersionIDMarker string              `json:"next_version_id_marker,omitempty"`
+	IsTruncated         bool                `json:"is_truncated"`
+}
+
+type ObjectVersionInfo struct {
+	Key            string `json:"key"`
+	VersionID      string `json:"version_id"`
+	IsLatest       bool   `json:"is_latest"`
+	IsDeleteMarker bool   `json:"is_delete_marker"`
+	Size           int64  `json:"size"`
+	ETag           string `json:"etag,omitempty"`
+	StorageClass   string `json:"storage_class,omitempty"`
+	LastModified   string `json:"last_modified"`
+}
+
+// DeleteObjectVersion Request and Response
+type DeleteObjectVersionRequest struct {
+	BucketName string `json:"bucket_name" binding:"required"`
+	ObjectKey  string `json:"object_key" binding:"required"`
+	VersionID  string `json:"version_id" binding:"required"`
+}
+
+type DeleteObjectVersionResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// RestoreObjectVersion Request and Response
+type RestoreObjectVersionRequest struct {
+	BucketName string `json:"bucket_name" binding:"required"`
+	ObjectKey  string `json:"object_key" binding:"required"`
+	VersionID  string `json:"version_id" binding:"required"`
+}
+
+type RestoreObjectVersionResponse struct {
+	Success   bool   `json:"success"`
+	VersionID string `json:"version_id"` // the new latest version
+	Message   string `json:"message"`
+}
+
+// GetBucketVersioning Request and Response
+type GetBucketVersioningRequest struct {
+	BucketName string `json:"bucket_name" form:"bucket_name" binding:"required"`
+}
+
+type GetBucketVersioningResponse struct {
+	Status string `json:"status"` // Enabled, Suspended, or empty if versioning was never enabled
+}
+
+// PutBucketVersioning Request and Response
+type PutBucketVersioningRequest struct {
+	BucketName string `json:"bucket_name" binding:"required"`
+	Status     string `json:"status" binding:"required,oneof=Enabled Suspended"`
+}
+
+type PutBucketVersioningResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
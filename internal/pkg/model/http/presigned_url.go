@@ -1,18 +1,50 @@
 package model
 
+// ObjectOptions are the optional settings applied to objects written to S3
+type ObjectOptions struct {
+	StorageClass string            `json:"storage_class" form:"storage_class" binding:"omitempty,oneof=STANDARD REDUCED_REDUNDANCY STANDARD_IA ONEZONE_IA INTELLIGENT_TIERING GLACIER DEEP_ARCHIVE GLACIER_IR"`
+	ACL          string            `json:"acl" form:"acl" binding:"omitempty,oneof=private public-read public-read-write authenticated-read aws-exec-read bucket-owner-read bucket-owner-full-control"`
+	SSEKMSKeyID  string            `json:"sse_kms_key_id" form:"sse_kms_key_id"`
+	Metadata     map[string]string `json:"metadata" form:"-"`
+}
+
 type GetIconPresignedURLRequest struct {
+	BucketName  string `json:"bucket_name" form:"bucket_name"` // defaults to the icons bucket
 	Key         string `json:"key" form:"key" binding:"required"`
 	Method      string `json:"method" form:"method" binding:"required"`
 	ContentType string `json:"content_type" form:"content_type"`
+	// PUT only, base64 SHA-256 signed into the URL, the upload must send it as x-amz-checksum-sha256
+	ChecksumSHA256 string `json:"checksum_sha256" form:"checksum_sha256"`
+	ObjectOptions
 }
 
 type GetIconPresignedURLResponse struct {
 	PresignedURL string `json:"presigned_url"`
 }
 
+// Presigned POST Request and Response
+type GetPresignedPostRequest struct {
+	BucketName        string            `json:"bucket_name" binding:"required"`
+	Key               string            `json:"key"`        // exact key, or
+	KeyPrefix         string            `json:"key_prefix"` // any key under this prefix (the form's key field may use ${filename})
+	ContentType       string            `json:"content_type"`
+	ContentTypePrefix string            `json:"content_type_prefix"`
+	MinSize           int64             `json:"min_size" binding:"min=0"`
+	MaxSize           int64             `json:"max_size" binding:"required,min=1"`
+	ExpiresIn         int64             `json:"expires_in" binding:"omitempty,min=1"` // seconds, at most and by default the presigned URL expiry
+	Metadata          map[string]string `json:"metadata"`
+}
+
+type GetPresignedPostResponse struct {
+	URL       string            `json:"url"`
+	Fields    map[string]string `json:"fields"`
+	ExpiresAt string            `json:"expires_at"`
+}
+
 // Head Object Request and Response
 type GetIconHeadObjectRequest struct {
-	Key string `json:"key" form:"key" binding:"required"`
+	BucketName string `json:"bucket_name" form:"bucket_name"` // defaults to the icons bucket
+	Key        string `json:"key" form:"key" binding:"required"`
 }
 
 type GetIconHeadObjectResponse struct {
@@ -22,25 +54,42 @@ type GetIconHeadObjectResponse struct {
 	LastModified  string            `json:"last_modified,omitempty"`
 	ETag          string            `json:"etag,omitempty"`
 	Metadata      map[string]string `json:"metadata,omitempty"`
+	// Set once the icon processor has handled the object: "ready" or "rejected"
+	ProcessingStatus string        `json:"processing_status,omitempty"`
+	Reason           string        `json:"reason,omitempty"` // why the icon was rejected
+	Variants         []IconVariant `json:"variants,omitempty"`
+}
+
+// IconVariant is a resized copy of an icon made by the icon processor
+type IconVariant struct {
+	Size          int    `json:"size"`
+	Key           string `json:"key"`
+	Format        string `json:"format"`
+	Width         int    `json:"width"`
+	Height        int    `json:"height"`
+	ContentLength int64  `json:"content_length"`
 }
 
 // Check Object Exists Request and Response
 type GetIconCheckObjectExistsRequest struct {
-	Key string `json:"key" form:"key" binding:"required"`
+	BucketName string `json:"bucket_name" form:"bucket_name"` // defaults to the icons bucket
+	Key        string `json:"key" form:"key" binding:"required"`
 }
 
 type GetIconCheckObjectExistsResponse struct {
 	Exists bool `json:"exists"`
 }
 
-// Delete Objects Request and Response
+// Delete Objects Request and Response, keys are deleted in batches of 1000
 type GetIconDeleteObjectsRequest struct {
-	Keys []string `json:"keys" form:"keys" binding:"required"`
+	BucketName string   `json:"bucket_name"` // defaults to the icons bucket
+	Keys       []string `json:"keys" binding:"required,min=1,max=10000,dive,required"`
 }
 
 type GetIconDeleteObjectsResponse struct {
-	Success      bool                `json:"success"`
+	Success      bool                `json:"success"` // false when any key failed
 	DeletedCount int                 `json:"deleted_count"`
+	Deleted      []string            `json:"deleted"`
 	Errors       []DeleteObjectError `json:"errors,omitempty"`
 }
 
@@ -86,50 +135,58 @@ type CreateBucketResponse struct {
 
 // 4. UploadFile Request and Response
 type UploadFileRequest struct {
-	BucketName string `json:"bucket_name" form:"bucket_name" binding:"required"`
-	ObjectKey  string `json:"object_key" form:"object_key" binding:"required"`
-	FileData   string `json:"file_data" form:"file_data" binding:"required"` // base64 encoded
+	BucketName     string `json:"bucket_name" form:"bucket_name" binding:"required"`
+	ObjectKey      string `json:"object_key" form:"object_key" binding:"required"`
+	FileData       string `json:"file_data" form:"file_data" binding:"required"` // base64 encoded
+	ContentType    string `json:"content_type" form:"content_type"`
+	ChecksumSHA256 string `json:"checksum_sha256" form:"checksum_sha256"` // base64, also read from the x-amz-checksum-sha256 header
+	ObjectOptions
 }
 
 type UploadFileResponse struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
+	Success        bool   `json:"success"`
+	Message        string `json:"message"`
+	ChecksumSHA256 string `json:"checksum_sha256,omitempty"`
 }
 
 // 5. UploadLargeObject Request and Response
 type UploadLargeObjectRequest struct {
-	BucketName string `json:"bucket_name" form:"bucket_name" binding:"required"`
-	ObjectKey  string `json:"object_key" form:"object_key" binding:"required"`
-	FileData   string `json:"file_data" form:"file_data" binding:"required"` // base64 encoded
+	BucketName     string `json:"bucket_name" form:"bucket_name" binding:"required"`
+	ObjectKey      string `json:"object_key" form:"object_key" binding:"required"`
+	FileData       string `json:"file_data" form:"file_data" binding:"required"` // base64 encoded
+	ContentType    string `json:"content_type" form:"content_type"`
+	ChecksumSHA256 string `json:"checksum_sha256" form:"checksum_sha256"` // base64, also read from the x-amz-checksum-sha256 header
+	ObjectOptions
 }
 
 type UploadLargeObjectResponse struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
+	Success        bool   `json:"success"`
+	Message        string `json:"message"`
+	ChecksumSHA256 string `json:"checksum_sha256,omitempty"`
 }
 
 // 6. DownloadFile Request and Response
 type DownloadFileRequest struct {
 	BucketName string `json:"bucket_name" form:"bucket_name" binding:"required"`
 	ObjectKey  string `json:"object_key" form:"object_key" binding:"required"`
+	VersionID  string `json:"version_id" form:"version_id"` // defaults to the latest version
 }
 
 type DownloadFileResponse struct {
-	FileData    string `json:"file_data"` // base64 encoded
-	ContentType string `json:"content_type"`
-	Size        int64  `json:"size"`
+	FileData       string `json:"file_data"` // base64 encoded
+	ContentType    string `json:"content_type"`
+	Size           int64  `json:"size"`
+	ChecksumSHA256 string `json:"checksum_sha256,omitempty"` // verified against the body
 }
 
-// 7. DownloadLargeObject Request and Response
+// 7. DownloadLargeObject Request (the response is the raw object body)
 type DownloadLargeObjectRequest struct {
-	BucketName string `json:"bucket_name" form:"bucket_name" binding:"required"`
-	ObjectKey  string `json:"object_key" form:"object_key" binding:"required"`
-}
-
-type DownloadLargeObjectResponse struct {
-	FileData    string `json:"file_data"` // base64 encoded
-	ContentType string `json:"content_type"`
-	Size        int64  `json:"size"`
+	BucketName  string `json:"bucket_name" form:"bucket_name" binding:"required"`
+	ObjectKey   string `json:"object_key" form:"object_key" binding:"required"`
+	FileName    string `json:"file_name" form:"file_name"` // Content-Disposition filename, defaults to the key's base name
+	VersionID   string `json:"version_id" form:"version_id"`
+	Range       string `json:"-" form:"-"` // from the Range header
+	IfNoneMatch string `json:"-" form:"-"` // from the If-None-Match header
 }
 
 // 8. CopyToFolder Request and Response
@@ -137,6 +194,7 @@ type CopyToFolderRequest struct {
 	BucketName string `json:"bucket_name" form:"bucket_name" binding:"required"`
 	ObjectKey  string `json:"object_key" form:"object_key" binding:"required"`
 	FolderName string `json:"folder_name" form:"folder_name" binding:"required"`
+	ObjectOptions
 }
 
 type CopyToFolderResponse struct {
@@ -150,6 +208,7 @@ type CopyToBucketRequest struct {
 	SourceBucket      string `json:"source_bucket" form:"source_bucket" binding:"required"`
 	DestinationBucket string `json:"destination_bucket" form:"destination_bucket" binding:"required"`
 	ObjectKey         string `json:"object_key" form:"object_key" binding:"required"`
+	ObjectOptions
 }
 
 type CopyToBucketResponse struct {
@@ -159,11 +218,18 @@ type CopyToBucketResponse struct {
 
 // 10. ListObjects Request and Response
 type ListObjectsRequest struct {
-	BucketName string `json:"bucket_name" form:"bucket_name" binding:"required"`
+	BucketName        string `json:"bucket_name" form:"bucket_name" binding:"required"`
+	Prefix            string `json:"prefix" form:"prefix"`
+	Delimiter         string `json:"delimiter" form:"delimiter"` // usually "/" to browse folders
+	ContinuationToken string `json:"continuation_token" form:"continuation_token"`
+	MaxKeys           int32  `json:"max_keys" form:"max_keys" binding:"omitempty,min=1,max=1000"`
 }
 
 type ListObjectsResponse struct {
-	Objects []ObjectInfo `json:"objects"`
+	Objects               []ObjectInfo `json:"objects"`
+	CommonPrefixes        []string     `json:"common_prefixes"`
+	NextContinuationToken string       `json:"next_continuation_token,omitempty"`
+	IsTruncated           bool         `json:"is_truncated"`
 }
 
 type ObjectInfo struct {
@@ -171,17 +237,19 @@ type ObjectInfo struct {
 	Size         int64  `json:"size"`
 	LastModified string `json:"last_modified"`
 	ETag         string `json:"etag"`
+	StorageClass string `json:"storage_class"`
 }
 
 // 11. DeleteObjectsFromBucket Request and Response
 type DeleteObjectsFromBucketRequest struct {
 	BucketName string   `json:"bucket_name" form:"bucket_name" binding:"required"`
-	ObjectKeys []string `json:"object_keys" form:"object_keys" binding:"required"`
+	ObjectKeys []string `json:"object_keys" form:"object_keys" binding:"required,min=1,max=10000,dive,required"`
 }
 
 type DeleteObjectsFromBucketResponse struct {
-	Success      bool                `json:"success"`
+	Success      bool                `json:"success"` // false when any key failed
 	DeletedCount int                 `json:"deleted_count"`
+	Deleted      []string            `json:"deleted"`
 	Errors       []DeleteObjectError `json:"errors,omitempty"`
 }
 
@@ -4,6 +4,16 @@ package model
 type SendMessageRequest struct {
 	QueueName string `json:"queue_name" binding:"required" example:"my-queue"`
 	Message   string `json:"message" binding:"required" example:"Hello World"`
+	MessageOptions
+}
+
+// MessageOptions are the optional settings of a message. DelaySeconds applies to standard
+// queues only, MessageGroupID and MessageDeduplicationID to FIFO queues only.
+type MessageOptions struct {
+	Attributes             map[string]string `json:"attributes,omitempty" example:"event:todo.created"`
+	DelaySeconds           int32             `json:"delay_seconds" binding:"omitempty,min=0,max=900" example:"0"`
+	MessageGroupID         string            `json:"message_group_id,omitempty" example:""`
+	MessageDeduplicationID string            `json:"message_deduplication_id,omitempty" example:""`
 }
 
 // SendMessageResponse represents the response for sending a message to SQS
@@ -13,16 +23,32 @@ type SendMessageResponse struct {
 	Error     string `json:"error,omitempty" example:""`
 }
 
-// SendMessagesRequest represents the request body for sending multiple messages to SQS
+// SendMessagesRequest represents the request body for sending multiple messages to SQS.
+// Messages are plain bodies, Entries carry their own options; at least one is required.
 type SendMessagesRequest struct {
-	QueueName string   `json:"queue_name" binding:"required" example:"my-queue"`
-	Messages  []string `json:"messages" binding:"required" example:"[\"message1\", \"message2\"]"`
+	QueueName string             `json:"queue_name" binding:"required" example:"my-queue"`
+	Messages  []string           `json:"messages" example:"[\"message1\", \"message2\"]"`
+	Entries   []SendMessageEntry `json:"entries" binding:"omitempty,dive"`
+}
+
+// SendMessageEntry is a message of a batch with its options
+type SendMessageEntry struct {
+	Message string `json:"message" binding:"required" example:"Hello World"`
+	MessageOptions
 }
 
 // SendMessagesResponse represents the response for sending multiple messages to SQS
 type SendMessagesResponse struct {
-	Success        bool     `json:"success" example:"true"`
-	SuccessCount   int      `json:"success_count" example:"2"`
-	FailedMessages []string `json:"failed_messages,omitempty" example:"[]"`
-	Error          string   `json:"error,omitempty" example:""`
+	Success        bool                `json:"success" example:"true"`
+	SuccessCount   int                 `json:"success_count" example:"2"`
+	FailedMessages []string            `json:"failed_messages,omitempty" example:"[]"`
+	Results        []SendMessageResult `json:"results"` // messages first, then entries, in request order
+	Error          string              `json:"error,omitempty" example:""`
+}
+
+// SendMessageResult is the outcome of one message of SendMessagesRequest
+type SendMessageResult struct {
+	Index     int    `json:"index" example:"0"`
+	MessageID string `json:"message_id,omitempty" example:"12345-67890-abcdef"`
+	Error     string `json:"error,omitempty" example:""`
 }
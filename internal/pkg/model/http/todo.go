@@ -1,14 +1,23 @@
 package model
 
 type CreateTodoRequest struct {
-	Title       string `json:"title" binding:"required"`
-	Description string `json:"description" binding:"required"`
+	Title       string           `json:"title" binding:"required"`
+	Description string           `json:"description" binding:"required"`
+	Attachments []TodoAttachment `json:"attachments" binding:"omitempty,dive"`
 }
 
 type UpdateTodoRequest struct {
-	Title       string `json:"title" binding:"required"`
-	Description string `json:"description" binding:"required"`
-	Completed   bool   `json:"completed" binding:"required"`
+	Title       string           `json:"title" binding:"required"`
+	Description string           `json:"description" binding:"required"`
+	Completed   bool             `json:"completed" binding:"required"`
+	Attachments []TodoAttachment `json:"attachments" binding:"omitempty,dive"` // replaces the attachments when set
+}
+
+// TodoAttachment is an object already uploaded to S3. Its checksum is recorded with the todo.
+type TodoAttachment struct {
+	BucketName     string `json:"bucket_name" binding:"required"`
+	ObjectKey      string `json:"object_key" binding:"required"`
+	ChecksumSHA256 string `json:"checksum_sha256"` // base64, optional, must match the object when set
 }
 
 type GetAllTodoResponse struct {
@@ -22,3 +31,14 @@ type GetTodoResponse struct {
 	CreatedAt   int64  `json:"created_at"`
 	UpdatedAt   int64  `json:"updated_at"`
 }
+
+// TodoIDRequest identifies a todo in the NATS requests, which have no path to carry it
+type TodoIDRequest struct {
+	ID string `json:"id" binding:"required"`
+}
+
+// UpdateTodoByIDRequest is UpdateTodoRequest over NATS, with the id of the todo
+type UpdateTodoByIDRequest struct {
+	ID string `json:"id" binding:"required"`
+	UpdateTodoRequest
+}
@@ -0,0 +1,40 @@
+package model
+
+// TransferObjects Request, shared by copy-objects, move-objects and sync-objects. Every
+// object under source_prefix is written to destination_prefix plus the rest of its key.
+type TransferObjectsRequest struct {
+	SourceBucket      string `json:"source_bucket" binding:"required"`
+	SourcePrefix      string `json:"source_prefix"`
+	DestinationBucket string `json:"destination_bucket" binding:"required"`
+	DestinationPrefix string `json:"destination_prefix"`
+	DryRun            bool   `json:"dry_run"` // only report what would be transferred
+	ObjectOptions
+}
+
+// TransferObjectsResponse carries the job to poll on /s3/jobs/:job_id
+type TransferObjectsResponse struct {
+	JobID string `json:"job_id"`
+}
+
+// TransferObjectsResult is the result of a finished transfer job. For a dry run Copied
+// counts the objects that would be copied.
+type TransferObjectsResult struct {
+	DryRun  bool            `json:"dry_run"`
+	Copied  int             `json:"copied"`
+	Skipped int             `json:"skipped"` // sync only, already up to date
+	Deleted int             `json:"deleted"` // move only, sources removed after copying
+	Failed  int             `json:"failed"`
+	Planned []TransferItem  `json:"planned,omitempty"` // dry run only, the first 1000 objects
+	Errors  []TransferError `json:"errors,omitempty"`
+}
+
+type TransferItem struct {
+	SourceKey      string `json:"source_key"`
+	DestinationKey string `json:"destination_key"`
+	Action         string `json:"action"` // copy or skip
+}
+
+type TransferError struct {
+	SourceKey string `json:"source_key"`
+	Message   string `json:"message"`
+}
@@ -2,11 +2,15 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
+
+	"go-base/internal/pkg/health"
+
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
-	"time"
 )
 
 var (
@@ -14,14 +18,20 @@ var (
 )
 
 func GetInstance() *Manager {
+	if instance == nil {
+		instance = &Manager{}
+	}
 	return instance
 }
 
 type Manager struct {
 	conn    *pgxpool.Pool
 	context context.Context
+	tracker health.Tracker
 }
 
+var _ health.HealthChecker = (*Manager)(nil)
+
 type Config struct {
 	Username                string
 	Password                string
@@ -32,6 +42,7 @@ type Config struct {
 	MaxConnSize             int32
 	MaxConnIdleTimeBySecond time.Duration
 	MaxConnLifetimeBySecond time.Duration
+	HealthCheckPeriod       time.Duration // between the checks replacing broken idle connections, driver default when 0
 }
 
 func (manager *Manager) Setup(config Config) error {
@@ -55,23 +66,26 @@ func (manager *Manager) Setup(config Config) error {
 	pgxConfig.MaxConns = config.MaxConnSize
 	pgxConfig.MaxConnIdleTime = config.MaxConnIdleTimeBySecond * time.Second
 	pgxConfig.MaxConnLifetime = config.MaxConnLifetimeBySecond * time.Second
+	if config.HealthCheckPeriod > 0 {
+		pgxConfig.HealthCheckPeriod = config.HealthCheckPeriod
+	}
 
 	pool, err := pgxpool.ConnectConfig(background, pgxConfig)
 	if err != nil {
 		fmt.Printf("postgres connect fail, %+v\n", err)
-		return err
+		return manager.tracker.Observe(err)
 	}
 
 	err = pool.Ping(background)
 	if err != nil {
 		fmt.Printf("postgres ping fail, %+v\n", err)
-		return err
+		pool.Close()
+		return manager.tracker.Observe(err)
 	}
 
-	instance = &Manager{
-		conn:    pool,
-		context: background,
-	}
+	manager.conn = pool
+	manager.context = background
+	manager.tracker.Observe(nil)
 
 	return nil
 }
@@ -108,3 +122,40 @@ func (manager *Manager) DoInTx(fn func(*pgx.Tx) error) error {
 
 	return tx.Commit(manager.context)
 }
+
+// Close closes the connection pool, waiting for the connections in use to be released
+func (manager *Manager) Close() {
+	if manager == nil || manager.conn == nil {
+		return
+	}
+	manager.conn.Close()
+}
+
+// Name identifies the database in health reports
+func (manager *Manager) Name() string {
+	return "postgres"
+}
+
+// Ping checks a connection of the pool until ctx is done. Broken connections are replaced
+// by the pool.
+func (manager *Manager) Ping(ctx context.Context) error {
+	if manager.conn == nil {
+		return manager.tracker.Observe(errors.New("postgres not set up"))
+	}
+	return manager.tracker.Observe(manager.conn.Ping(ctx))
+}
+
+// State returns the state of the connection as of the last ping
+func (manager *Manager) State() health.State {
+	return manager.tracker.State()
+}
+
+// LastError returns the last error of a ping
+func (manager *Manager) LastError() error {
+	return manager.tracker.LastError()
+}
+
+// LastErrorAt returns when the last ping failed
+func (manager *Manager) LastErrorAt() time.Time {
+	return manager.tracker.LastErrorAt()
+}
@@ -0,0 +1,90 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-base/internal/pkg/health"
+	"go-base/internal/pkg/logger"
+
+	"github.com/nats-io/nats.go"
+)
+
+var _ health.HealthChecker = (*Manager)(nil)
+
+// Name identifies NATS in health reports
+func (manager *Manager) Name() string {
+	return "nats"
+}
+
+// Ping checks the connection with a round trip to the server until ctx is done
+func (manager *Manager) Ping(ctx context.Context) error {
+	if manager.connect == nil {
+		return errors.New("queue not set up")
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultRequestTimeout)
+		defer cancel()
+	}
+	if err := manager.connect.FlushWithContext(ctx); err != nil {
+		manager.tracker.Set(manager.State(), err)
+		return err
+	}
+	return nil
+}
+
+// State returns the state of the connection as the client sees it
+func (manager *Manager) State() health.State {
+	if manager.connect == nil {
+		return manager.tracker.State()
+	}
+	return connectionState(manager.connect)
+}
+
+// connectionState maps the status of a connection to its health
+func connectionState(nc *nats.Conn) health.State {
+	switch nc.Status() {
+	case nats.CONNECTED:
+		return health.StateUp
+	case nats.RECONNECTING, nats.CONNECTING:
+		return health.StateReconnecting
+	default:
+		return health.StateDown
+	}
+}
+
+// LastError returns the last error of the connection, e.g. why it was disconnected
+func (manager *Manager) LastError() error {
+	return manager.tracker.LastError()
+}
+
+// LastErrorAt returns when the last error happened
+func (manager *Manager) LastErrorAt() time.Time {
+	return manager.tracker.LastErrorAt()
+}
+
+// disconnected records why the connection was lost, the client reconnects with backoff
+func (manager *Manager) disconnected(nc *nats.Conn, err error) {
+	if err == nil {
+		return // closed on purpose
+	}
+	logger.Warn.Printf("queue disconnected, reconnecting: %v", err)
+	manager.tracker.Set(health.StateReconnecting, err)
+}
+
+func (manager *Manager) reconnected(nc *nats.Conn) {
+	logger.Info.Printf("queue reconnected to %s", nc.ConnectedUrl())
+	manager.tracker.Set(health.StateUp, nil)
+}
+
+// asyncError records the errors of subscriptions, e.g. slow consumers
+func (manager *Manager) asyncError(nc *nats.Conn, sub *nats.Subscription, err error) {
+	if sub != nil {
+		logger.Error.Printf("queue subscription %s error: %v", sub.Subject, err)
+	} else {
+		logger.Error.Printf("queue error: %v", err)
+	}
+	manager.tracker.Set(connectionState(nc), err)
+}
@@ -0,0 +1,336 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go-base/internal/pkg/logger"
+
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	defaultMaxDeliver = 5
+	defaultAckWait    = 30 * time.Second
+	defaultBatch      = 10
+	fetchWait         = 5 * time.Second
+	fetchRetryDelay   = time.Second
+
+	// maxDeliveriesAdvisory is published by JetStream for messages delivered MaxDeliver
+	// times without being acked, followed by .<stream>.<consumer>
+	maxDeliveriesAdvisory = "$JS.EVENT.ADVISORY.CONSUMER.MAX_DELIVERIES"
+)
+
+// NakError asks JetStream to redeliver a message after Delay
+type NakError struct {
+	Delay time.Duration
+	Err   error
+}
+
+func (e *NakError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *NakError) Unwrap() error {
+	return e.Err
+}
+
+// Nak returns err from a handler so the message is redelivered after delay, instead of
+// right away
+func Nak(delay time.Duration, err error) error {
+	return &NakError{Delay: delay, Err: err}
+}
+
+// TermError asks JetStream never to redeliver a message
+type TermError struct {
+	Err error
+}
+
+func (e *TermError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TermError) Unwrap() error {
+	return e.Err
+}
+
+// Term returns err from a handler so the message isn't redelivered, e.g. when it can
+// never be handled
+func Term(err error) error {
+	return &TermError{Err: err}
+}
+
+// PublishOptions holds the options of a JetStream message
+type PublishOptions struct {
+	MsgID  string      // stored as Nats-Msg-Id and EventIDHeader, the stream drops messages with the same id within its Duplicates window
+	Header nats.Header // extra headers
+}
+
+// ConsumerConfig holds configuration for a durable JetStream consumer
+type ConsumerConfig struct {
+	Stream       string        // stream the consumer reads
+	Durable      string        // consumer name kept by the server, so a restart resumes where it stopped
+	Subject      string        // filter, empty reads every subject of the stream
+	MaxDeliver   int           // deliveries before a message is given up, default 5
+	AckWait      time.Duration // unacked messages are redelivered after it, default 30s
+	Batch        int           // messages fetched at once by pull consumers, default 10
+	Middleware   []Middleware  // outermost first
+	OnMaxDeliver MaxDeliverHandler
+}
+
+// MaxDeliverHandler receives the messages delivered MaxDeliver times without being
+// handled, e.g. to store them for inspection. They're logged when it's nil.
+type MaxDeliverHandler func(ctx context.Context, msg *nats.RawStreamMsg, deliveries int)
+
+// maxDeliveriesEvent is the advisory JetStream publishes when a message runs out of
+// deliveries
+type maxDeliveriesEvent struct {
+	Stream     string `json:"stream"`
+	Consumer   string `json:"consumer"`
+	StreamSeq  uint64 `json:"stream_seq"`
+	Deliveries int    `json:"deliveries"`
+}
+
+// Consumer receives the messages of a durable JetStream consumer
+type Consumer struct {
+	subscription *nats.Subscription
+	advisory     *nats.Subscription
+	cancel       context.CancelFunc
+	done         chan struct{}
+	stopOnce     sync.Once
+}
+
+// AddStream creates a JetStream stream, or updates it when it exists
+func (manager *Manager) AddStream(config nats.StreamConfig) error {
+	return addStream(manager.jetStream, config)
+}
+
+func addStream(js nats.JetStreamContext, config nats.StreamConfig) error {
+	_, err := js.AddStream(&config)
+	if errors.Is(err, nats.ErrStreamNameAlreadyInUse) {
+		_, err = js.UpdateStream(&config)
+	}
+	return err
+}
+
+// PublishMsg publishes msg as JSON to a JetStream subject and waits for the stream to
+// store it. The ack tells whether the stream dropped it as a duplicate of options.MsgID.
+func (manager *Manager) PublishMsg(ctx context.Context, sub string, msg interface{}, options PublishOptions) (*nats.PubAck, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	m := nats.NewMsg(sub)
+	m.Data = data
+	for key, values := range options.Header {
+		m.Header[key] = values
+	}
+	opts := []nats.PubOpt{nats.Context(ctx)}
+	if options.MsgID != "" {
+		opts = append(opts, nats.MsgId(options.MsgID))
+		if m.Header.Get(EventIDHeader) == "" {
+			m.Header.Set(EventIDHeader, options.MsgID)
+		}
+	}
+
+	return manager.jetStream.PublishMsg(m, opts...)
+}
+
+// SubscribePush creates or updates a durable push consumer and handles its messages as
+// they are delivered. Every subscriber of the same consumer shares its messages.
+func (manager *Manager) SubscribePush(config ConsumerConfig, handler MsgHandler) (*Consumer, error) {
+	config = config.withDefaults()
+	consumerConfig := config.consumerConfig()
+	consumerConfig.DeliverSubject = fmt.Sprintf("deliver.%s.%s", config.Stream, config.Durable)
+	consumerConfig.DeliverGroup = config.Durable
+	if err := manager.addConsumer(config.Stream, consumerConfig); err != nil {
+		return nil, err
+	}
+
+	handler = Chain(handler, config.Middleware...)
+	subscription, err := manager.jetStream.QueueSubscribe(config.Subject, config.Durable, func(msg *nats.Msg) {
+		manager.handleMsg(context.Background(), msg, handler)
+	}, nats.Bind(config.Stream, config.Durable), nats.ManualAck())
+	if err != nil {
+		return nil, err
+	}
+
+	consumer := &Consumer{subscription: subscription, cancel: func() {}, done: make(chan struct{})}
+	close(consumer.done)
+	return manager.startConsumer(consumer, config)
+}
+
+// SubscribePull creates or updates a durable pull consumer and handles its messages in
+// batches, fetching the next batch when the previous one is handled
+func (manager *Manager) SubscribePull(config ConsumerConfig, handler MsgHandler) (*Consumer, error) {
+	config = config.withDefaults()
+	if err := manager.addConsumer(config.Stream, config.consumerConfig()); err != nil {
+		return nil, err
+	}
+
+	subscription, err := manager.jetStream.PullSubscribe(config.Subject, config.Durable, nats.Bind(config.Stream, config.Durable))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	consumer := &Consumer{subscription: subscription, cancel: cancel, done: make(chan struct{})}
+	handler = Chain(handler, config.Middleware...)
+	go func() {
+		defer close(consumer.done)
+		manager.fetchLoop(ctx, subscription, config.Batch, handler)
+	}()
+	return manager.startConsumer(consumer, config)
+}
+
+// Stop stops receiving messages and waits for the ones being handled. The consumer stays
+// on the server, subscribing again resumes from the first unacked message.
+func (consumer *Consumer) Stop() error {
+	return consumer.stop()
+}
+
+func (consumer *Consumer) stop() (err error) {
+	consumer.stopOnce.Do(func() {
+		consumer.cancel()
+		<-consumer.done
+		if consumer.advisory != nil {
+			consumer.advisory.Drain()
+		}
+		err = consumer.subscription.Drain()
+	})
+	return err
+}
+
+// withDefaults fills in the unset options
+func (config ConsumerConfig) withDefaults() ConsumerConfig {
+	if config.MaxDeliver <= 0 {
+		config.MaxDeliver = defaultMaxDeliver
+	}
+	if config.AckWait <= 0 {
+		config.AckWait = defaultAckWait
+	}
+	if config.Batch <= 0 {
+		config.Batch = defaultBatch
+	}
+	return config
+}
+
+// consumerConfig returns the server configuration shared by push and pull consumers
+func (config ConsumerConfig) consumerConfig() nats.ConsumerConfig {
+	return nats.ConsumerConfig{
+		Durable:       config.Durable,
+		FilterSubject: config.Subject,
+		AckPolicy:     nats.AckExplicitPolicy,
+		AckWait:       config.AckWait,
+		MaxDeliver:    config.MaxDeliver,
+		DeliverPolicy: nats.DeliverAllPolicy,
+	}
+}
+
+// addConsumer creates a durable consumer, or updates it when it exists. Consumers created
+// by the subscription would be deleted when it's drained.
+func (manager *Manager) addConsumer(stream string, config nats.ConsumerConfig) error {
+	if _, err := manager.jetStream.ConsumerInfo(stream, config.Durable); err == nil {
+		_, err = manager.jetStream.UpdateConsumer(stream, &config)
+		return err
+	} else if !errors.Is(err, nats.ErrConsumerNotFound) {
+		return err
+	}
+	_, err := manager.jetStream.AddConsumer(stream, &config)
+	return err
+}
+
+// startConsumer subscribes to the max deliveries advisories of the consumer and keeps it,
+// so Close stops it
+func (manager *Manager) startConsumer(consumer *Consumer, config ConsumerConfig) (*Consumer, error) {
+	advisorySubject := fmt.Sprintf("%s.%s.%s", maxDeliveriesAdvisory, config.Stream, config.Durable)
+	advisory, err := manager.connect.QueueSubscribe(advisorySubject, config.Durable, func(msg *nats.Msg) {
+		manager.handleMaxDeliveries(msg, config.OnMaxDeliver)
+	})
+	if err != nil {
+		consumer.stop()
+		return nil, err
+	}
+	consumer.advisory = advisory
+
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+	manager.consumers = append(manager.consumers, consumer)
+	return consumer, nil
+}
+
+// fetchLoop fetches and handles batches until ctx is done
+func (manager *Manager) fetchLoop(ctx context.Context, subscription *nats.Subscription, batch int, handler MsgHandler) {
+	for ctx.Err() == nil {
+		fetchCtx, cancel := context.WithTimeout(ctx, fetchWait)
+		msgs, err := subscription.Fetch(batch, nats.Context(fetchCtx))
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, nats.ErrTimeout) {
+				continue
+			}
+			logger.Error.Printf("queue failed to fetch messages of %s: %v", subscription.Subject, err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(fetchRetryDelay):
+			}
+			continue
+		}
+
+		// the batch is handled even when stopping, it's already delivered
+		for _, msg := range msgs {
+			manager.handleMsg(context.WithoutCancel(ctx), msg, handler)
+		}
+	}
+}
+
+// handleMsg handles a JetStream message and acks it when handled, naks it on errors or
+// terminates it on TermError. Messages the handler settled itself are left alone.
+func (manager *Manager) handleMsg(ctx context.Context, msg *nats.Msg, handler MsgHandler) {
+	err := handler(ctx, msg)
+
+	var ackErr error
+	var nakErr *NakError
+	var termErr *TermError
+	switch {
+	case err == nil:
+		ackErr = msg.Ack()
+	case errors.As(err, &termErr):
+		logger.Error.Printf("queue terminated message on %s: %v", msg.Subject, err)
+		ackErr = msg.Term()
+	case errors.As(err, &nakErr):
+		logger.Warn.Printf("queue failed to handle message on %s, retrying in %s: %v", msg.Subject, nakErr.Delay, err)
+		ackErr = msg.NakWithDelay(nakErr.Delay)
+	default:
+		logger.Error.Printf("queue failed to handle message on %s: %v", msg.Subject, err)
+		ackErr = msg.Nak()
+	}
+	if ackErr != nil && !errors.Is(ackErr, nats.ErrMsgAlreadyAckd) {
+		logger.Error.Printf("queue failed to settle message on %s: %v", msg.Subject, ackErr)
+	}
+}
+
+// handleMaxDeliveries passes the message of a max deliveries advisory to handler
+func (manager *Manager) handleMaxDeliveries(advisory *nats.Msg, handler MaxDeliverHandler) {
+	var event maxDeliveriesEvent
+	if err := json.Unmarshal(advisory.Data, &event); err != nil {
+		logger.Error.Printf("queue failed to parse advisory %s: %v", advisory.Subject, err)
+		return
+	}
+
+	msg, err := manager.jetStream.GetMsg(event.Stream, event.StreamSeq)
+	if err != nil {
+		logger.Error.Printf("queue message %d of %s ran out of deliveries, failed to get it: %v", event.StreamSeq, event.Stream, err)
+		return
+	}
+	if handler == nil {
+		logger.Error.Printf("queue message %d of %s on %s ran out of deliveries after %d", event.StreamSeq, event.Stream, msg.Subject, event.Deliveries)
+		return
+	}
+	handler(context.Background(), msg, event.Deliveries)
+}
@@ -0,0 +1,68 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-base/internal/pkg/dedupe"
+	"go-base/internal/pkg/logger"
+
+	"github.com/nats-io/nats.go"
+)
+
+// EventIDHeader carries the id of an event, used to handle redeliveries once
+const EventIDHeader = "event-id"
+
+// MsgHandler handles a message, an error means it wasn't handled
+type MsgHandler func(ctx context.Context, msg *nats.Msg) error
+
+// Middleware wraps a MsgHandler, e.g. to deduplicate every message
+type Middleware func(next MsgHandler) MsgHandler
+
+// dedupeRetryDelay is how long a message handled by another subscriber is naked for
+const dedupeRetryDelay = 30 * time.Second
+
+// DedupeMiddleware handles every event once, by EventIDHeader or the JetStream
+// Nats-Msg-Id header when missing. Messages without an id are always handled. Duplicates
+// still being handled by another subscriber fail with dedupe.ErrInProgress, which
+// JetStream consumers redeliver after a while.
+func DedupeMiddleware(store dedupe.Store) Middleware {
+	return func(next MsgHandler) MsgHandler {
+		return func(ctx context.Context, msg *nats.Msg) error {
+			id := msg.Header.Get(EventIDHeader)
+			if id == "" {
+				id = msg.Header.Get(nats.MsgIdHdr)
+			}
+			if id == "" {
+				return next(ctx, msg)
+			}
+			err := dedupe.Once(ctx, store, id, func(ctx context.Context) error {
+				return next(ctx, msg)
+			})
+			if errors.Is(err, dedupe.ErrInProgress) {
+				return Nak(dedupeRetryDelay, err)
+			}
+			return err
+		}
+	}
+}
+
+// SubscribeHandler subscribes handler to sub through middleware, the first outermost.
+// Errors are logged, core NATS doesn't redeliver.
+func (manager *Manager) SubscribeHandler(sub string, handler MsgHandler, middleware ...Middleware) (*nats.Subscription, error) {
+	handler = Chain(handler, middleware...)
+	return manager.connect.Subscribe(sub, func(msg *nats.Msg) {
+		if err := handler(context.Background(), msg); err != nil {
+			logger.Error.Printf("queue failed to handle message on %s: %v", msg.Subject, err)
+		}
+	})
+}
+
+// Chain wraps handler in middleware, the first outermost
+func Chain(handler MsgHandler, middleware ...Middleware) MsgHandler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
@@ -1,8 +1,14 @@
 package queue
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
+	"sync"
+	"time"
+
+	"go-base/internal/pkg/health"
+	"go-base/internal/pkg/logger"
+
 	"github.com/nats-io/nats.go"
 )
 
@@ -11,29 +17,77 @@ var (
 )
 
 func GetInstance() *Manager {
+	if instance == nil {
+		instance = &Manager{}
+	}
 	return instance
 }
 
 type Manager struct {
-	connect *nats.Conn
+	connect   *nats.Conn
+	jetStream nats.JetStreamContext
+	consumers []*Consumer
+	closed    chan struct{}
+	tracker   health.Tracker
+	mu        sync.Mutex
 }
 
 type Config struct {
-	Url string
+	Url           string
+	Streams       []nats.StreamConfig // JetStream streams created, or updated when they exist, on Setup
+	ReconnectWait time.Duration       // between reconnect attempts, 2 seconds by default
+	MaxReconnects int                 // attempts before giving up, 0 retries forever
 }
 
 func (manager *Manager) Setup(config Config) error {
 
-	nc, err := nats.Connect(config.Url)
+	if config.ReconnectWait <= 0 {
+		config.ReconnectWait = 2 * time.Second
+	}
+	if config.MaxReconnects <= 0 {
+		config.MaxReconnects = -1
+	}
+
+	closed := make(chan struct{})
+	nc, err := nats.Connect(config.Url,
+		nats.ReconnectWait(config.ReconnectWait),
+		nats.MaxReconnects(config.MaxReconnects),
+		nats.DisconnectErrHandler(manager.disconnected),
+		nats.ReconnectHandler(manager.reconnected),
+		nats.ErrorHandler(manager.asyncError),
+		nats.ClosedHandler(func(*nats.Conn) {
+			close(closed)
+		}),
+	)
+	if err != nil {
+		logger.Error.Printf("queue connect fail, %+v\n", err)
+		manager.tracker.Set(health.StateDown, err)
+		return err
+	}
+
+	js, err := nc.JetStream()
 	if err != nil {
-		fmt.Printf("queue connect fail, %+v\n", err)
+		logger.Error.Printf("queue JetStream fail, %+v\n", err)
+		manager.tracker.Set(health.StateDown, err)
+		nc.Close()
 		return err
 	}
 
-	instance = &Manager{
-		connect: nc,
+	// provisioned before the manager holds the connection, which is closed when it fails
+	for i := range config.Streams {
+		if err = addStream(js, config.Streams[i]); err != nil {
+			logger.Error.Printf("queue stream %s fail, %+v\n", config.Streams[i].Name, err)
+			manager.tracker.Set(health.StateDown, err)
+			nc.Close()
+			return err
+		}
 	}
 
+	manager.connect = nc
+	manager.jetStream = js
+	manager.consumers = nil
+	manager.closed = closed
+
 	return nil
 }
 
@@ -63,3 +117,30 @@ func (manager *Manager) Subscribe(sub string, msgHandler func(sub string, msg []
 
 	return subscription, nil
 }
+
+// Close stops the consumers and drains the connection: subscriptions stop receiving, the
+// messages already received are handled and acked, and pending publishes are flushed. It
+// waits for the connection to close until ctx is done.
+func (manager *Manager) Close(ctx context.Context) error {
+	if manager == nil || manager.connect == nil {
+		return nil
+	}
+
+	manager.mu.Lock()
+	consumers := manager.consumers
+	manager.consumers = nil
+	manager.mu.Unlock()
+	for _, consumer := range consumers {
+		consumer.stop()
+	}
+
+	if err := manager.connect.Drain(); err != nil {
+		return err
+	}
+	select {
+	case <-manager.closed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
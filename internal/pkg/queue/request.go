@@ -0,0 +1,142 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go-base/internal/pkg/logger"
+
+	"github.com/nats-io/nats.go"
+)
+
+// defaultRequestTimeout bounds requests whose ctx has no deadline
+const defaultRequestTimeout = 5 * time.Second
+
+// Reply is the body of every reply sent by Respond: the result in Data, or the reason it
+// failed in Error
+type Reply struct {
+	Data  json.RawMessage `json:"data,omitempty"`
+	Error *ReplyError     `json:"error,omitempty"`
+}
+
+// ReplyError is a failed request, with the HTTP status and error code the same request
+// gets over HTTP
+type ReplyError struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+func (e *ReplyError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%d %s: %s", e.Status, e.Code, e.Message)
+	}
+	return fmt.Sprintf("%d %s", e.Status, e.Code)
+}
+
+// QueueSubscribe subscribes to sub in queue group, each message goes to one subscriber of
+// the group
+func (manager *Manager) QueueSubscribe(sub, queue string, msgHandler func(sub string, msg []byte)) (*nats.Subscription, error) {
+
+	subscription, err := manager.connect.QueueSubscribe(sub, queue, func(msg *nats.Msg) {
+		msgHandler(msg.Subject, msg.Data)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// QueueSubscribeHandler subscribes handler to sub in queue group through middleware, the
+// first outermost. Errors are logged.
+func (manager *Manager) QueueSubscribeHandler(sub, queue string, handler MsgHandler, middleware ...Middleware) (*nats.Subscription, error) {
+	handler = Chain(handler, middleware...)
+	return manager.connect.QueueSubscribe(sub, queue, func(msg *nats.Msg) {
+		if err := handler(context.Background(), msg); err != nil {
+			logger.Error.Printf("queue failed to handle message on %s: %v", msg.Subject, err)
+		}
+	})
+}
+
+// Request sends payload as JSON to sub and waits for the reply until ctx is done, or
+// for 5 seconds when ctx has no deadline. Use DecodeReply on replies sent by Respond.
+func (manager *Manager) Request(ctx context.Context, sub string, payload interface{}) (*nats.Msg, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultRequestTimeout)
+		defer cancel()
+	}
+	return manager.connect.RequestWithContext(ctx, sub, data)
+}
+
+// DecodeReply decodes the data of a reply sent by Respond into v, or returns its
+// *ReplyError
+func DecodeReply(msg *nats.Msg, v interface{}) error {
+	var reply Reply
+	if err := json.Unmarshal(msg.Data, &reply); err != nil {
+		return fmt.Errorf("failed to decode reply: %w", err)
+	}
+	if reply.Error != nil {
+		return reply.Error
+	}
+	if v == nil || len(reply.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(reply.Data, v); err != nil {
+		return fmt.Errorf("failed to decode reply data: %w", err)
+	}
+	return nil
+}
+
+// Respond returns a MsgHandler answering JSON requests: it decodes the request into Req,
+// an empty one leaving it zero, calls handler and replies with its result as Reply.
+// Errors other than *ReplyError are replied as internal errors. Only failing to reply is
+// returned.
+func Respond[Req, Resp any](handler func(ctx context.Context, request Req) (Resp, error)) MsgHandler {
+	return func(ctx context.Context, msg *nats.Msg) error {
+		var reply Reply
+		var request Req
+		if len(msg.Data) > 0 {
+			if err := json.Unmarshal(msg.Data, &request); err != nil {
+				reply.Error = &ReplyError{Status: http.StatusBadRequest, Code: http.StatusText(http.StatusBadRequest), Message: err.Error()}
+				return respond(msg, reply)
+			}
+		}
+
+		response, err := handler(ctx, request)
+		if err != nil {
+			var replyErr *ReplyError
+			if !errors.As(err, &replyErr) {
+				logger.Error.Printf("queue failed to handle request on %s: %v", msg.Subject, err)
+				replyErr = &ReplyError{Status: http.StatusInternalServerError, Code: http.StatusText(http.StatusInternalServerError)}
+			}
+			reply.Error = replyErr
+			return respond(msg, reply)
+		}
+
+		if reply.Data, err = json.Marshal(response); err != nil {
+			logger.Error.Printf("queue failed to encode reply on %s: %v", msg.Subject, err)
+			reply.Error = &ReplyError{Status: http.StatusInternalServerError, Code: http.StatusText(http.StatusInternalServerError)}
+		}
+		return respond(msg, reply)
+	}
+}
+
+// respond sends reply to the requester of msg
+func respond(msg *nats.Msg, reply Reply) error {
+	data, err := json.Marshal(reply)
+	if err != nil {
+		return err
+	}
+	return msg.Respond(data)
+}
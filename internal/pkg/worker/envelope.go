@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-base/internal/pkg/util"
+)
+
+// ErrMalformedMessage is returned for messages that aren't an envelope or whose payload
+// doesn't decode. Retrying won't fix them, so SQSWorker doesn't retry them and moves them
+// to the dead-letter queue on their first delivery.
+var ErrMalformedMessage = errors.New("malformed message")
+
+// Envelope is the versioned JSON body of the messages routed by MessageRouter:
+//
+//	{"type": "todo.created", "version": 1, "id": "...", "timestamp": "...", "payload": {...}}
+type Envelope struct {
+	Type      string          `json:"type"`
+	Version   int             `json:"version"` // version of the payload schema, 1 when missing
+	ID        string          `json:"id"`      // unique per event, used to deduplicate redeliveries
+	Timestamp time.Time       `json:"timestamp"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// NewEnvelope wraps payload in an envelope with a new id and the current time
+func NewEnvelope(messageType string, version int, payload interface{}) (Envelope, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("failed to encode %s payload: %w", messageType, err)
+	}
+	return Envelope{
+		Type:      messageType,
+		Version:   version,
+		ID:        util.GenUUID(),
+		Timestamp: time.Now().UTC(),
+		Payload:   data,
+	}, nil
+}
+
+// Body returns the envelope as a message body
+func (e Envelope) Body() (string, error) {
+	data, err := json.Marshal(e)
+	return string(data), err
+}
+
+// Decode decodes the payload into v
+func (e Envelope) Decode(v interface{}) error {
+	if err := json.Unmarshal(e.Payload, v); err != nil {
+		return fmt.Errorf("%w: %s v%d payload, %v", ErrMalformedMessage, e.Type, e.Version, err)
+	}
+	return nil
+}
+
+// parseEnvelope reads the envelope of a message body
+func parseEnvelope(body string) (Envelope, error) {
+	var envelope Envelope
+	if err := json.Unmarshal([]byte(body), &envelope); err != nil {
+		return Envelope{}, fmt.Errorf("%w: %v", ErrMalformedMessage, err)
+	}
+	if envelope.Type == "" {
+		return Envelope{}, fmt.Errorf("%w: type is required", ErrMalformedMessage)
+	}
+	if envelope.Version == 0 {
+		envelope.Version = 1
+	}
+	return envelope, nil
+}
@@ -0,0 +1,135 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go-base/internal/pkg/aws/sqs"
+	"go-base/internal/pkg/logger"
+)
+
+// HandlerFunc handles the envelope of a message routed by MessageRouter
+type HandlerFunc func(ctx context.Context, envelope Envelope, message sqs.Message) error
+
+// Middleware wraps a handler, e.g. to log, measure or deduplicate every message
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// UnknownTypePolicy decides what happens to messages no handler is registered for
+type UnknownTypePolicy int
+
+const (
+	// RejectUnknown fails them, so they are retried and end up in the dead-letter queue
+	RejectUnknown UnknownTypePolicy = iota
+	// DiscardUnknown logs and deletes them
+	DiscardUnknown
+)
+
+// MessageRouter is a MessageProcessor that reads the Envelope of a message and calls the
+// handler registered for its type and version, through the middleware
+type MessageRouter struct {
+	handlers       map[routeKey]HandlerFunc
+	defaultHandler HandlerFunc
+	unknownType    UnknownTypePolicy
+	middleware     []Middleware
+	mu             sync.RWMutex
+}
+
+// MessageRouterConfig holds configuration for MessageRouter
+type MessageRouterConfig struct {
+	UnknownType    UnknownTypePolicy // for messages without a handler, when DefaultHandler is nil
+	DefaultHandler HandlerFunc       // handles messages without a handler
+	Middleware     []Middleware      // outermost first
+}
+
+type routeKey struct {
+	messageType string
+	version     int
+}
+
+var _ MessageProcessor = (*MessageRouter)(nil)
+
+// NewMessageRouter creates a new message router
+func NewMessageRouter(cfg MessageRouterConfig) *MessageRouter {
+	return &MessageRouter{
+		handlers:       make(map[routeKey]HandlerFunc),
+		defaultHandler: cfg.DefaultHandler,
+		unknownType:    cfg.UnknownType,
+		middleware:     cfg.Middleware,
+	}
+}
+
+// HandleFunc registers handler for the messages of messageType and version, replacing
+// the previous one
+func (r *MessageRouter) HandleFunc(messageType string, version int, handler HandlerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.handlers[routeKey{messageType: messageType, version: version}] = handler
+}
+
+// Use adds middleware around every handler, inside the middleware added before
+func (r *MessageRouter) Use(middleware ...Middleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.middleware = append(r.middleware, middleware...)
+}
+
+// Handle registers handler for the messages of messageType and version, with their
+// payload decoded into T. Payloads that don't decode fail with ErrMalformedMessage.
+func Handle[T any](r *MessageRouter, messageType string, version int, handler func(ctx context.Context, payload T, envelope Envelope) error) {
+	r.HandleFunc(messageType, version, func(ctx context.Context, envelope Envelope, message sqs.Message) error {
+		var payload T
+		if err := envelope.Decode(&payload); err != nil {
+			return err
+		}
+		return handler(ctx, payload, envelope)
+	})
+}
+
+// ProcessMessage routes a message to the handler of its type and version. The middleware
+// runs for every message, malformed ones included: they reach it with the zero Envelope
+// and fail with ErrMalformedMessage.
+func (r *MessageRouter) ProcessMessage(ctx context.Context, message sqs.Message) error {
+	r.mu.RLock()
+	middleware := r.middleware
+	r.mu.RUnlock()
+
+	handler := HandlerFunc(r.route)
+	envelope, err := parseEnvelope(message.Body)
+	if err != nil {
+		handler = func(ctx context.Context, envelope Envelope, message sqs.Message) error {
+			return err
+		}
+	}
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler(ctx, envelope, message)
+}
+
+// route calls the handler registered for the type and version of envelope, or applies
+// the unknown-type policy
+func (r *MessageRouter) route(ctx context.Context, envelope Envelope, message sqs.Message) error {
+	r.mu.RLock()
+	handler, ok := r.handlers[routeKey{messageType: envelope.Type, version: envelope.Version}]
+	r.mu.RUnlock()
+
+	if !ok {
+		if r.defaultHandler == nil {
+			return r.unknown(envelope, message)
+		}
+		handler = r.defaultHandler
+	}
+	return handler(ctx, envelope, message)
+}
+
+// unknown applies the unknown-type policy to a message without a handler
+func (r *MessageRouter) unknown(envelope Envelope, message sqs.Message) error {
+	if r.unknownType == DiscardUnknown {
+		logger.Warn.Printf("MessageRouter discarded message %s, no handler for %s v%d", message.MessageID, envelope.Type, envelope.Version)
+		return nil
+	}
+	return fmt.Errorf("no handler for %s v%d", envelope.Type, envelope.Version)
+}
@@ -0,0 +1,105 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"go-base/internal/pkg/aws/sqs"
+	"go-base/internal/pkg/dedupe"
+	"go-base/internal/pkg/logger"
+)
+
+// LoggingMiddleware logs every message with how long it took and how it ended
+func LoggingMiddleware() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, envelope Envelope, message sqs.Message) error {
+			start := time.Now()
+			err := next(ctx, envelope, message)
+			if err != nil {
+				logger.Error.Printf("MessageRouter %s v%d message %s failed in %s: %v", envelope.Type, envelope.Version, envelope.ID, time.Since(start), err)
+			} else {
+				logger.Info.Printf("MessageRouter %s v%d message %s handled in %s", envelope.Type, envelope.Version, envelope.ID, time.Since(start))
+			}
+			return err
+		}
+	}
+}
+
+// MetricsRecorder receives the outcome of every message, e.g. to export it as metrics
+type MetricsRecorder interface {
+	RecordMessage(messageType string, version int, duration time.Duration, err error)
+}
+
+// MetricsMiddleware reports every message to recorder
+func MetricsMiddleware(recorder MetricsRecorder) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, envelope Envelope, message sqs.Message) error {
+			start := time.Now()
+			err := next(ctx, envelope, message)
+			recorder.RecordMessage(envelope.Type, envelope.Version, time.Since(start), err)
+			return err
+		}
+	}
+}
+
+// RecoveryMiddleware turns a panicking handler into a failed message, so it doesn't take
+// the worker down
+func RecoveryMiddleware() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, envelope Envelope, message sqs.Message) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Error.Printf("MessageRouter %s v%d message %s panicked: %v\n%s", envelope.Type, envelope.Version, envelope.ID, r, debug.Stack())
+					err = fmt.Errorf("handler panicked: %v", r)
+				}
+			}()
+			return next(ctx, envelope, message)
+		}
+	}
+}
+
+// dedupeRetryDelay is how long a message handled by another consumer is released for
+const dedupeRetryDelay = 30 * time.Second
+
+// DedupeMiddleware handles every message once, by envelope id or SQS MessageId when the
+// envelope has none, since SQS delivers messages at least once. Duplicates of a handled
+// message are deleted, and duplicates still being handled by another consumer released
+// for a while.
+func DedupeMiddleware(store dedupe.Store) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, envelope Envelope, message sqs.Message) error {
+			id := envelope.ID
+			if id == "" {
+				id = message.MessageID
+			}
+			err := dedupe.Once(ctx, store, id, func(ctx context.Context) error {
+				return next(ctx, envelope, message)
+			})
+			if errors.Is(err, dedupe.ErrInProgress) {
+				return Release(dedupeRetryDelay, err)
+			}
+			return err
+		}
+	}
+}
+
+// processorFunc adapts a function to MessageProcessor
+type processorFunc func(ctx context.Context, message sqs.Message) error
+
+func (f processorFunc) ProcessMessage(ctx context.Context, message sqs.Message) error {
+	return f(ctx, message)
+}
+
+// dedupeProcessor processes every message once through DedupeMiddleware, for processors
+// that don't read an envelope, so by MessageId
+func dedupeProcessor(store dedupe.Store, processor MessageProcessor) MessageProcessor {
+	handler := DedupeMiddleware(store)(func(ctx context.Context, envelope Envelope, message sqs.Message) error {
+		return processor.ProcessMessage(ctx, message)
+	})
+	return processorFunc(func(ctx context.Context, message sqs.Message) error {
+		return handler(ctx, Envelope{}, message)
+	})
+}
@@ -0,0 +1,160 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go-base/internal/pkg/aws/s3"
+	"go-base/internal/pkg/logger"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// MultipartCleanupWorker periodically aborts multipart uploads that were started but never
+// completed, so the uploaded parts stop being billed
+type MultipartCleanupWorker struct {
+	bucketNames []string
+	interval    time.Duration
+	maxAge      time.Duration
+	running     bool
+	stopChan    chan struct{}
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+	mu          sync.RWMutex
+}
+
+// MultipartCleanupWorkerConfig holds configuration for the multipart cleanup worker
+type MultipartCleanupWorkerConfig struct {
+	BucketNames []string
+	Interval    time.Duration // How often to scan the buckets
+	MaxAge      time.Duration // Uploads initiated longer ago than this are aborted
+}
+
+// NewMultipartCleanupWorker creates a new multipart cleanup worker
+func NewMultipartCleanupWorker(cfg MultipartCleanupWorkerConfig) (*MultipartCleanupWorker, error) {
+	if len(cfg.BucketNames) == 0 {
+		return nil, fmt.Errorf("at least one bucket name is required")
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Hour
+	}
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = 24 * time.Hour
+	}
+
+	return &MultipartCleanupWorker{
+		bucketNames: cfg.BucketNames,
+		interval:    cfg.Interval,
+		maxAge:      cfg.MaxAge,
+	}, nil
+}
+
+// Start starts the cleanup loop. A stopped worker can be started again.
+func (w *MultipartCleanupWorker) Start(ctx context.Context) {
+	w.mu.Lock()
+	if w.running {
+		w.mu.Unlock()
+		logger.Warn.Printf("Multipart cleanup worker for buckets %v is already running", w.bucketNames)
+		return
+	}
+	w.running = true
+	w.stopChan = make(chan struct{})
+	// Cancelled on Stop so a scan in progress doesn't hold up shutdown
+	ctx, w.cancel = context.WithCancel(ctx)
+	stopChan := w.stopChan
+	w.mu.Unlock()
+
+	logger.Info.Printf("Starting multipart cleanup worker for buckets %v, interval %s, max age %s", w.bucketNames, w.interval, w.maxAge)
+
+	w.wg.Add(1)
+	go w.loop(ctx, stopChan)
+}
+
+// Stop stops the cleanup loop, cancels a running scan and waits for it to return
+func (w *MultipartCleanupWorker) Stop() {
+	w.mu.Lock()
+	if !w.running {
+		w.mu.Unlock()
+		return
+	}
+	w.running = false
+	w.cancel()
+	close(w.stopChan)
+	w.mu.Unlock()
+
+	w.wg.Wait()
+
+	logger.Info.Printf("Multipart cleanup worker for buckets %v stopped", w.bucketNames)
+}
+
+// IsRunning returns true if the worker is currently running
+func (w *MultipartCleanupWorker) IsRunning() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.running
+}
+
+func (w *MultipartCleanupWorker) loop(ctx context.Context, stopChan chan struct{}) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.Cleanup(ctx)
+
+		select {
+		case <-stopChan:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Cleanup aborts every stale upload in the buckets once and returns how many were aborted
+func (w *MultipartCleanupWorker) Cleanup(ctx context.Context) int {
+	s3API := s3.GetInstance()
+	if s3API == nil {
+		logger.Warn.Printf("Multipart cleanup skipped, S3 instance not initialized")
+		return 0
+	}
+
+	aborted := 0
+	for _, bucketName := range w.bucketNames {
+		aborted += w.cleanupBucket(ctx, s3API, bucketName)
+	}
+	return aborted
+}
+
+func (w *MultipartCleanupWorker) cleanupBucket(ctx context.Context, s3API s3.S3API, bucketName string) int {
+	uploads, err := s3API.ListMultipartUploads(ctx, bucketName)
+	if err != nil {
+		logger.Error.Printf("Multipart cleanup failed to list uploads in bucket %s: %v", bucketName, err)
+		return 0
+	}
+
+	deadline := time.Now().Add(-w.maxAge)
+	aborted := 0
+	for _, upload := range uploads {
+		if upload.Initiated == nil || upload.Initiated.After(deadline) {
+			continue
+		}
+
+		key := aws.ToString(upload.Key)
+		uploadID := aws.ToString(upload.UploadId)
+		if err := s3API.AbortMultipartUpload(ctx, bucketName, key, uploadID); err != nil {
+			logger.Error.Printf("Multipart cleanup failed to abort upload %s of %s: %v", uploadID, key, err)
+			continue
+		}
+		aborted++
+	}
+
+	if aborted > 0 {
+		logger.Info.Printf("Multipart cleanup aborted %d stale uploads in bucket %s", aborted, bucketName)
+	}
+	return aborted
+}
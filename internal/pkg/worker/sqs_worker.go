@@ -2,20 +2,45 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
 	"go-base/internal/pkg/aws/sqs"
 	"go-base/internal/pkg/config"
+	"go-base/internal/pkg/dedupe"
 	"go-base/internal/pkg/logger"
 )
 
-// MessageProcessor defines the interface for processing SQS messages
+// MessageProcessor defines the interface for processing SQS messages. ctx is cancelled
+// when the worker can no longer keep the message hidden from other consumers.
 type MessageProcessor interface {
 	ProcessMessage(ctx context.Context, message sqs.Message) error
 }
 
+// ReleaseError is returned by a processor, through Release, to give a message back to
+// the queue without retrying it
+type ReleaseError struct {
+	Delay time.Duration // how long the message stays hidden before it is redelivered
+	Err   error
+}
+
+func (e *ReleaseError) Error() string {
+	return fmt.Sprintf("release message for %s: %v", e.Delay, e.Err)
+}
+
+func (e *ReleaseError) Unwrap() error {
+	return e.Err
+}
+
+// Release returns the error a processor gives back to release a message for deliberate
+// backoff, e.g. while a dependency is unavailable. The message is redelivered after delay
+// instead of being retried right away.
+func Release(delay time.Duration, err error) error {
+	return &ReleaseError{Delay: delay, Err: err}
+}
+
 // SQSWorker represents a worker that processes SQS messages
 type SQSWorker struct {
 	queueName    string
@@ -24,21 +49,49 @@ type SQSWorker struct {
 	pollInterval time.Duration
 	maxRetries   int
 	workerCount  int
-	running      bool
-	stopChan     chan struct{}
-	wg           sync.WaitGroup
-	mu           sync.RWMutex
+	batchSize    int32
+	pool         chan struct{} // bounds the messages processed at the same time across pollers
+	// visibilityTimeout is extended every heartbeatInterval while a message is processed
+	visibilityTimeout int32
+	heartbeatInterval time.Duration
+	// deadLetterQueue receives messages that failed on their maxReceiveCount-th delivery
+	deadLetterQueue sqs.SQSAPI
+	maxReceiveCount int
+	running         bool
+	stopChan        chan struct{}
+	stopPolling     context.CancelFunc // interrupts long polls on shutdown
+	cancel          context.CancelFunc // cancels the messages being processed when shutdown times out
+	wg              sync.WaitGroup
+	mu              sync.RWMutex
 }
 
 // SQSWorkerConfig holds configuration for SQS worker
 type SQSWorkerConfig struct {
 	QueueName    string
+	Queue        sqs.SQSAPI // Client of the queue, created for QueueName when nil
 	Processor    MessageProcessor
 	PollInterval time.Duration // How long to wait between polls when no messages
 	MaxRetries   int           // Maximum retries for failed message processing
-	WorkerCount  int           // Number of concurrent workers
+	WorkerCount  int           // Number of concurrent pollers
+	Concurrency  int           // Messages processed at the same time across pollers, defaults to BatchSize per poller
+	BatchSize    int32         // Messages received per poll, at most 10 and the free slots of Concurrency
+	// VisibilityTimeout hides received messages from other consumers, in seconds. It is
+	// extended every HeartbeatInterval, a third of it by default, while they are processed.
+	VisibilityTimeout int32
+	HeartbeatInterval time.Duration
+	// DeadLetterQueue receives messages that failed on their MaxReceiveCount-th delivery,
+	// 5 by default, and poison messages received more often than that are moved without
+	// being processed. Failed messages are only redelivered when it is nil.
+	DeadLetterQueue sqs.SQSAPI
+	MaxReceiveCount int
+	// DedupeStore processes every message once by its MessageId, like DedupeMiddleware.
+	// Redeliveries are processed again when it is nil.
+	DedupeStore dedupe.Store
 }
 
+// errPoisonMessage is recorded as the failure of messages moved without being processed
+var errPoisonMessage = errors.New("poison message")
+
 // NewSQSWorker creates a new SQS worker
 func NewSQSWorker(cfg SQSWorkerConfig) (*SQSWorker, error) {
 	if cfg.QueueName == "" {
@@ -56,23 +109,51 @@ func NewSQSWorker(cfg SQSWorkerConfig) (*SQSWorker, error) {
 	if cfg.WorkerCount <= 0 {
 		cfg.WorkerCount = 1
 	}
+	if cfg.BatchSize <= 0 || cfg.BatchSize > sqs.MaxBatchEntries {
+		cfg.BatchSize = sqs.MaxBatchEntries
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = cfg.WorkerCount * int(cfg.BatchSize)
+	}
+	if cfg.VisibilityTimeout <= 0 {
+		cfg.VisibilityTimeout = 300
+	}
+	if cfg.HeartbeatInterval <= 0 {
+		cfg.HeartbeatInterval = time.Duration(cfg.VisibilityTimeout) * time.Second / 3
+	}
+	if cfg.MaxReceiveCount <= 0 {
+		cfg.MaxReceiveCount = 5
+	}
+	if cfg.DedupeStore != nil {
+		cfg.Processor = dedupeProcessor(cfg.DedupeStore, cfg.Processor)
+	}
 
-	sqsManager, err := sqs.NewBaseManager(sqs.Config{
-		QueueName: cfg.QueueName,
-		Region:    config.Env.AWSSQSRegion,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create SQS manager: %w", err)
+	if cfg.Queue == nil {
+		sqsManager, err := sqs.NewBaseManager(sqs.Config{
+			QueueName: cfg.QueueName,
+			Region:    config.Env.AWSSQSRegion,
+			Endpoint:  config.Env.SQSEndpoint(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create SQS manager: %w", err)
+		}
+		cfg.Queue = &sqsManager
 	}
 
 	return &SQSWorker{
-		queueName:    cfg.QueueName,
-		sqsManager:   &sqsManager,
-		processor:    cfg.Processor,
-		pollInterval: cfg.PollInterval,
-		maxRetries:   cfg.MaxRetries,
-		workerCount:  cfg.WorkerCount,
-		stopChan:     make(chan struct{}),
+		queueName:         cfg.QueueName,
+		sqsManager:        cfg.Queue,
+		processor:         cfg.Processor,
+		pollInterval:      cfg.PollInterval,
+		maxRetries:        cfg.MaxRetries,
+		workerCount:       cfg.WorkerCount,
+		batchSize:         cfg.BatchSize,
+		pool:              make(chan struct{}, cfg.Concurrency),
+		visibilityTimeout: cfg.VisibilityTimeout,
+		heartbeatInterval: cfg.HeartbeatInterval,
+		deadLetterQueue:   cfg.DeadLetterQueue,
+		maxReceiveCount:   cfg.MaxReceiveCount,
+		stopChan:          make(chan struct{}),
 	}, nil
 }
 
@@ -85,23 +166,33 @@ func (w *SQSWorker) Start(ctx context.Context) {
 		return
 	}
 	w.running = true
+	ctx, w.cancel = context.WithCancel(ctx)
+	pollCtx, stopPolling := context.WithCancel(ctx)
+	w.stopPolling = stopPolling
 	w.mu.Unlock()
 
-	logger.Info.Printf("Starting SQS worker for queue %s with %d workers", w.queueName, w.workerCount)
+	logger.Info.Printf("Starting SQS worker for queue %s with %d pollers, concurrency %d", w.queueName, w.workerCount, cap(w.pool))
 
 	// Start multiple worker goroutines
 	for i := 0; i < w.workerCount; i++ {
 		w.wg.Add(1)
-		go w.workerLoop(ctx, i)
+		go w.workerLoop(ctx, pollCtx, i)
 	}
 }
 
-// Stop stops the SQS worker gracefully
+// Stop stops the SQS worker gracefully, waiting for the messages being processed
 func (w *SQSWorker) Stop() {
+	w.Shutdown(context.Background())
+}
+
+// Shutdown stops polling and waits for the messages being processed until ctx is done.
+// Then their processing is cancelled and ctx.Err() returned; the messages that weren't
+// processed become visible again after their visibility timeout.
+func (w *SQSWorker) Shutdown(ctx context.Context) error {
 	w.mu.Lock()
 	if !w.running {
 		w.mu.Unlock()
-		return
+		return nil
 	}
 	w.running = false
 	w.mu.Unlock()
@@ -110,11 +201,27 @@ func (w *SQSWorker) Stop() {
 
 	// Signal all workers to stop
 	close(w.stopChan)
+	w.stopPolling()
 
 	// Wait for all workers to finish
-	w.wg.Wait()
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		logger.Warn.Printf("SQS worker for queue %s didn't drain in time, cancelling the messages being processed", w.queueName)
+		err = ctx.Err()
+		w.cancel()
+		<-done
+	}
+	w.cancel()
 
 	logger.Info.Printf("SQS worker for queue %s stopped", w.queueName)
+	return err
 }
 
 // IsRunning returns true if the worker is currently running
@@ -125,7 +232,7 @@ func (w *SQSWorker) IsRunning() bool {
 }
 
 // workerLoop is the main loop for each worker goroutine
-func (w *SQSWorker) workerLoop(ctx context.Context, workerID int) {
+func (w *SQSWorker) workerLoop(ctx, pollCtx context.Context, workerID int) {
 	defer w.wg.Done()
 
 	logger.Info.Printf("SQS worker %d started for queue %s", workerID, w.queueName)
@@ -139,50 +246,208 @@ func (w *SQSWorker) workerLoop(ctx context.Context, workerID int) {
 			logger.Info.Printf("SQS worker %d context cancelled for queue %s", workerID, w.queueName)
 			return
 		default:
-			w.processMessages(ctx, workerID)
+			w.processMessages(ctx, pollCtx, workerID)
 		}
 	}
 }
 
-// processMessages polls for and processes messages
-func (w *SQSWorker) processMessages(ctx context.Context, workerID int) {
+// processMessages reserves free pool slots, receives at most that many messages and
+// hands each one to its own goroutine, which keeps it hidden while it is processed and
+// settles it as soon as it finishes. pollCtx is cancelled to interrupt the poll, ctx to
+// cancel the processing.
+func (w *SQSWorker) processMessages(ctx, pollCtx context.Context, workerID int) {
+	// Only receive what can be processed right away, so no message waits for a slot
+	// while its visibility timeout runs out
+	slots := w.reserve(pollCtx)
+	if slots == 0 {
+		return
+	}
+
 	// Poll for messages with long polling (20 seconds max)
-	hasMessage, message, err := w.sqsManager.ReceiveMessage(ctx, 20, 300) // 20s wait, 5min visibility timeout
+	messages, err := w.sqsManager.ReceiveMessages(pollCtx, int32(slots), 20, w.visibilityTimeout) // 20s wait
+	if pollCtx.Err() != nil {
+		w.release(slots)
+		return
+	}
 	if err != nil {
-		logger.Error.Printf("SQS worker %d failed to receive message from queue %s: %v", workerID, w.queueName, err)
-		time.Sleep(w.pollInterval)
+		w.release(slots)
+		logger.Error.Printf("SQS worker %d failed to receive messages from queue %s: %v", workerID, w.queueName, err)
+		w.wait(ctx, w.pollInterval)
 		return
 	}
+	w.release(slots - len(messages))
 
-	if !hasMessage {
+	if len(messages) == 0 {
 		// No messages available, short sleep before next poll
-		time.Sleep(1 * time.Second)
+		w.wait(ctx, 1*time.Second)
 		return
 	}
 
-	logger.Info.Printf("SQS worker %d received message from queue %s", workerID, w.queueName)
+	logger.Info.Printf("SQS worker %d received %d messages from queue %s", workerID, len(messages), w.queueName)
 
-	// Process the message with retries
-	if w.processMessageWithRetries(ctx, message, workerID) {
-		// Successfully processed, delete the message
-		err = w.sqsManager.DeleteMessage(ctx, message.ReceiptHandle)
-		if err != nil {
-			logger.Error.Printf("SQS worker %d failed to delete message from queue %s: %v", workerID, w.queueName, err)
-		} else {
-			logger.Info.Printf("SQS worker %d successfully processed and deleted message from queue %s", workerID, w.queueName)
+	for _, message := range messages {
+		w.wg.Add(1)
+		go func(message sqs.Message) {
+			defer func() {
+				w.release(1)
+				w.wg.Done()
+			}()
+			w.handleMessage(ctx, message, workerID)
+		}(message)
+	}
+}
+
+// reserve waits for a free pool slot and takes up to batchSize of them, 0 when ctx is
+// done first
+func (w *SQSWorker) reserve(ctx context.Context) int {
+	select {
+	case w.pool <- struct{}{}:
+	case <-ctx.Done():
+		return 0
+	}
+
+	slots := 1
+	for slots < int(w.batchSize) {
+		select {
+		case w.pool <- struct{}{}:
+			slots++
+		default:
+			return slots
 		}
+	}
+	return slots
+}
+
+// release gives back slots taken by reserve
+func (w *SQSWorker) release(slots int) {
+	for i := 0; i < slots; i++ {
+		<-w.pool
+	}
+}
+
+// handleMessage processes a message, unless it is poison, and settles it right away: it is
+// deleted when it was processed or moved to the dead-letter queue, the others become
+// visible again after the visibility timeout expires
+func (w *SQSWorker) handleMessage(ctx context.Context, message sqs.Message, workerID int) {
+	var err error
+	if w.isPoison(message) {
+		err = fmt.Errorf("%w: received %d times, more than %d", errPoisonMessage, message.ReceiveCount, w.maxReceiveCount)
 	} else {
-		logger.Error.Printf("SQS worker %d failed to process message from queue %s after %d retries", workerID, w.queueName, w.maxRetries)
-		// Message will become visible again after visibility timeout expires
+		err = w.processMessage(ctx, message, workerID)
+	}
+
+	// Settle the message even when processing was cancelled, so a finished one isn't redelivered
+	settleCtx := context.WithoutCancel(ctx)
+	if !w.moveToDeadLetterQueue(settleCtx, message, err, workerID) {
+		return
+	}
+	if err := w.sqsManager.DeleteMessage(settleCtx, message.ReceiptHandle); err != nil {
+		logger.Error.Printf("SQS worker %d failed to delete message %s from queue %s: %v", workerID, message.MessageID, w.queueName, err)
+	}
+}
+
+// isPoison reports whether a message was delivered more often than it should have been
+// tried, e.g. because it keeps crashing or timing out its processor
+func (w *SQSWorker) isPoison(message sqs.Message) bool {
+	return w.deadLetterQueue != nil && message.ReceiveCount > w.maxReceiveCount
+}
+
+// moveToDeadLetterQueue sends a message that failed on its last allowed delivery, or a
+// malformed one on any delivery, to the dead-letter queue with the failure, and reports
+// whether the message can be deleted: when it was processed or moved. Released and
+// cancelled messages are never moved.
+func (w *SQSWorker) moveToDeadLetterQueue(ctx context.Context, message sqs.Message, err error, workerID int) bool {
+	var release *ReleaseError
+	switch {
+	case err == nil:
+		return true
+	case errors.As(err, &release):
+		return false
+	case errors.Is(err, context.Canceled):
+		// Interrupted, not failed: shutdown or a lost visibility extension
+		logger.Warn.Printf("SQS worker %d left message %s in queue %s, processing was cancelled", workerID, message.MessageID, w.queueName)
+		return false
+	case w.deadLetterQueue != nil && (message.ReceiveCount >= w.maxReceiveCount || errors.Is(err, ErrMalformedMessage)):
+		if _, sendErr := w.deadLetterQueue.SendMessage(ctx, sqs.DeadLetter(message, w.queueName, err)); sendErr != nil {
+			logger.Error.Printf("SQS worker %d failed to move message %s from queue %s to the dead-letter queue: %v", workerID, message.MessageID, w.queueName, sendErr)
+			return false
+		}
+		logger.Warn.Printf("SQS worker %d moved message %s from queue %s to the dead-letter queue after %d deliveries: %v", workerID, message.MessageID, w.queueName, message.ReceiveCount, err)
+		return true
+	default:
+		logger.Error.Printf("SQS worker %d left message %s in queue %s unprocessed: %v", workerID, message.MessageID, w.queueName, err)
+		return false
+	}
+}
+
+// wait sleeps for d, returning early when the worker stops
+func (w *SQSWorker) wait(ctx context.Context, d time.Duration) {
+	select {
+	case <-w.stopChan:
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
+
+// processMessage processes a message while a heartbeat keeps it hidden and returns why it
+// failed. A released message is made visible again after its delay.
+func (w *SQSWorker) processMessage(ctx context.Context, message sqs.Message, workerID int) error {
+	messageCtx, cancel := context.WithCancel(ctx)
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		w.heartbeat(messageCtx, cancel, message, workerID)
+	}()
+
+	err := w.processMessageWithRetries(messageCtx, message, workerID)
+	cancel()
+	<-heartbeatDone // so the heartbeat can't override the release below
+
+	var release *ReleaseError
+	if errors.As(err, &release) {
+		delay := int32(release.Delay / time.Second)
+		logger.Info.Printf("SQS worker %d released message %s from queue %s for %ds: %v", workerID, message.MessageID, w.queueName, delay, release.Err)
+		if err := w.sqsManager.ChangeMessageVisibility(ctx, message.ReceiptHandle, delay); err != nil {
+			logger.Error.Printf("SQS worker %d failed to release message %s from queue %s: %v", workerID, message.MessageID, w.queueName, err)
+		}
+	}
+	return err
+}
+
+// heartbeat extends the visibility timeout of a message until ctx is done. When an
+// extension fails the message may already be redelivered, so processing is cancelled.
+func (w *SQSWorker) heartbeat(ctx context.Context, cancel context.CancelFunc, message sqs.Message, workerID int) {
+	ticker := time.NewTicker(w.heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := w.sqsManager.ChangeMessageVisibility(ctx, message.ReceiptHandle, w.visibilityTimeout)
+			if err != nil && ctx.Err() == nil {
+				logger.Error.Printf("SQS worker %d failed to extend visibility of message %s from queue %s, cancelling: %v", workerID, message.MessageID, w.queueName, err)
+				cancel()
+				return
+			}
+		}
 	}
 }
 
-// processMessageWithRetries processes a message with retry logic
-func (w *SQSWorker) processMessageWithRetries(ctx context.Context, message sqs.Message, workerID int) bool {
+// processMessageWithRetries processes a message with retry logic. Released and malformed
+// messages and cancelled contexts aren't retried.
+func (w *SQSWorker) processMessageWithRetries(ctx context.Context, message sqs.Message, workerID int) error {
+	var err error
 	for attempt := 1; attempt <= w.maxRetries; attempt++ {
-		err := w.processor.ProcessMessage(ctx, message)
+		err = w.processor.ProcessMessage(ctx, message)
 		if err == nil {
-			return true
+			return nil
+		}
+
+		var release *ReleaseError
+		if errors.As(err, &release) || errors.Is(err, ErrMalformedMessage) {
+			return err
 		}
 
 		logger.Warn.Printf("SQS worker %d failed to process message (attempt %d/%d) from queue %s: %v",
@@ -191,10 +456,14 @@ func (w *SQSWorker) processMessageWithRetries(ctx context.Context, message sqs.M
 		if attempt < w.maxRetries {
 			// Wait before retry with exponential backoff
 			backoffDuration := time.Duration(attempt) * time.Second
-			time.Sleep(backoffDuration)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoffDuration):
+			}
 		}
 	}
-	return false
+	return err
 }
 
 // DefaultMessageProcessor is a simple implementation of MessageProcessor for demonstration
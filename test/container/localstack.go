@@ -0,0 +1,65 @@
+package container
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	LocalStackRegion          = "us-east-1"
+	LocalStackAccessKeyID     = "test"
+	LocalStackSecretAccessKey = "test"
+)
+
+type LocalStackContainer struct {
+	testcontainers.Container
+	URI string
+}
+
+func SetupLocalStack(ctx context.Context) (*LocalStackContainer, error) {
+
+	req := testcontainers.ContainerRequest{
+		Image:        "localstack/localstack:3.8",
+		ExposedPorts: []string{"4566/tcp"},
+		Env: map[string]string{
+			"SERVICES": "s3,sqs",
+		},
+		WaitingFor: wait.ForLog("Ready."),
+	}
+
+	container, genericContainerErr := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if genericContainerErr != nil {
+		return nil, genericContainerErr
+	}
+
+	mappedPort, mappedPortErr := container.MappedPort(ctx, "4566")
+	if mappedPortErr != nil {
+		return nil, mappedPortErr
+	}
+
+	hostIP, hostErr := container.Host(ctx)
+	if hostErr != nil {
+		return nil, hostErr
+	}
+
+	uri := fmt.Sprintf("http://%s:%s", hostIP, mappedPort.Port())
+	return &LocalStackContainer{Container: container, URI: uri}, nil
+}
+
+// CreateQueue creates an SQS queue inside the container
+func (c *LocalStackContainer) CreateQueue(ctx context.Context, queueName string) error {
+	exitCode, err := c.Exec(ctx, []string{"awslocal", "sqs", "create-queue", "--queue-name", queueName})
+	if err != nil {
+		return err
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("create queue %s exited with %d", queueName, exitCode)
+	}
+	return nil
+}
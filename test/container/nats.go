@@ -0,0 +1,69 @@
+package container
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+)
+
+type NatsServer struct {
+	*server.Server
+	URI     string
+	options *server.Options
+}
+
+// SetupNats starts an embedded NATS server with JetStream on a random port, storing the
+// streams in storeDir
+func SetupNats(storeDir string) (*NatsServer, error) {
+
+	natsServer, err := startNats(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  storeDir,
+		NoSigs:    true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return natsServer, nil
+}
+
+// Restart stops the server and starts it again on the same port, e.g. to test reconnects
+func (s *NatsServer) Restart() error {
+	s.Terminate()
+
+	restarted, err := startNats(s.options)
+	if err != nil {
+		return err
+	}
+	*s = *restarted
+	return nil
+}
+
+// Terminate stops the server
+func (s *NatsServer) Terminate() {
+	s.Shutdown()
+	s.WaitForShutdown()
+}
+
+func startNats(options *server.Options) (*NatsServer, error) {
+	natsServer, err := server.NewServer(options)
+	if err != nil {
+		return nil, err
+	}
+
+	go natsServer.Start()
+	if !natsServer.ReadyForConnections(10 * time.Second) {
+		natsServer.Shutdown()
+		return nil, errors.New("nats server not ready for connections")
+	}
+
+	// restarts listen on the same port
+	started := *options
+	started.Port = natsServer.Addr().(*net.TCPAddr).Port
+	return &NatsServer{Server: natsServer, URI: natsServer.ClientURL(), options: &started}, nil
+}
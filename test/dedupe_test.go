@@ -0,0 +1,123 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go-base/internal/pkg/cache"
+	"go-base/internal/pkg/dedupe"
+	"go-base/internal/pkg/queue"
+	"go-base/test/container"
+
+	"github.com/nats-io/nats.go"
+)
+
+var _ dedupe.Cache = (*cache.Manager)(nil)
+
+func Test_Dedupe_Once(t *testing.T) {
+	stores := map[string]func(t *testing.T) dedupe.Store{
+		"memory": func(t *testing.T) dedupe.Store {
+			return dedupe.NewMemoryStore(dedupe.Config{})
+		},
+		// 沒有 Docker 時略過
+		"redis": func(t *testing.T) dedupe.Store {
+			if testing.Short() {
+				t.Skip("skipping Redis in short mode")
+			}
+			ctx := context.Background()
+			redisContainer, err := container.SetupRedis(ctx)
+			if err != nil {
+				t.Skipf("Redis unavailable, %v", err)
+			}
+			t.Cleanup(func() { redisContainer.Terminate(ctx) }) // 清理
+
+			manager := &cache.Manager{}
+			if err := manager.Setup(cache.Config{
+				Type:         "redis_default",
+				EndpointList: []string{strings.TrimPrefix(redisContainer.URI, "redis://")},
+			}); err != nil {
+				t.Fatalf("failed to connect to Redis: %v", err)
+			}
+			t.Cleanup(func() { manager.Close() }) // 清理
+			return dedupe.NewCacheStore(manager, dedupe.Config{Prefix: "test:", InProgressTTL: time.Minute, DoneTTL: time.Minute})
+		},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			ctx := context.Background()
+			calls := 0
+			handle := func(err error) func(ctx context.Context) error {
+				return func(ctx context.Context) error {
+					calls++
+					return err
+				}
+			}
+
+			if err := dedupe.Once(ctx, store, "event-1", handle(nil)); err != nil || calls != 1 {
+				t.Fatalf("expected the event to be handled, got calls=%d err=%v", calls, err)
+			}
+			// 已處理過的 event 不再處理
+			if err := dedupe.Once(ctx, store, "event-1", handle(nil)); err != nil || calls != 1 {
+				t.Errorf("expected the duplicate to be skipped, got calls=%d err=%v", calls, err)
+			}
+
+			// 失敗時釋放，重送可以再處理
+			failure := errors.New("temporary failure")
+			if err := dedupe.Once(ctx, store, "event-2", handle(failure)); !errors.Is(err, failure) {
+				t.Errorf("expected the failure, got %v", err)
+			}
+			if err := dedupe.Once(ctx, store, "event-2", handle(nil)); err != nil || calls != 3 {
+				t.Errorf("expected the redelivery to be handled, got calls=%d err=%v", calls, err)
+			}
+
+			// 另一個 consumer 處理中
+			if status, err := store.Claim(ctx, "event-3"); err != nil || status != dedupe.Claimed {
+				t.Fatalf("expected to claim the event, got %v %v", status, err)
+			}
+			if err := dedupe.Once(ctx, store, "event-3", handle(nil)); !errors.Is(err, dedupe.ErrInProgress) || calls != 3 {
+				t.Errorf("expected ErrInProgress, got calls=%d err=%v", calls, err)
+			}
+			if status, err := store.Claim(ctx, "event-1"); err != nil || status != dedupe.Done {
+				t.Errorf("expected the handled event to be done, got %v %v", status, err)
+			}
+		})
+	}
+}
+
+func Test_Queue_DedupeMiddleware(t *testing.T) {
+	var handled []string
+	handler := queue.Chain(func(ctx context.Context, msg *nats.Msg) error {
+		handled = append(handled, string(msg.Data))
+		return nil
+	}, queue.DedupeMiddleware(dedupe.NewMemoryStore(dedupe.Config{})))
+
+	newMsg := func(data string, header nats.Header) *nats.Msg {
+		msg := nats.NewMsg("events.created")
+		msg.Data = []byte(data)
+		msg.Header = header
+		return msg
+	}
+	messages := []*nats.Msg{
+		newMsg("first", nats.Header{queue.EventIDHeader: []string{"event-1"}}),
+		newMsg("first again", nats.Header{queue.EventIDHeader: []string{"event-1"}}),
+		newMsg("jetstream", nats.Header{nats.MsgIdHdr: []string{"event-2"}}),
+		newMsg("jetstream again", nats.Header{nats.MsgIdHdr: []string{"event-2"}}),
+		newMsg("no id", nil),
+		newMsg("no id", nil),
+	}
+	for _, msg := range messages {
+		if err := handler(context.Background(), msg); err != nil {
+			t.Errorf("failed to handle %s: %v", msg.Data, err)
+		}
+	}
+
+	want := []string{"first", "jetstream", "no id", "no id"}
+	if strings.Join(handled, ",") != strings.Join(want, ",") {
+		t.Errorf("expected %v, got %v", want, handled)
+	}
+}
@@ -0,0 +1,179 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-base/internal/app/router"
+	"go-base/internal/pkg/aws/s3"
+	"go-base/internal/pkg/job"
+	modelHttp "go-base/internal/pkg/model/http"
+
+	"github.com/aws/smithy-go"
+)
+
+func putObjects(mockS3 *s3.MockS3API, bucketName string, prefix string, count int) []string {
+	keys := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		key := fmt.Sprintf("%sfile-%05d.txt", prefix, i)
+		mockS3.PutObject(bucketName, key, []byte("data"), "text/plain")
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func Test_DeleteObjectsFromBucket_More_Than_1000_Keys(t *testing.T) {
+	mockS3 := &s3.MockS3API{}
+	keys := putObjects(mockS3, "delete-bucket", "logs/", 2500)
+	mockS3.FailKey("logs/file-01234.txt", &smithy.GenericAPIError{Code: "AccessDenied", Message: "Access Denied"})
+	s3.SetInstance(mockS3)
+	defer s3.SetInstance(nil) // 清理
+
+	w, _ := HttpSendAndMarshalBody(http.MethodDelete, "/s3/delete-objects-from-bucket", map[string]interface{}{
+		"bucket_name": "delete-bucket",
+		"object_keys": keys,
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+
+	var response modelHttp.DeleteObjectsFromBucketResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v, body=%s", err, w.Body.String())
+	}
+	// 部分失敗時 success 為 false，且只計算真正刪除的數量
+	if response.Success || response.DeletedCount != 2499 || len(response.Deleted) != 2499 {
+		t.Errorf("expected 2499 deleted and success=false, got success=%v, deleted_count=%d", response.Success, response.DeletedCount)
+	}
+	if len(response.Errors) != 1 || response.Errors[0].Key != "logs/file-01234.txt" || response.Errors[0].Code != "AccessDenied" {
+		t.Errorf("unexpected errors: %+v", response.Errors)
+	}
+	if _, ok := mockS3.Object("delete-bucket", "logs/file-01234.txt"); !ok {
+		t.Error("expected the failed key to still exist")
+	}
+	if _, ok := mockS3.Object("delete-bucket", "logs/file-02000.txt"); ok {
+		t.Error("expected keys past the first batch to be deleted")
+	}
+}
+
+func Test_DeleteObjects_Uses_Delete_With_JSON_Body(t *testing.T) {
+	mockS3 := &s3.MockS3API{}
+	mockS3.PutObject("icon-bucket", "icons/a.png", []byte("png"), "image/png")
+	s3.SetInstance(mockS3)
+	defer s3.SetInstance(nil) // 清理
+
+	w, _ := HttpSendAndMarshalBody(http.MethodDelete, "/s3/delete-objects", map[string]interface{}{
+		"bucket_name": "icon-bucket",
+		"keys":        []string{"icons/a.png"},
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+	var response modelHttp.GetIconDeleteObjectsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v, body=%s", err, w.Body.String())
+	}
+	if !response.Success || response.DeletedCount != 1 {
+		t.Errorf("unexpected response: %+v", response)
+	}
+
+	// 不再接受 GET
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/s3/delete-objects?bucket_name=icon-bucket&keys=icons/a.png", nil)
+	router.Router.ServeHTTP(w, req)
+	if w.Code == http.StatusOK {
+		t.Errorf("expected GET to be rejected, got %d", w.Code)
+	}
+}
+
+func Test_DeleteObjectsByPrefix_Small_Prefix(t *testing.T) {
+	mockS3 := &s3.MockS3API{}
+	putObjects(mockS3, "delete-bucket", "tmp/", 3)
+	mockS3.PutObject("delete-bucket", "keep/a.txt", []byte("data"), "text/plain")
+	s3.SetInstance(mockS3)
+	defer s3.SetInstance(nil) // 清理
+
+	w, _ := HttpSendAndMarshalBody(http.MethodDelete, "/s3/delete-objects-by-prefix", map[string]string{
+		"bucket_name": "delete-bucket",
+		"prefix":      "tmp/",
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+	var response modelHttp.DeleteObjectsByPrefixResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v, body=%s", err, w.Body.String())
+	}
+	if response.JobID != "" || !response.Success || response.DeletedCount != 3 {
+		t.Errorf("unexpected response: %+v", response)
+	}
+	if _, ok := mockS3.Object("delete-bucket", "keep/a.txt"); !ok {
+		t.Error("expected objects outside the prefix to be kept")
+	}
+}
+
+func Test_DeleteObjectsByPrefix_Large_Prefix_Runs_As_Job(t *testing.T) {
+	mockS3 := &s3.MockS3API{}
+	putObjects(mockS3, "delete-bucket", "exports/2024/", 5500)
+	mockS3.PutObject("delete-bucket", "exports/2025/a.csv", []byte("data"), "text/csv")
+	s3.SetInstance(mockS3)
+	defer s3.SetInstance(nil) // 清理
+
+	w, _ := HttpSendAndMarshalBody(http.MethodDelete, "/s3/delete-objects-by-prefix", map[string]string{
+		"bucket_name": "delete-bucket",
+		"prefix":      "exports/2024/",
+	}, nil)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d, body=%s", w.Code, w.Body.String())
+	}
+	var response modelHttp.DeleteObjectsByPrefixResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v, body=%s", err, w.Body.String())
+	}
+	if response.JobID == "" {
+		t.Fatalf("expected a job id, got: %+v", response)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, ok := job.Wait(ctx, response.JobID); !ok {
+		t.Fatalf("job %s not found", response.JobID)
+	}
+
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/s3/jobs/"+response.JobID, nil)
+	router.Router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+	var status modelHttp.GetJobResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatalf("failed to parse response: %v, body=%s", err, w.Body.String())
+	}
+	if status.Status != string(job.StatusSucceeded) || status.Total != 5500 || status.Processed != 5500 || status.Failed != 0 {
+		t.Errorf("unexpected job status: %+v", status)
+	}
+
+	remaining, err := mockS3.ListObjects(context.Background(), "delete-bucket", "exports/", "", "", 1000)
+	if err != nil {
+		t.Fatalf("failed to list objects: %v", err)
+	}
+	if len(remaining.Objects) != 1 || remaining.Objects[0].Key != "exports/2025/a.csv" {
+		t.Errorf("expected only exports/2025/a.csv to remain, got: %+v", remaining.Objects)
+	}
+}
+
+func Test_GetJob_Not_Found(t *testing.T) {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/s3/jobs/unknown-job", nil)
+	router.Router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d, body=%s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,172 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-base/internal/app/router"
+	"go-base/internal/pkg/aws/s3"
+)
+
+func setupDownloadMockS3() *s3.MockS3API {
+	mockS3 := &s3.MockS3API{}
+	mockS3.PutObject("download-bucket", "files/report.txt", []byte("0123456789abcdef"), "text/plain")
+	s3.SetInstance(mockS3)
+	return mockS3
+}
+
+func Test_DownloadLargeObject_Success(t *testing.T) {
+	setupDownloadMockS3()
+	defer s3.SetInstance(nil) // 清理
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/s3/download-large-object?bucket_name=download-bucket&object_key=files/report.txt", nil)
+
+	router.Router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+	if w.Body.String() != "0123456789abcdef" {
+		t.Errorf("expected raw object body, got: %s", w.Body.String())
+	}
+	if got := w.Header().Get("Content-Type"); got != "text/plain" {
+		t.Errorf("expected Content-Type text/plain, got: %s", got)
+	}
+	if got := w.Header().Get("Content-Length"); got != "16" {
+		t.Errorf("expected Content-Length 16, got: %s", got)
+	}
+	if w.Header().Get("ETag") == "" || w.Header().Get("Last-Modified") == "" {
+		t.Errorf("expected ETag and Last-Modified headers, got: %v", w.Header())
+	}
+	if got := w.Header().Get("Content-Disposition"); got != "attachment; filename=report.txt" {
+		t.Errorf("unexpected Content-Disposition: %s", got)
+	}
+}
+
+func Test_DownloadLargeObject_FileName(t *testing.T) {
+	setupDownloadMockS3()
+	defer s3.SetInstance(nil) // 清理
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/s3/download-large-object?bucket_name=download-bucket&object_key=files/report.txt&file_name=my%20report.txt", nil)
+
+	router.Router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="my report.txt"` {
+		t.Errorf("unexpected Content-Disposition: %s", got)
+	}
+}
+
+func Test_DownloadLargeObject_Range(t *testing.T) {
+	setupDownloadMockS3()
+	defer s3.SetInstance(nil) // 清理
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/s3/download-large-object?bucket_name=download-bucket&object_key=files/report.txt", nil)
+	req.Header.Set("Range", "bytes=4-7")
+
+	router.Router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusPartialContent {
+		t.Fatalf("expected 206, got %d, body=%s", w.Code, w.Body.String())
+	}
+	if w.Body.String() != "4567" {
+		t.Errorf("expected ranged body 4567, got: %s", w.Body.String())
+	}
+	if got := w.Header().Get("Content-Range"); got != "bytes 4-7/16" {
+		t.Errorf("unexpected Content-Range: %s", got)
+	}
+}
+
+func Test_DownloadLargeObject_Invalid_Range(t *testing.T) {
+	setupDownloadMockS3()
+	defer s3.SetInstance(nil) // 清理
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/s3/download-large-object?bucket_name=download-bucket&object_key=files/report.txt", nil)
+	req.Header.Set("Range", "bytes=100-200")
+
+	router.Router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestedRangeNotSatisfiable {
+		t.Fatalf("expected 416, got %d, body=%s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "4003") { // S3InvalidRange
+		t.Errorf("expected error code 4003, got body: %s", w.Body.String())
+	}
+}
+
+func Test_DownloadLargeObject_Not_Modified(t *testing.T) {
+	setupDownloadMockS3()
+	defer s3.SetInstance(nil) // 清理
+
+	// 先取得 ETag
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/s3/download-large-object?bucket_name=download-bucket&object_key=files/report.txt", nil)
+	router.Router.ServeHTTP(w, req)
+	etag := w.Header().Get("ETag")
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/s3/download-large-object?bucket_name=download-bucket&object_key=files/report.txt", nil)
+	req.Header.Set("If-None-Match", `"stale", `+etag)
+
+	router.Router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("expected 304, got %d, body=%s", w.Code, w.Body.String())
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("expected empty body, got: %s", w.Body.String())
+	}
+	// 回傳物件目前的 ETag，不是 request 帶的清單
+	if got := w.Header().Get("ETag"); got != etag {
+		t.Errorf("expected ETag %s, got %s", etag, got)
+	}
+
+	// * 符合任何版本
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/s3/download-large-object?bucket_name=download-bucket&object_key=files/report.txt", nil)
+	req.Header.Set("If-None-Match", "*")
+	router.Router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified || w.Header().Get("ETag") != etag {
+		t.Errorf("expected 304 with ETag %s, got %d %s", etag, w.Code, w.Header().Get("ETag"))
+	}
+}
+
+func Test_DownloadLargeObject_Not_Found(t *testing.T) {
+	setupDownloadMockS3()
+	defer s3.SetInstance(nil) // 清理
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/s3/download-large-object?bucket_name=download-bucket&object_key=files/missing.txt", nil)
+
+	router.Router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d, body=%s", w.Code, w.Body.String())
+	}
+}
+
+func Test_DownloadLargeObject_S3_Error(t *testing.T) {
+	mockS3 := setupDownloadMockS3()
+	mockS3.FailOn("GetObjectStream", errMockS3)
+	defer s3.SetInstance(nil) // 清理
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/s3/download-large-object?bucket_name=download-bucket&object_key=files/report.txt", nil)
+
+	router.Router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d, body=%s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "4001") { // S3GetObjectFail
+		t.Errorf("expected error code 4001, got body: %s", w.Body.String())
+	}
+}
@@ -17,7 +17,7 @@ func Test_GetIconPresignedURL_Success_GET(t *testing.T) {
 	defer s3.SetInstance(nil) // 清理
 
 	w := httptest.NewRecorder()
-	req := httptest.NewRequest(http.MethodGet, "/icon/presigned-url?key=test/icon.png&method=GET", nil)
+	req := httptest.NewRequest(http.MethodGet, "/s3/presigned-url?key=test/icon.png&method=GET", nil)
 
 	router.Router.ServeHTTP(w, req)
 
@@ -42,7 +42,7 @@ func Test_GetIconPresignedURL_Success_PUT(t *testing.T) {
 	defer s3.SetInstance(nil) // 清理
 
 	w := httptest.NewRecorder()
-	req := httptest.NewRequest(http.MethodGet, "/icon/presigned-url?key=test/icon.png&method=PUT&content_type=image/png", nil)
+	req := httptest.NewRequest(http.MethodGet, "/s3/presigned-url?key=test/icon.png&method=PUT&content_type=image/png", nil)
 
 	router.Router.ServeHTTP(w, req)
 
@@ -70,7 +70,7 @@ func Test_GetIconPresignedURL_Invalid_Method(t *testing.T) {
 	defer s3.SetInstance(nil) // 清理
 
 	w := httptest.NewRecorder()
-	req := httptest.NewRequest(http.MethodGet, "/icon/presigned-url?key=test/icon.png&method=DELETE", nil)
+	req := httptest.NewRequest(http.MethodGet, "/s3/presigned-url?key=test/icon.png&method=DELETE", nil)
 
 	router.Router.ServeHTTP(w, req)
 
@@ -92,7 +92,7 @@ func Test_GetIconPresignedURL_Missing_Key(t *testing.T) {
 	defer s3.SetInstance(nil) // 清理
 
 	w := httptest.NewRecorder()
-	req := httptest.NewRequest(http.MethodGet, "/icon/presigned-url?method=GET", nil)
+	req := httptest.NewRequest(http.MethodGet, "/s3/presigned-url?method=GET", nil)
 
 	router.Router.ServeHTTP(w, req)
 
@@ -108,7 +108,7 @@ func Test_GetIconPresignedURL_Missing_Method(t *testing.T) {
 	defer s3.SetInstance(nil) // 清理
 
 	w := httptest.NewRecorder()
-	req := httptest.NewRequest(http.MethodGet, "/icon/presigned-url?key=test/icon.png", nil)
+	req := httptest.NewRequest(http.MethodGet, "/s3/presigned-url?key=test/icon.png", nil)
 
 	router.Router.ServeHTTP(w, req)
 
@@ -124,7 +124,7 @@ func Test_GetIconPresignedURL_S3_Error(t *testing.T) {
 	defer s3.SetInstance(nil) // 清理
 
 	w := httptest.NewRecorder()
-	req := httptest.NewRequest(http.MethodGet, "/icon/presigned-url?key=test/icon.png&method=GET", nil)
+	req := httptest.NewRequest(http.MethodGet, "/s3/presigned-url?key=test/icon.png&method=GET", nil)
 
 	router.Router.ServeHTTP(w, req)
 
@@ -144,7 +144,7 @@ func Test_GetIconPresignedURL_S3_Not_Initialized(t *testing.T) {
 	s3.SetInstance(nil)
 
 	w := httptest.NewRecorder()
-	req := httptest.NewRequest(http.MethodGet, "/icon/presigned-url?key=test/icon.png&method=GET", nil)
+	req := httptest.NewRequest(http.MethodGet, "/s3/presigned-url?key=test/icon.png&method=GET", nil)
 
 	router.Router.ServeHTTP(w, req)
 
@@ -158,3 +158,37 @@ func Test_GetIconPresignedURL_S3_Not_Initialized(t *testing.T) {
 		t.Errorf("expected error code 1006, got body: %s", body)
 	}
 }
+
+func Test_GetIconPresignedURL_Named_Bucket(t *testing.T) {
+	mockS3 := &s3.MockS3API{}
+	s3.SetInstance(mockS3)
+	s3.SetBuckets(map[string]string{s3.BucketIcons: "icon-bucket", s3.BucketExports: "export-bucket"}, "default-bucket")
+	defer s3.SetInstance(nil)                  // 清理
+	defer s3.SetBuckets(nil, "default-bucket") // 清理
+
+	testCases := []struct {
+		name           string
+		query          string
+		expectedBucket string
+	}{
+		{"defaults to icons bucket", "key=test/icon.png&method=GET", "icon-bucket"},
+		{"named bucket", "key=test/icon.png&method=GET&bucket_name=exports", "export-bucket"},
+		{"real bucket name", "key=test/icon.png&method=GET&bucket_name=other-bucket", "other-bucket"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/s3/presigned-url?"+tc.query, nil)
+
+			router.Router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), "https://"+tc.expectedBucket+".s3.amazonaws.com/") {
+				t.Errorf("expected URL for bucket %s, got: %s", tc.expectedBucket, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-base/internal/app/router"
+	"go-base/internal/pkg/aws/s3"
+	modelHttp "go-base/internal/pkg/model/http"
+)
+
+// setupVersionedBucket 建立開啟 versioning 的 bucket，並上傳兩個版本的 icons/a.png
+func setupVersionedBucket(t *testing.T) *s3.MockS3API {
+	t.Helper()
+
+	mockS3 := &s3.MockS3API{}
+	mockS3.PutObject("versioned-bucket", "readme.txt", []byte("readme"), "text/plain")
+	s3.SetInstance(mockS3)
+
+	w, _ := HttpSendAndMarshalBody(http.MethodPut, "/s3/put-bucket-versioning", map[string]string{
+		"bucket_name": "versioned-bucket",
+		"status":      "Enabled",
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+
+	for _, content := range []string{"version-1", "version-2"} {
+		w, _ = HttpSendAndMarshalBody(http.MethodPost, "/s3/upload-file", map[string]string{
+			"bucket_name": "versioned-bucket",
+			"object_key":  "icons/a.png",
+			"file_data":   base64.StdEncoding.EncodeToString([]byte(content)),
+		}, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+		}
+	}
+	return mockS3
+}
+
+func listObjectVersions(t *testing.T, query string) modelHttp.ListObjectVersionsResponse {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/s3/list-object-versions?"+query, nil)
+	router.Router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+	var response modelHttp.ListObjectVersionsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v, body=%s", err, w.Body.String())
+	}
+	return response
+}
+
+func Test_BucketVersioning_Status(t *testing.T) {
+	setupVersionedBucket(t)
+	defer s3.SetInstance(nil) // 清理
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/s3/get-bucket-versioning?bucket_name=versioned-bucket", nil)
+	router.Router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+	if w.Body.String() != `{"status":"Enabled"}` {
+		t.Errorf("unexpected body: %s", w.Body.String())
+	}
+
+	w, _ = HttpSendAndMarshalBody(http.MethodPut, "/s3/put-bucket-versioning", map[string]string{
+		"bucket_name": "versioned-bucket",
+		"status":      "Paused",
+	}, nil)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d, body=%s", w.Code, w.Body.String())
+	}
+}
+
+func Test_ListObjectVersions(t *testing.T) {
+	setupVersionedBucket(t)
+	defer s3.SetInstance(nil) // 清理
+
+	response := listObjectVersions(t, "bucket_name=versioned-bucket&prefix=icons/")
+
+	if len(response.Versions) != 2 {
+		t.Fatalf("expected 2 versions, got %+v", response.Versions)
+	}
+	latest, previous := response.Versions[0], response.Versions[1]
+	if !latest.IsLatest || previous.IsLatest || latest.VersionID == previous.VersionID {
+		t.Errorf("expected newest version first, got %+v", response.Versions)
+	}
+}
+
+func Test_ListObjectVersions_Pagination(t *testing.T) {
+	setupVersionedBucket(t)
+	defer s3.SetInstance(nil) // 清理
+
+	first := listObjectVersions(t, "bucket_name=versioned-bucket&max_keys=2")
+	if !first.IsTruncated || len(first.Versions) != 2 {
+		t.Fatalf("expected a truncated first page of 2, got %+v", first)
+	}
+
+	second := listObjectVersions(t, "bucket_name=versioned-bucket&max_keys=2&key_marker="+first.NextKeyMarker+"&version_id_marker="+first.NextVersionIDMarker)
+	if second.IsTruncated || len(second.Versions) != 1 || second.Versions[0].Key != "readme.txt" {
+		t.Errorf("expected readme.txt on the last page, got %+v", second)
+	}
+}
+
+func Test_ObjectVersion_Download_And_Restore(t *testing.T) {
+	setupVersionedBucket(t)
+	defer s3.SetInstance(nil) // 清理
+
+	versions := listObjectVersions(t, "bucket_name=versioned-bucket&prefix=icons/").Versions
+	oldVersionID := versions[1].VersionID
+
+	// 下載指定版本
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/s3/download-large-object?bucket_name=versioned-bucket&object_key=icons/a.png&version_id="+oldVersionID, nil)
+	router.Router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "version-1" {
+		t.Fatalf("expected version-1, got %d, body=%s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("X-Amz-Version-Id"); got != oldVersionID {
+		t.Errorf("expected version header %s, got %s", oldVersionID, got)
+	}
+
+	// 將舊版本還原為最新版本
+	w, _ = HttpSendAndMarshalBody(http.MethodPost, "/s3/restore-object-version", map[string]string{
+		"bucket_name": "versioned-bucket",
+		"object_key":  "icons/a.png",
+		"version_id":  oldVersionID,
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/s3/download-large-object?bucket_name=versioned-bucket&object_key=icons/a.png", nil)
+	router.Router.ServeHTTP(w, req)
+	if w.Body.String() != "version-1" {
+		t.Errorf("expected restored content version-1, got: %s", w.Body.String())
+	}
+	if versions := listObjectVersions(t, "bucket_name=versioned-bucket&prefix=icons/").Versions; len(versions) != 3 {
+		t.Errorf("expected the restore to add a version, got %+v", versions)
+	}
+}
+
+func Test_ObjectVersion_Delete_Marker(t *testing.T) {
+	setupVersionedBucket(t)
+	defer s3.SetInstance(nil) // 清理
+
+	// 在 versioned bucket 刪除物件只會加上 delete marker
+	w, _ := HttpSendAndMarshalBody(http.MethodDelete, "/s3/delete-objects-from-bucket", map[string]interface{}{
+		"bucket_name": "versioned-bucket",
+		"object_keys": []string{"icons/a.png"},
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+
+	versions := listObjectVersions(t, "bucket_name=versioned-bucket&prefix=icons/").Versions
+	if len(versions) != 3 || !versions[0].IsDeleteMarker || !versions[0].IsLatest {
+		t.Fatalf("expected a delete marker as latest version, got %+v", versions)
+	}
+
+	// 刪除 delete marker 即可復原物件
+	w, _ = HttpSendAndMarshalBody(http.MethodDelete, "/s3/delete-object-version", map[string]string{
+		"bucket_name": "versioned-bucket",
+		"object_key":  "icons/a.png",
+		"version_id":  versions[0].VersionID,
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/s3/download-large-object?bucket_name=versioned-bucket&object_key=icons/a.png", nil)
+	router.Router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "version-2" {
+		t.Errorf("expected version-2 back, got %d, body=%s", w.Code, w.Body.String())
+	}
+}
+
+func Test_ObjectVersion_Not_Found(t *testing.T) {
+	setupVersionedBucket(t)
+	defer s3.SetInstance(nil) // 清理
+
+	w, _ := HttpSendAndMarshalBody(http.MethodDelete, "/s3/delete-object-version", map[string]string{
+		"bucket_name": "versioned-bucket",
+		"object_key":  "icons/a.png",
+		"version_id":  "missing-version",
+	}, nil)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d, body=%s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,142 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-base/internal/app/router"
+	"go-base/internal/pkg/aws/s3"
+	modelHttp "go-base/internal/pkg/model/http"
+)
+
+var errMockS3 = errors.New("mock S3 error")
+
+func setupListObjectsMockS3() *s3.MockS3API {
+	mockS3 := &s3.MockS3API{}
+	for _, key := range []string{"icons/a.png", "icons/b.png", "icons/sub/c.png", "icons/sub/d.png", "readme.txt"} {
+		mockS3.PutObject("list-bucket", key, []byte(key), "")
+	}
+	s3.SetInstance(mockS3)
+	return mockS3
+}
+
+func listObjects(t *testing.T, query string) modelHttp.ListObjectsResponse {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/s3/list-objects?"+query, nil)
+
+	router.Router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+	var response modelHttp.ListObjectsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v, body=%s", err, w.Body.String())
+	}
+	return response
+}
+
+func Test_ListObjects_Returns_Objects(t *testing.T) {
+	setupListObjectsMockS3()
+	defer s3.SetInstance(nil) // 清理
+
+	response := listObjects(t, "bucket_name=list-bucket")
+
+	if len(response.Objects) != 5 {
+		t.Fatalf("expected 5 objects, got %d: %+v", len(response.Objects), response.Objects)
+	}
+	object := response.Objects[0]
+	if object.Key != "icons/a.png" || object.Size != int64(len("icons/a.png")) || object.ETag == "" || object.StorageClass == "" {
+		t.Errorf("unexpected object info: %+v", object)
+	}
+	if response.IsTruncated {
+		t.Errorf("expected a single page")
+	}
+}
+
+func Test_ListObjects_Prefix_And_Delimiter(t *testing.T) {
+	setupListObjectsMockS3()
+	defer s3.SetInstance(nil) // 清理
+
+	response := listObjects(t, "bucket_name=list-bucket&prefix=icons/&delimiter=/")
+
+	if len(response.Objects) != 2 {
+		t.Fatalf("expected 2 objects, got %+v", response.Objects)
+	}
+	if len(response.CommonPrefixes) != 1 || response.CommonPrefixes[0] != "icons/sub/" {
+		t.Errorf("expected common prefix icons/sub/, got %+v", response.CommonPrefixes)
+	}
+}
+
+func Test_ListObjects_Pagination(t *testing.T) {
+	setupListObjectsMockS3()
+	defer s3.SetInstance(nil) // 清理
+
+	var keys []string
+	query := "bucket_name=list-bucket&max_keys=2"
+	for page := 0; page < 5; page++ {
+		response := listObjects(t, query)
+		for _, object := range response.Objects {
+			keys = append(keys, object.Key)
+		}
+		if !response.IsTruncated {
+			break
+		}
+		if response.NextContinuationToken == "" {
+			t.Fatalf("expected a continuation token on a truncated page")
+		}
+		query = "bucket_name=list-bucket&max_keys=2&continuation_token=" + response.NextContinuationToken
+	}
+
+	if len(keys) != 5 {
+		t.Errorf("expected all 5 keys across pages, got %v", keys)
+	}
+}
+
+func Test_ListObjects_Invalid_MaxKeys(t *testing.T) {
+	setupListObjectsMockS3()
+	defer s3.SetInstance(nil) // 清理
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/s3/list-objects?bucket_name=list-bucket&max_keys=5000", nil)
+
+	router.Router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d, body=%s", w.Code, w.Body.String())
+	}
+}
+
+func Test_ListObjects_Bucket_Not_Found(t *testing.T) {
+	setupListObjectsMockS3()
+	defer s3.SetInstance(nil) // 清理
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/s3/list-objects?bucket_name=missing-bucket", nil)
+
+	router.Router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d, body=%s", w.Code, w.Body.String())
+	}
+}
+
+func Test_ListObjects_S3_Error(t *testing.T) {
+	mockS3 := setupListObjectsMockS3()
+	mockS3.FailOn("ListObjects", errMockS3)
+	defer s3.SetInstance(nil) // 清理
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/s3/list-objects?bucket_name=list-bucket", nil)
+
+	router.Router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d, body=%s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,122 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-base/internal/app/router"
+	externalAccount "go-base/internal/app/service/external/account"
+	"go-base/internal/pkg/aws/s3"
+	"go-base/internal/pkg/aws/sqs"
+	"go-base/internal/pkg/config"
+	"go-base/internal/pkg/health"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func getReadyz(t *testing.T) (int, health.Report) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	router.Router.ServeHTTP(w, req)
+
+	var report health.Report
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("failed to parse response: %v, body=%s", err, w.Body.String())
+	}
+	return w.Code, report
+}
+
+// checkStates 回傳每個檢查的狀態
+func checkStates(report health.Report) map[string]health.State {
+	states := make(map[string]health.State, len(report.Checks))
+	for _, result := range report.Checks {
+		states[result.Name] = result.State
+	}
+	return states
+}
+
+func Test_Livez(t *testing.T) {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/livez", nil)
+	router.Router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "ok" {
+		t.Errorf("expected 200 ok, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func Test_Readyz(t *testing.T) {
+	mockS3 := &s3.MockS3API{}
+	mockS3.CreateBucket(context.Background(), "ready-bucket", "us-west-2")
+	s3.SetInstance(mockS3)
+	defer s3.SetInstance(nil) // 清理
+
+	mockSQS := &sqs.MockSQSAPI{QueueNames: []string{"ready-queue"}}
+	sqs.SetInstance(mockSQS)
+	defer sqs.SetInstance(nil) // 清理
+
+	httpmock.Reset()
+	externalAccount.ClearAuthCache()
+	authURL := config.Env.AuthServiceHost + "/$SS$/Services/OAuth/Token"
+	httpmock.RegisterResponder("POST", authURL, httpmock.NewStringResponder(200, `{"access_token":"ready-token"}`))
+	defer httpmock.Reset()                 // 清理
+	defer externalAccount.ClearAuthCache() // 清理
+
+	registry := health.NewRegistry(health.RegistryConfig{Timeout: time.Second})
+	registry.RegisterCheck(sqs.HealthChecker("ready-queue"), health.CheckOptions{Critical: true})
+	registry.RegisterCheck(s3.HealthChecker("ready-bucket"), health.CheckOptions{})
+	registry.RegisterCheck(externalAccount.HealthChecker(), health.CheckOptions{})
+	health.SetInstance(registry)
+	defer health.SetInstance(nil) // 清理
+
+	code, report := getReadyz(t)
+	if code != http.StatusOK || !report.Healthy || report.Degraded {
+		t.Fatalf("expected ready, got %d %+v", code, report)
+	}
+	want := map[string]health.State{"sqs": health.StateUp, "s3": health.StateUp, "auth": health.StateUp}
+	if got := checkStates(report); len(got) != len(want) || got["sqs"] != want["sqs"] || got["s3"] != want["s3"] || got["auth"] != want["auth"] {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	// 非 critical 的 S3 與 auth 失敗仍然 ready
+	mockS3.FailOn("BucketExists", errors.New("access denied"))
+	externalAccount.ClearAuthCache()
+	httpmock.RegisterResponder("POST", authURL, httpmock.NewStringResponder(401, `{"error":"unauthorized"}`))
+	code, report = getReadyz(t)
+	if code != http.StatusOK || !report.Healthy || !report.Degraded {
+		t.Fatalf("expected ready but degraded, got %d %+v", code, report)
+	}
+	if got := checkStates(report); got["s3"] != health.StateDown || got["auth"] != health.StateDown || got["sqs"] != health.StateUp {
+		t.Errorf("expected s3 and auth down, got %v", got)
+	}
+
+	// critical 的 SQS 失敗
+	mockSQS.ShouldFail = true
+	code, report = getReadyz(t)
+	if code != http.StatusServiceUnavailable || report.Healthy {
+		t.Fatalf("expected 503, got %d %+v", code, report)
+	}
+	if got := checkStates(report); got["sqs"] != health.StateDown {
+		t.Errorf("expected sqs down, got %v", got)
+	}
+}
+
+func Test_Readyz_Bucket_Not_Found(t *testing.T) {
+	s3.SetInstance(&s3.MockS3API{})
+	defer s3.SetInstance(nil) // 清理
+
+	registry := health.NewRegistry(health.RegistryConfig{})
+	registry.Register(s3.HealthChecker("missing-bucket"))
+	health.SetInstance(registry)
+	defer health.SetInstance(nil) // 清理
+
+	code, report := getReadyz(t)
+	if code != http.StatusServiceUnavailable || report.Checks[0].Error != "bucket missing-bucket not found" {
+		t.Errorf("expected the missing bucket reported, got %d %+v", code, report)
+	}
+}
@@ -0,0 +1,213 @@
+package test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-base/internal/app/router"
+	"go-base/internal/pkg/aws/s3"
+	modelHttp "go-base/internal/pkg/model/http"
+)
+
+func Test_UploadFile_Forwards_Checksum(t *testing.T) {
+	mockS3 := &s3.MockS3API{}
+	mockS3.PutObject("checksum-bucket", "placeholder", nil, "")
+	s3.SetInstance(mockS3)
+	defer s3.SetInstance(nil) // 清理
+
+	data := []byte("hello checksum")
+	checksum := s3.ChecksumSHA256(data)
+
+	tests := []struct {
+		name       string
+		body       map[string]interface{}
+		header     string
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "checksum in body",
+			body:       map[string]interface{}{"checksum_sha256": checksum},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "checksum in header",
+			header:     checksum,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "no checksum",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "checksum of other data",
+			header:     s3.ChecksumSHA256([]byte("other data")),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "4033", // S3ChecksumMismatch
+		},
+		{
+			name:       "malformed checksum",
+			body:       map[string]interface{}{"checksum_sha256": "not-a-sha256"},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "4034", // S3InvalidChecksum
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := map[string]interface{}{
+				"bucket_name": "checksum-bucket",
+				"object_key":  "docs/" + strings.ReplaceAll(tt.name, " ", "-") + ".txt",
+				"file_data":   base64.StdEncoding.EncodeToString(data),
+			}
+			for k, v := range tt.body {
+				body[k] = v
+			}
+			payload, _ := json.Marshal(body)
+			w, err := HttpPost("/s3/upload-file", string(payload), map[string]string{"x-amz-checksum-sha256": tt.header})
+			if err != nil {
+				t.Fatalf("failed to send request: %v", err)
+			}
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d, body=%s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantCode != "" {
+				if !strings.Contains(w.Body.String(), tt.wantCode) {
+					t.Errorf("expected error code %s, got body: %s", tt.wantCode, w.Body.String())
+				}
+				if _, ok := mockS3.Object("checksum-bucket", body["object_key"].(string)); ok {
+					t.Error("expected the object not to be stored")
+				}
+				return
+			}
+
+			var response modelHttp.UploadFileResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil || response.ChecksumSHA256 != checksum {
+				t.Errorf("expected checksum %s in response, got body=%s", checksum, w.Body.String())
+			}
+			options, _ := mockS3.ObjectOptions("checksum-bucket", body["object_key"].(string))
+			if options.ChecksumSHA256 != checksum {
+				t.Errorf("expected the checksum to be forwarded, got %+v", options)
+			}
+		})
+	}
+}
+
+func Test_DownloadFile_Checksum_Mismatch(t *testing.T) {
+	mockS3 := &s3.MockS3API{}
+	mockS3.PutObject("checksum-bucket", "docs/report.txt", []byte("original content"), "text/plain")
+	s3.SetInstance(mockS3)
+	defer s3.SetInstance(nil) // 清理
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/s3/download-file?bucket_name=checksum-bucket&object_key=docs/report.txt", nil)
+	router.Router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+	var response modelHttp.DownloadFileResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil || response.ChecksumSHA256 != s3.ChecksumSHA256([]byte("original content")) {
+		t.Errorf("expected the verified checksum in the response, got body=%s", w.Body.String())
+	}
+
+	// 模擬上傳後內容損毀
+	mockS3.CorruptObject("checksum-bucket", "docs/report.txt", []byte("corrupted content"))
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/s3/download-file?bucket_name=checksum-bucket&object_key=docs/report.txt", nil)
+	router.Router.ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d, body=%s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "4033") { // S3ChecksumMismatch
+		t.Errorf("expected error code 4033, got body: %s", w.Body.String())
+	}
+
+	// 串流下載已送出狀態碼，客戶端可用 x-amz-checksum-sha256 自行驗證
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/s3/download-large-object?bucket_name=checksum-bucket&object_key=docs/report.txt", nil)
+	router.Router.ServeHTTP(w, req)
+	if got := w.Header().Get("x-amz-checksum-sha256"); got != s3.ChecksumSHA256([]byte("original content")) {
+		t.Errorf("expected the stored checksum header, got %q", got)
+	}
+	if s3.ChecksumSHA256(w.Body.Bytes()) == w.Header().Get("x-amz-checksum-sha256") {
+		t.Error("expected the corrupted body not to match the checksum header")
+	}
+}
+
+func Test_VerifyObject(t *testing.T) {
+	mockS3 := &s3.MockS3API{}
+	mockS3.PutObject("checksum-bucket", "docs/good.txt", []byte("good content"), "text/plain")
+	mockS3.PutObject("checksum-bucket", "docs/bad.txt", []byte("bad content"), "text/plain")
+	mockS3.CorruptObject("checksum-bucket", "docs/bad.txt", []byte("bit rot"))
+	s3.SetInstance(mockS3)
+	defer s3.SetInstance(nil) // 清理
+
+	tests := []struct {
+		name         string
+		query        string
+		wantStatus   int
+		wantValid    bool
+		wantChecksum string
+	}{
+		{
+			name:         "matches stored checksum",
+			query:        "object_key=docs/good.txt",
+			wantStatus:   http.StatusOK,
+			wantValid:    true,
+			wantChecksum: s3.ChecksumSHA256([]byte("good content")),
+		},
+		{
+			name:         "matches expected checksum",
+			query:        "object_key=docs/good.txt&checksum_sha256=" + strings.ReplaceAll(s3.ChecksumSHA256([]byte("good content")), "+", "%2B"),
+			wantStatus:   http.StatusOK,
+			wantValid:    true,
+			wantChecksum: s3.ChecksumSHA256([]byte("good content")),
+		},
+		{
+			name:         "differs from expected checksum",
+			query:        "object_key=docs/good.txt&checksum_sha256=" + strings.ReplaceAll(s3.ChecksumSHA256([]byte("other")), "+", "%2B"),
+			wantStatus:   http.StatusOK,
+			wantValid:    false,
+			wantChecksum: s3.ChecksumSHA256([]byte("good content")),
+		},
+		{
+			name:         "corrupted object",
+			query:        "object_key=docs/bad.txt",
+			wantStatus:   http.StatusOK,
+			wantValid:    false,
+			wantChecksum: s3.ChecksumSHA256([]byte("bit rot")),
+		},
+		{
+			name:       "missing object",
+			query:      "object_key=docs/missing.txt",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/s3/verify?bucket_name=checksum-bucket&"+tt.query, nil)
+			router.Router.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d, body=%s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var response modelHttp.VerifyObjectResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to parse response: %v, body=%s", err, w.Body.String())
+			}
+			if response.Valid != tt.wantValid || response.ChecksumSHA256 != tt.wantChecksum {
+				t.Errorf("unexpected response: %+v", response)
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-base/internal/pkg/cache"
+	"go-base/internal/pkg/health"
+	"go-base/internal/pkg/postgres"
+	"go-base/internal/pkg/queue"
+	"go-base/test/container"
+)
+
+// fakeChecker 模擬一個連線，ping 花 delay 後回傳 err
+type fakeChecker struct {
+	name    string
+	delay   time.Duration
+	err     error
+	tracker health.Tracker
+}
+
+func (c *fakeChecker) Name() string { return c.name }
+
+func (c *fakeChecker) Ping(ctx context.Context) error {
+	select {
+	case <-time.After(c.delay):
+		return c.tracker.Observe(c.err)
+	case <-ctx.Done():
+		return c.tracker.Observe(ctx.Err())
+	}
+}
+
+func (c *fakeChecker) State() health.State { return c.tracker.State() }
+
+func (c *fakeChecker) LastError() error { return c.tracker.LastError() }
+
+func (c *fakeChecker) LastErrorAt() time.Time { return c.tracker.LastErrorAt() }
+
+// checkRecorder 記錄 registry 回報的結果
+type checkRecorder struct {
+	mu     sync.Mutex
+	states map[string]health.State
+}
+
+func (r *checkRecorder) RecordCheck(name string, state health.State, latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.states[name] = state
+}
+
+func Test_Health_Registry(t *testing.T) {
+	recorder := &checkRecorder{states: map[string]health.State{}}
+	registry := health.NewRegistry(health.RegistryConfig{Timeout: 300 * time.Millisecond, Recorder: recorder})
+	registry.Register(
+		&fakeChecker{name: "fast", delay: 10 * time.Millisecond},
+		&fakeChecker{name: "slow", delay: 200 * time.Millisecond},
+		&fakeChecker{name: "failing", err: errors.New("connection refused")},
+	)
+
+	start := time.Now()
+	report := registry.Check(context.Background())
+	// 同時檢查
+	if elapsed := time.Since(start); elapsed > 290*time.Millisecond {
+		t.Errorf("expected the checks to run at the same time, took %s", elapsed)
+	}
+	if report.Healthy || len(report.Checks) != 3 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	for i, want := range []struct {
+		name  string
+		state health.State
+	}{{"fast", health.StateUp}, {"slow", health.StateUp}, {"failing", health.StateDown}} {
+		if got := report.Checks[i]; got.Name != want.name || got.State != want.state {
+			t.Errorf("expected %s %s, got %+v", want.name, want.state, got)
+		}
+	}
+	if failing := report.Checks[2]; failing.Error != "connection refused" || failing.LastError != "connection refused" || failing.LastErrorAt == nil {
+		t.Errorf("expected the failure reported, got %+v", failing)
+	}
+	if recorder.states["failing"] != health.StateDown || recorder.states["fast"] != health.StateUp {
+		t.Errorf("expected every check recorded, got %v", recorder.states)
+	}
+
+	// 逾時視為 down
+	registry = health.NewRegistry(health.RegistryConfig{Timeout: 50 * time.Millisecond})
+	registry.Register(&fakeChecker{name: "hanging", delay: time.Minute})
+	report = registry.Check(context.Background())
+	if report.Healthy || report.Checks[0].State != health.StateDown || !strings.Contains(report.Checks[0].Error, "deadline") {
+		t.Errorf("expected the timeout reported, got %+v", report)
+	}
+
+	// 沒有註冊任何連線
+	if report := health.NewRegistry(health.RegistryConfig{}).Check(context.Background()); !report.Healthy {
+		t.Errorf("expected an empty registry to be healthy, got %+v", report)
+	}
+}
+
+func Test_Health_Registry_Critical(t *testing.T) {
+	registry := health.NewRegistry(health.RegistryConfig{Timeout: time.Minute})
+	registry.Register(&fakeChecker{name: "database"})
+	registry.RegisterCheck(&fakeChecker{name: "storage", err: errors.New("access denied")}, health.CheckOptions{})
+	// 不看 ctx 的 ping 也在自己的 timeout 結束
+	registry.RegisterCheck(health.NewChecker("auth", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}), health.CheckOptions{Timeout: 50 * time.Millisecond})
+
+	start := time.Now()
+	report := registry.Check(context.Background())
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected the per-check timeout, took %s", elapsed)
+	}
+	// 非 critical 的失敗只會 degraded
+	if !report.Healthy || !report.Degraded {
+		t.Errorf("expected healthy and degraded, got %+v", report)
+	}
+	for i, want := range []struct {
+		name     string
+		critical bool
+		state    health.State
+	}{{"database", true, health.StateUp}, {"storage", false, health.StateDown}, {"auth", false, health.StateDown}} {
+		if got := report.Checks[i]; got.Name != want.name || got.Critical != want.critical || got.State != want.state {
+			t.Errorf("expected %+v, got %+v", want, got)
+		}
+	}
+	if !strings.Contains(report.Checks[2].Error, "deadline") {
+		t.Errorf("expected the timeout reported, got %+v", report.Checks[2])
+	}
+
+	// critical 的失敗
+	registry.Register(&fakeChecker{name: "queue", err: errors.New("connection refused")})
+	if report := registry.Check(context.Background()); report.Healthy {
+		t.Errorf("expected unhealthy, got %+v", report)
+	}
+}
+
+func Test_Health_Registry_Cache(t *testing.T) {
+	var mu sync.Mutex
+	pings := 0
+	registry := health.NewRegistry(health.RegistryConfig{CacheTTL: 300 * time.Millisecond})
+	registry.Register(health.NewChecker("counted", func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		pings++
+		time.Sleep(20 * time.Millisecond)
+		return ctx.Err()
+	}))
+	countPings := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return pings
+	}
+
+	// 呼叫端取消不會讓取消的結果被快取
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	first := registry.Check(ctx)
+	if !first.Healthy {
+		t.Fatalf("expected healthy, got %+v", first)
+	}
+
+	// TTL 內同時呼叫都拿到同一份結果
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if report := registry.Check(context.Background()); !report.CheckedAt.Equal(first.CheckedAt) {
+				t.Errorf("expected the cached report, got %+v", report)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := countPings(); got != 1 {
+		t.Errorf("expected 1 ping within the TTL, got %d", got)
+	}
+
+	// TTL 過後重新檢查
+	time.Sleep(350 * time.Millisecond)
+	if report := registry.Check(context.Background()); !report.CheckedAt.After(first.CheckedAt) || countPings() != 2 {
+		t.Errorf("expected a new check after the TTL, got %+v with %d pings", report, countPings())
+	}
+}
+
+func Test_Health_Not_Set_Up(t *testing.T) {
+	registry := health.NewRegistry(health.RegistryConfig{Timeout: time.Second})
+	registry.Register(&cache.Manager{}, &postgres.Manager{}, &queue.Manager{})
+
+	report := registry.Check(context.Background())
+	for _, result := range report.Checks {
+		if result.State != health.StateDown || !strings.Contains(result.Error, "not set up") {
+			t.Errorf("expected %s down, got %+v", result.Name, result)
+		}
+	}
+}
+
+// waitForState 等到連線進入 state
+func waitForState(t *testing.T, checker health.HealthChecker, state health.State) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for checker.State() != state {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %s %s, got %s", checker.Name(), state, checker.State())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func Test_Health_Nats_Reconnect(t *testing.T) {
+	natsServer, err := container.SetupNats(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to start NATS: %v", err)
+	}
+	t.Cleanup(natsServer.Terminate) // 清理
+
+	manager := &queue.Manager{}
+	if err := manager.Setup(queue.Config{Url: natsServer.URI, ReconnectWait: 50 * time.Millisecond}); err != nil {
+		t.Fatalf("failed to set up queue: %v", err)
+	}
+	t.Cleanup(func() { manager.Close(context.Background()) }) // 清理
+
+	registry := health.NewRegistry(health.RegistryConfig{Timeout: 200 * time.Millisecond})
+	registry.Register(manager)
+	if report := registry.Check(context.Background()); !report.Healthy || report.Checks[0].Name != "nats" {
+		t.Fatalf("expected NATS up, got %+v", report)
+	}
+
+	// server 停止後持續重連
+	natsServer.Shutdown()
+	natsServer.WaitForShutdown()
+	waitForState(t, manager, health.StateReconnecting)
+	report := registry.Check(context.Background())
+	if report.Healthy || report.Checks[0].State != health.StateDown || report.Checks[0].LastError == "" {
+		t.Errorf("expected NATS down with the disconnection, got %+v", report)
+	}
+
+	// server 回來後自動重連
+	if err := natsServer.Restart(); err != nil {
+		t.Fatalf("failed to restart NATS: %v", err)
+	}
+	waitForState(t, manager, health.StateUp)
+	if report := registry.Check(context.Background()); !report.Healthy {
+		t.Errorf("expected NATS up after reconnecting, got %+v", report)
+	}
+}
+
+func Test_Health_Redis(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping Redis in short mode")
+	}
+	ctx := context.Background()
+	redisContainer, err := container.SetupRedis(ctx)
+	if err != nil {
+		t.Skipf("Redis unavailable, %v", err)
+	}
+	defer redisContainer.Terminate(ctx) // 清理
+
+	manager := &cache.Manager{}
+	if err := manager.Setup(cache.Config{
+		Type:         "redis_default",
+		EndpointList: []string{strings.TrimPrefix(redisContainer.URI, "redis://")},
+	}); err != nil {
+		t.Fatalf("failed to connect to Redis: %v", err)
+	}
+	defer manager.Close() // 清理
+
+	registry := health.NewRegistry(health.RegistryConfig{})
+	registry.Register(manager)
+	if report := registry.Check(ctx); !report.Healthy || report.Checks[0].Name != "redis" {
+		t.Errorf("expected Redis up, got %+v", report)
+	}
+}
@@ -0,0 +1,216 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go-base/internal/app/service"
+	"go-base/internal/pkg/aws/s3"
+	"go-base/internal/pkg/aws/sqs"
+	modelHttp "go-base/internal/pkg/model/http"
+)
+
+func newTestIconProcessor() *service.IconProcessor {
+	return service.NewIconProcessor(service.IconProcessorConfig{
+		Sizes:        []int{32, 64, 128, 256},
+		MaxDimension: 1024,
+		MaxFileSize:  1024 * 1024,
+	})
+}
+
+func testImage(width int, height int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+func testPNG(t *testing.T, width int, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage(width, height)); err != nil {
+		t.Fatalf("failed to encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// testJPEGWithExif 產生帶有 EXIF APP1 區段的 JPEG
+func testJPEGWithExif(t *testing.T, width int, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(width, height), nil); err != nil {
+		t.Fatalf("failed to encode jpeg: %v", err)
+	}
+	exif := append([]byte("Exif\x00\x00"), []byte("GPS 25.0330N 121.5654E")...)
+	segment := append([]byte{0xFF, 0xE1, byte((len(exif) + 2) >> 8), byte(len(exif) + 2)}, exif...)
+	data := buf.Bytes()
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+// s3EventMessage 模擬 S3 透過 SQS 送出的 ObjectCreated 事件
+func s3EventMessage(bucketName string, key string) sqs.Message {
+	body := fmt.Sprintf(`{"Records":[{"eventName":"ObjectCreated:Put","s3":{"bucket":{"name":%q},"object":{"key":%q}}}]}`, bucketName, key)
+	return sqs.Message{Body: body, ReceiptHandle: "receipt-handle"}
+}
+
+func getIconHeadObject(t *testing.T, bucketName string, key string) modelHttp.GetIconHeadObjectResponse {
+	t.Helper()
+	w, err := HttpGet(fmt.Sprintf("/s3/head-object?bucket_name=%s&key=%s", bucketName, key), nil)
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+	var response modelHttp.GetIconHeadObjectResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v, body=%s", err, w.Body.String())
+	}
+	return response
+}
+
+func Test_IconProcessor_Generates_Variants(t *testing.T) {
+	WithDBCleanup(t)
+	mockS3 := &s3.MockS3API{}
+	mockS3.PutObject("icon-bucket", "avatars/me.png", testPNG(t, 200, 100), "application/octet-stream")
+	s3.SetInstance(mockS3)
+	defer s3.SetInstance(nil) // 清理
+
+	if err := newTestIconProcessor().ProcessMessage(context.Background(), s3EventMessage("icon-bucket", "avatars/me.png")); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// 256 比原圖大，不產生
+	wantSizes := map[int][2]int{32: {32, 16}, 64: {64, 32}, 128: {128, 64}}
+	for size, dimensions := range wantSizes {
+		data, ok := mockS3.Object("icon-bucket", service.IconVariantKey("avatars/me.png", size))
+		if !ok {
+			t.Fatalf("expected the %dpx variant to exist", size)
+		}
+		config, err := png.DecodeConfig(bytes.NewReader(data))
+		if err != nil || config.Width != dimensions[0] || config.Height != dimensions[1] {
+			t.Errorf("expected %dx%d png for %dpx, got %+v, %v", dimensions[0], dimensions[1], size, config, err)
+		}
+	}
+	if _, ok := mockS3.Object("icon-bucket", service.IconVariantKey("avatars/me.png", 256)); ok {
+		t.Error("expected no variant larger than the icon")
+	}
+
+	response := getIconHeadObject(t, "icon-bucket", "avatars/me.png")
+	if response.ProcessingStatus != "ready" || len(response.Variants) != 3 {
+		t.Fatalf("unexpected head object response: %+v", response)
+	}
+	if response.ContentType != "image/png" || response.Metadata[service.IconProcessedMetadata] != "true" {
+		t.Errorf("expected the original to be replaced with the sanitized png, got %+v", response)
+	}
+
+	// 處理器自己寫入的物件所觸發的事件要被忽略
+	before, _ := mockS3.Object("icon-bucket", "avatars/me.png")
+	if err := newTestIconProcessor().ProcessMessage(context.Background(), s3EventMessage("icon-bucket", "avatars/me.png")); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := newTestIconProcessor().ProcessMessage(context.Background(), s3EventMessage("icon-bucket", service.IconVariantKey("avatars/me.png", 32))); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if after, _ := mockS3.Object("icon-bucket", "avatars/me.png"); !bytes.Equal(before, after) {
+		t.Error("expected a processed icon not to be processed again")
+	}
+	if _, ok := mockS3.Object("icon-bucket", service.IconVariantKey(service.IconVariantKey("avatars/me.png", 32), 32)); ok {
+		t.Error("expected variants not to get variants")
+	}
+}
+
+func Test_IconProcessor_Strips_Exif(t *testing.T) {
+	WithDBCleanup(t)
+	original := testJPEGWithExif(t, 64, 64)
+	mockS3 := &s3.MockS3API{}
+	mockS3.PutObject("icon-bucket", "avatars/my+photo.jpg", original, "image/jpeg")
+	s3.SetInstance(mockS3)
+	defer s3.SetInstance(nil) // 清理
+
+	if !bytes.Contains(original, []byte("Exif")) {
+		t.Fatal("expected the test jpeg to carry exif data")
+	}
+
+	// 事件中的 key 是 URL 編碼過的
+	if err := newTestIconProcessor().ProcessMessage(context.Background(), s3EventMessage("icon-bucket", "avatars/my%2Bphoto.jpg")); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	data, ok := mockS3.Object("icon-bucket", "avatars/my+photo.jpg")
+	if !ok {
+		t.Fatal("expected the icon to be kept")
+	}
+	if bytes.Contains(data, []byte("Exif")) || bytes.Contains(data, []byte("GPS")) {
+		t.Error("expected exif data to be stripped")
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(data)); err != nil {
+		t.Errorf("expected a valid jpeg, got %v", err)
+	}
+	if _, ok := mockS3.Object("icon-bucket", service.IconVariantKey("avatars/my+photo.jpg", 64)); !ok {
+		t.Error("expected the 64px variant to exist")
+	}
+}
+
+func Test_IconProcessor_Rejects_Invalid_Icons(t *testing.T) {
+	WithDBCleanup(t)
+
+	tests := []struct {
+		name   string
+		key    string
+		data   func(t *testing.T) []byte
+		reason string
+	}{
+		{
+			name:   "not an image",
+			key:    "avatars/script.png",
+			data:   func(t *testing.T) []byte { return []byte("#!/bin/sh\necho not an image\n") },
+			reason: "unsupported content type",
+		},
+		{
+			name:   "oversize dimensions",
+			key:    "avatars/banner.png",
+			data:   func(t *testing.T) []byte { return testPNG(t, 2000, 10) },
+			reason: "exceeds the",
+		},
+		{
+			name:   "oversize file",
+			key:    "avatars/huge.png",
+			data:   func(t *testing.T) []byte { return make([]byte, 1024*1024+1) },
+			reason: "bytes limit",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockS3 := &s3.MockS3API{}
+			mockS3.PutObject("icon-bucket", tt.key, tt.data(t), "image/png")
+			s3.SetInstance(mockS3)
+			defer s3.SetInstance(nil) // 清理
+
+			if err := newTestIconProcessor().ProcessMessage(context.Background(), s3EventMessage("icon-bucket", tt.key)); err != nil {
+				t.Fatalf("expected rejection not to be retried, got %v", err)
+			}
+			if _, ok := mockS3.Object("icon-bucket", tt.key); ok {
+				t.Error("expected the rejected upload to be deleted")
+			}
+
+			response := getIconHeadObject(t, "icon-bucket", tt.key)
+			if response.Exists || response.ProcessingStatus != "rejected" || !strings.Contains(response.Reason, tt.reason) {
+				t.Errorf("unexpected head object response: %+v", response)
+			}
+		})
+	}
+}
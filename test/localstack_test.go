@@ -0,0 +1,202 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"go-base/internal/pkg/aws/endpoint"
+	"go-base/internal/pkg/aws/s3"
+	"go-base/internal/pkg/aws/sqs"
+	modelHttp "go-base/internal/pkg/model/http"
+	"go-base/test/container"
+)
+
+// Test_LocalStack 透過 LocalStack 對 /s3 與 /sqs 路由做端對端測試，沒有 Docker 時略過
+func Test_LocalStack(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping end-to-end tests in short mode")
+	}
+
+	ctx := context.Background()
+	localStack, err := container.SetupLocalStack(ctx)
+	if err != nil {
+		t.Skipf("LocalStack unavailable, %v", err)
+	}
+	defer localStack.Terminate(ctx) // 清理
+
+	localStackEndpoint := endpoint.Config{
+		URL:             localStack.URI,
+		AccessKeyID:     container.LocalStackAccessKeyID,
+		SecretAccessKey: container.LocalStackSecretAccessKey,
+		DisableTLS:      true,
+	}
+
+	t.Run("s3", func(t *testing.T) {
+		s3API, err := s3.NewBaseS3API(s3.Config{
+			AWSS3Region:  container.LocalStackRegion,
+			Endpoint:     localStackEndpoint,
+			UsePathStyle: true,
+		})
+		if err != nil {
+			t.Fatalf("failed to create S3 client: %v", err)
+		}
+		s3.SetInstance(s3API)
+		defer s3.SetInstance(nil) // 清理
+
+		testLocalStackS3(t)
+	})
+
+	t.Run("sqs", func(t *testing.T) {
+		if err := localStack.CreateQueue(ctx, "e2e-queue"); err != nil {
+			t.Fatalf("failed to create queue: %v", err)
+		}
+		registry, err := sqs.NewRegistry(sqs.RegistryConfig{
+			Region:     container.LocalStackRegion,
+			Endpoint:   localStackEndpoint,
+			QueueNames: []string{"e2e-queue", "missing-queue"},
+		})
+		if err != nil {
+			t.Fatalf("failed to create SQS client: %v", err)
+		}
+		previous := sqs.GetInstance()
+		sqs.SetInstance(registry)
+		defer sqs.SetInstance(previous) // 清理
+		allowSendQueues(t, "e2e-queue", "missing-queue")
+
+		queue, err := registry.Queue(ctx, "e2e-queue")
+		if err != nil {
+			t.Fatalf("failed to resolve queue: %v", err)
+		}
+		testLocalStackSQS(t, queue)
+	})
+}
+
+func testLocalStackS3(t *testing.T) {
+	const bucketName = "e2e-bucket"
+	data := []byte("hello localstack")
+	checksum := s3.ChecksumSHA256(data)
+
+	w, _ := HttpSendAndMarshalBody(http.MethodPost, "/s3/create-bucket", map[string]interface{}{
+		"bucket_name": bucketName,
+		"region":      container.LocalStackRegion,
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("create bucket: expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+
+	w, _ = HttpSendAndMarshalBody(http.MethodPost, "/s3/upload-file", map[string]interface{}{
+		"bucket_name":     bucketName,
+		"object_key":      "docs/uploaded.txt",
+		"file_data":       base64.StdEncoding.EncodeToString(data),
+		"content_type":    "text/plain",
+		"checksum_sha256": checksum,
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("upload file: expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+
+	w, _ = HttpGet("/s3/download-file?bucket_name="+bucketName+"&object_key=docs/uploaded.txt", nil)
+	var downloaded modelHttp.DownloadFileResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &downloaded); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("download file: got %d, body=%s", w.Code, w.Body.String())
+	}
+	if downloaded.FileData != base64.StdEncoding.EncodeToString(data) || downloaded.ChecksumSHA256 != checksum {
+		t.Errorf("download file: unexpected response %+v", downloaded)
+	}
+
+	w, _ = HttpGet("/s3/verify?bucket_name="+bucketName+"&object_key=docs/uploaded.txt", nil)
+	var verified modelHttp.VerifyObjectResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &verified); err != nil || !verified.Valid || verified.StoredChecksumSHA256 != checksum {
+		t.Errorf("verify: unexpected response %d, body=%s", w.Code, w.Body.String())
+	}
+
+	// 用預簽名網址直接上傳到 LocalStack
+	w, _ = HttpGet("/s3/presigned-url?bucket_name="+bucketName+"&key=docs/presigned.txt&method=PUT", nil)
+	var presigned modelHttp.GetIconPresignedURLResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &presigned); err != nil || presigned.PresignedURL == "" {
+		t.Fatalf("presigned url: got %d, body=%s", w.Code, w.Body.String())
+	}
+	req, _ := http.NewRequest(http.MethodPut, presigned.PresignedURL, bytes.NewReader(data))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("presigned put: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("presigned put: expected 200, got %d", resp.StatusCode)
+	}
+
+	response := listObjects(t, "bucket_name="+bucketName+"&prefix="+url.QueryEscape("docs/"))
+	if len(response.Objects) != 2 {
+		t.Fatalf("list objects: expected 2 objects, got %+v", response.Objects)
+	}
+
+	w, _ = HttpSendAndMarshalBody(http.MethodDelete, "/s3/delete-objects-from-bucket", map[string]interface{}{
+		"bucket_name": bucketName,
+		"object_keys": []string{"docs/uploaded.txt", "docs/presigned.txt"},
+	}, nil)
+	var deleted modelHttp.DeleteObjectsFromBucketResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &deleted); err != nil || !deleted.Success || deleted.DeletedCount != 2 {
+		t.Errorf("delete objects: unexpected response %d, body=%s", w.Code, w.Body.String())
+	}
+}
+
+func testLocalStackSQS(t *testing.T, manager sqs.SQSAPI) {
+	ctx := context.Background()
+
+	w, _ := HttpSendAndMarshalBody(http.MethodPost, "/sqs/send-message", map[string]interface{}{
+		"queue_name": "e2e-queue",
+		"message":    "hello localstack",
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("send message: expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+
+	w, _ = HttpSendAndMarshalBody(http.MethodPost, "/sqs/send-messages", map[string]interface{}{
+		"queue_name": "e2e-queue",
+		"messages":   []string{"first", "second"},
+	}, nil)
+	var sent modelHttp.SendMessagesResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &sent); err != nil || !sent.Success || sent.SuccessCount != 2 {
+		t.Fatalf("send messages: unexpected response %d, body=%s", w.Code, w.Body.String())
+	}
+
+	for queueName, code := range map[string]string{
+		"missing-queue": "5002", // SQSQueueNotFound
+		"other-queue":   "5001", // SQSQueueNotAllowed
+	} {
+		w, _ = HttpSendAndMarshalBody(http.MethodPost, "/sqs/send-message", map[string]interface{}{
+			"queue_name": queueName,
+			"message":    "hello localstack",
+		}, nil)
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), code) {
+			t.Errorf("send message to %s: expected 400 with %s, got %d, body=%s", queueName, code, w.Code, w.Body.String())
+		}
+	}
+
+	received := map[string]bool{}
+	for i := 0; i < 10 && len(received) < 3; i++ {
+		hasMessage, message, err := manager.ReceiveMessage(ctx, 1, 30)
+		if err != nil {
+			t.Fatalf("receive message: %v", err)
+		}
+		if !hasMessage {
+			continue
+		}
+		received[message.Body] = true
+		if err := manager.DeleteMessage(ctx, message.ReceiptHandle); err != nil {
+			t.Errorf("delete message: %v", err)
+		}
+	}
+	for _, body := range []string{"hello localstack", "first", "second"} {
+		if !received[body] {
+			t.Errorf("expected to receive %q, got %v", body, received)
+		}
+	}
+}
@@ -66,6 +66,11 @@ func Setup() {
 		log.Fatal(err)
 	}
 
+	// first, the managers log from the callbacks of their drivers
+	if err = logger.Setup(config.Env.LogLevel); err != nil {
+		log.Fatal(err)
+	}
+
 	if err = database.Setup(config.Env.MongoURI); err != nil {
 		log.Fatalf("database Setup, error:%v", err)
 	}
@@ -121,10 +126,6 @@ func Setup() {
 		}
 	*/
 
-	if err = logger.Setup(config.Env.LogLevel); err != nil {
-		log.Fatal(err)
-	}
-
 	client.Setup()
 
 	if err = router.Setup(); err != nil {
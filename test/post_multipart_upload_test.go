@@ -0,0 +1,219 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-base/internal/app/router"
+	"go-base/internal/pkg/aws/s3"
+	modelHttp "go-base/internal/pkg/model/http"
+	"go-base/internal/pkg/worker"
+)
+
+func setupMultipartMockS3() *s3.MockS3API {
+	mockS3 := &s3.MockS3API{}
+	mockS3.PutObject("upload-bucket", "existing.txt", []byte("existing"), "text/plain")
+	s3.SetInstance(mockS3)
+	return mockS3
+}
+
+func createMultipartUpload(t *testing.T, objectKey string) string {
+	t.Helper()
+
+	w, _ := HttpSendAndMarshalBody(http.MethodPost, "/s3/create-multipart-upload", map[string]string{
+		"bucket_name":  "upload-bucket",
+		"object_key":   objectKey,
+		"content_type": "application/octet-stream",
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+	var response modelHttp.CreateMultipartUploadResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil || response.UploadID == "" {
+		t.Fatalf("expected an upload id, got body=%s", w.Body.String())
+	}
+	return response.UploadID
+}
+
+func Test_MultipartUpload_Complete_Flow(t *testing.T) {
+	mockS3 := setupMultipartMockS3()
+	defer s3.SetInstance(nil) // 清理
+
+	uploadID := createMultipartUpload(t, "big/file.bin")
+
+	// 取得每個 part 的 presigned URL
+	w, _ := HttpSendAndMarshalBody(http.MethodPost, "/s3/presign-upload-parts", map[string]interface{}{
+		"bucket_name":  "upload-bucket",
+		"object_key":   "big/file.bin",
+		"upload_id":    uploadID,
+		"part_numbers": []int{1, 2},
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+	var presigned modelHttp.PresignUploadPartsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &presigned); err != nil || len(presigned.Parts) != 2 {
+		t.Fatalf("expected 2 presigned parts, got body=%s", w.Body.String())
+	}
+
+	// 模擬 client 以 presigned URL 上傳 part (順序顛倒)
+	if _, err := mockS3.UploadPart(uploadID, 2, []byte("world")); err != nil {
+		t.Fatalf("UploadPart failed: %v", err)
+	}
+	if _, err := mockS3.UploadPart(uploadID, 1, []byte("hello ")); err != nil {
+		t.Fatalf("UploadPart failed: %v", err)
+	}
+
+	// 續傳時查詢已上傳的 parts
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/s3/list-parts?bucket_name=upload-bucket&object_key=big/file.bin&upload_id="+uploadID, nil)
+	router.Router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+	var listed modelHttp.ListPartsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &listed); err != nil || len(listed.Parts) != 2 {
+		t.Fatalf("expected 2 uploaded parts, got body=%s", w.Body.String())
+	}
+
+	// 不帶 parts 時使用 S3 已收到的 parts 完成上傳
+	w, _ = HttpSendAndMarshalBody(http.MethodPost, "/s3/complete-multipart-upload", map[string]string{
+		"bucket_name": "upload-bucket",
+		"object_key":  "big/file.bin",
+		"upload_id":   uploadID,
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"part_count":2`) {
+		t.Errorf("expected part_count 2, got body=%s", w.Body.String())
+	}
+
+	data, ok := mockS3.Object("upload-bucket", "big/file.bin")
+	if !ok || string(data) != "hello world" {
+		t.Errorf("expected assembled object 'hello world', got %q (exists=%v)", data, ok)
+	}
+}
+
+func Test_MultipartUpload_Complete_Without_Parts(t *testing.T) {
+	setupMultipartMockS3()
+	defer s3.SetInstance(nil) // 清理
+
+	uploadID := createMultipartUpload(t, "empty.bin")
+
+	w, _ := HttpSendAndMarshalBody(http.MethodPost, "/s3/complete-multipart-upload", map[string]string{
+		"bucket_name": "upload-bucket",
+		"object_key":  "empty.bin",
+		"upload_id":   uploadID,
+	}, nil)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d, body=%s", w.Code, w.Body.String())
+	}
+}
+
+func Test_MultipartUpload_Abort(t *testing.T) {
+	setupMultipartMockS3()
+	defer s3.SetInstance(nil) // 清理
+
+	uploadID := createMultipartUpload(t, "aborted.bin")
+
+	w, _ := HttpSendAndMarshalBody(http.MethodDelete, "/s3/abort-multipart-upload", map[string]string{
+		"bucket_name": "upload-bucket",
+		"object_key":  "aborted.bin",
+		"upload_id":   uploadID,
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+
+	// 已中止的 upload 不能再查詢
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/s3/list-parts?bucket_name=upload-bucket&object_key=aborted.bin&upload_id="+uploadID, nil)
+	router.Router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d, body=%s", w.Code, w.Body.String())
+	}
+}
+
+func Test_MultipartUpload_Invalid_Part_Number(t *testing.T) {
+	setupMultipartMockS3()
+	defer s3.SetInstance(nil) // 清理
+
+	w, _ := HttpSendAndMarshalBody(http.MethodPost, "/s3/presign-upload-parts", map[string]interface{}{
+		"bucket_name":  "upload-bucket",
+		"object_key":   "big/file.bin",
+		"upload_id":    "mock-upload-1",
+		"part_numbers": []int{0},
+	}, nil)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d, body=%s", w.Code, w.Body.String())
+	}
+}
+
+func Test_MultipartUpload_Create_S3_Error(t *testing.T) {
+	mockS3 := setupMultipartMockS3()
+	mockS3.FailOn("CreateMultipartUpload", errMockS3)
+	defer s3.SetInstance(nil) // 清理
+
+	w, _ := HttpSendAndMarshalBody(http.MethodPost, "/s3/create-multipart-upload", map[string]string{
+		"bucket_name": "upload-bucket",
+		"object_key":  "big/file.bin",
+	}, nil)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d, body=%s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "4004") { // S3CreateMultipartUploadFail
+		t.Errorf("expected error code 4004, got body: %s", w.Body.String())
+	}
+}
+
+func Test_MultipartCleanupWorker_Restart(t *testing.T) {
+	mockS3 := setupMultipartMockS3()
+	defer s3.SetInstance(nil) // 清理
+
+	cleanupWorker, err := worker.NewMultipartCleanupWorker(worker.MultipartCleanupWorkerConfig{
+		BucketNames: []string{"upload-bucket"},
+		Interval:    20 * time.Millisecond,
+		MaxAge:      time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewMultipartCleanupWorker failed: %v", err)
+	}
+
+	// 停止後重新啟動，worker 仍須繼續清理過期的 upload
+	for i, objectKey := range []string{"stale-1.bin", "stale-2.bin"} {
+		uploadID := createMultipartUpload(t, objectKey)
+		mockS3.SetUploadInitiated(uploadID, time.Now().Add(-2*time.Hour))
+
+		cleanupWorker.Start(context.Background())
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			uploads, err := mockS3.ListMultipartUploads(context.Background(), "upload-bucket")
+			if err != nil {
+				t.Fatalf("ListMultipartUploads failed: %v", err)
+			}
+			if len(uploads) == 0 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("run %d: expected stale upload to be aborted, %d left", i+1, len(uploads))
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		cleanupWorker.Stop()
+	}
+
+	// 重複 Stop 不可 panic
+	cleanupWorker.Stop()
+	if cleanupWorker.IsRunning() {
+		t.Error("expected worker to be stopped")
+	}
+}
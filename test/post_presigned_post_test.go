@@ -0,0 +1,107 @@
+package test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-base/internal/pkg/aws/s3"
+	modelHttp "go-base/internal/pkg/model/http"
+)
+
+// decodePostConditions 解出 fake 產生的 policy document 中的 conditions
+func decodePostConditions(t *testing.T, policy string) []interface{} {
+	t.Helper()
+
+	raw, err := base64.StdEncoding.DecodeString(policy)
+	if err != nil {
+		t.Fatalf("policy is not base64: %v", err)
+	}
+	var document struct {
+		Expiration string        `json:"expiration"`
+		Conditions []interface{} `json:"conditions"`
+	}
+	if err := json.Unmarshal(raw, &document); err != nil {
+		t.Fatalf("policy is not a policy document: %v", err)
+	}
+	return document.Conditions
+}
+
+func Test_PresignedPost_Conditions(t *testing.T) {
+	s3.SetInstance(&s3.MockS3API{})
+	defer s3.SetInstance(nil) // 清理
+
+	w, _ := HttpSendAndMarshalBody(http.MethodPost, "/s3/presigned-post", map[string]interface{}{
+		"bucket_name":         "upload-bucket",
+		"key_prefix":          "uploads/user-1/",
+		"content_type_prefix": "image/",
+		"min_size":            1,
+		"max_size":            1024,
+		"metadata":            map[string]string{"Owner": "user-1"},
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+
+	var response modelHttp.GetPresignedPostResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if response.Fields["key"] != "uploads/user-1/${filename}" {
+		t.Errorf("expected key field under the prefix, got %q", response.Fields["key"])
+	}
+	if response.Fields["x-amz-meta-owner"] != "user-1" {
+		t.Errorf("expected metadata field, got fields=%v", response.Fields)
+	}
+
+	// 檢查簽入 policy 的 conditions
+	conditions := decodePostConditions(t, response.Fields["policy"])
+	expected := []interface{}{
+		[]interface{}{"content-length-range", float64(1), float64(1024)},
+		[]interface{}{"starts-with", "$Content-Type", "image/"},
+		[]interface{}{"starts-with", "$key", "uploads/user-1/"},
+		map[string]interface{}{"x-amz-meta-owner": "user-1"},
+	}
+	for _, condition := range expected {
+		found := false
+		for _, actual := range conditions {
+			if reflect.DeepEqual(actual, condition) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("expected condition %v, got %v", condition, conditions)
+		}
+	}
+}
+
+func Test_PresignedPost_Invalid_Policy(t *testing.T) {
+	s3.SetInstance(&s3.MockS3API{})
+	defer s3.SetInstance(nil) // 清理
+
+	cases := []struct {
+		name string
+		body map[string]interface{}
+	}{
+		{"key and key_prefix", map[string]interface{}{"bucket_name": "upload-bucket", "key": "a.png", "key_prefix": "uploads/", "max_size": 1024}},
+		{"neither key nor key_prefix", map[string]interface{}{"bucket_name": "upload-bucket", "max_size": 1024}},
+		{"min size above max size", map[string]interface{}{"bucket_name": "upload-bucket", "key": "a.png", "min_size": 2048, "max_size": 1024}},
+		{"expiry above presign expiry", map[string]interface{}{"bucket_name": "upload-bucket", "key": "a.png", "max_size": 1024, "expires_in": int64(s3.PresignURLExpiry.Seconds()) + 1}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, _ := HttpSendAndMarshalBody(http.MethodPost, "/s3/presigned-post", tc.body, nil)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d, body=%s", w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), "4011") { // S3PresignPostInvalidPolicy
+				t.Errorf("expected error code 4011, got body: %s", w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"go-base/internal/pkg/aws/sqs"
+	"go-base/internal/pkg/config"
+	modelHttp "go-base/internal/pkg/model/http"
+)
+
+func setupMockSQS(t *testing.T) *sqs.MockSQSAPI {
+	mockSQS := &sqs.MockSQSAPI{QueueNames: []string{"todo-queue", "notification-queue", "deleted-queue", "todo-dlq"}}
+	mockSQS.DeleteQueue("deleted-queue")
+	sqs.SetInstance(mockSQS)
+	allowSendQueues(t, "todo-queue", "notification-queue", "deleted-queue")
+	return mockSQS
+}
+
+// allowSendQueues 設定 /sqs 可以送出的 queue，第一個為 AWS_SQS_QUEUE_NAME
+func allowSendQueues(t *testing.T, queueNames ...string) {
+	previousName, previousNames := config.Env.AWSSQSQueueName, config.Env.AWSSQSQueueNames
+	config.Env.AWSSQSQueueName, config.Env.AWSSQSQueueNames = queueNames[0], queueNames[1:]
+	t.Cleanup(func() { // 清理
+		config.Env.AWSSQSQueueName, config.Env.AWSSQSQueueNames = previousName, previousNames
+	})
+}
+
+func Test_SendMessage_To_Named_Queue(t *testing.T) {
+	mockSQS := setupMockSQS(t)
+	defer sqs.SetInstance(nil) // 清理
+
+	tests := []struct {
+		name       string
+		queueName  string
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "default queue",
+			queueName:  "todo-queue",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "other allowed queue",
+			queueName:  "notification-queue",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "queue not in allow-list",
+			queueName:  "unknown-queue",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "5001", // SQSQueueNotAllowed
+		},
+		{
+			name:       "queue resolvable but not in send allow-list",
+			queueName:  "todo-dlq",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "5001", // SQSQueueNotAllowed
+		},
+		{
+			name:       "queue does not exist",
+			queueName:  "deleted-queue",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "5002", // SQSQueueNotFound
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, _ := HttpSendAndMarshalBody(http.MethodPost, "/sqs/send-message", map[string]string{
+				"queue_name": tt.queueName,
+				"message":    "hello " + tt.queueName,
+			}, nil)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d, body=%s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantCode != "" && !strings.Contains(w.Body.String(), tt.wantCode) {
+				t.Errorf("expected error code %s, got body: %s", tt.wantCode, w.Body.String())
+			}
+		})
+	}
+
+	if got := mockSQS.Messages("todo-queue"); !reflect.DeepEqual(got, []string{"hello todo-queue"}) {
+		t.Errorf("unexpected messages in todo-queue: %v", got)
+	}
+	if got := mockSQS.Messages("notification-queue"); !reflect.DeepEqual(got, []string{"hello notification-queue"}) {
+		t.Errorf("unexpected messages in notification-queue: %v", got)
+	}
+}
+
+func Test_SendMessages_To_Named_Queue(t *testing.T) {
+	mockSQS := setupMockSQS(t)
+	defer sqs.SetInstance(nil) // 清理
+
+	w, _ := HttpSendAndMarshalBody(http.MethodPost, "/sqs/send-messages", map[string]interface{}{
+		"queue_name": "notification-queue",
+		"messages":   []string{"first", "second"},
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+	var response modelHttp.SendMessagesResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil || !response.Success || response.SuccessCount != 2 {
+		t.Errorf("unexpected response: %s", w.Body.String())
+	}
+	if got := mockSQS.Messages("notification-queue"); !reflect.DeepEqual(got, []string{"first", "second"}) {
+		t.Errorf("unexpected messages in notification-queue: %v", got)
+	}
+	if got := mockSQS.Messages("todo-queue"); len(got) != 0 {
+		t.Errorf("expected nothing in todo-queue, got %v", got)
+	}
+
+	// 不在允許清單的 queue 整批拒絕
+	w, _ = HttpSendAndMarshalBody(http.MethodPost, "/sqs/send-messages", map[string]interface{}{
+		"queue_name": "unknown-queue",
+		"messages":   []string{"first"},
+	}, nil)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "5001") { // SQSQueueNotAllowed
+		t.Errorf("expected 400 with 5001, got %d, body=%s", w.Code, w.Body.String())
+	}
+}
+
+func Test_SendMessage_With_Options(t *testing.T) {
+	mockSQS := setupMockSQS(t)
+	defer sqs.SetInstance(nil) // 清理
+
+	w, _ := HttpSendAndMarshalBody(http.MethodPost, "/sqs/send-message", map[string]interface{}{
+		"queue_name":               "todo-queue",
+		"message":                  "hello",
+		"attributes":               map[string]string{"event": "todo.created"},
+		"delay_seconds":            30,
+		"message_group_id":         "todo-1",
+		"message_deduplication_id": "todo-1-created",
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+	var response modelHttp.SendMessageResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil || !strings.HasPrefix(response.MessageID, "mock-message-") {
+		t.Errorf("expected the message id from SQS, got body=%s", w.Body.String())
+	}
+	want := []sqs.OutgoingMessage{{
+		Body:                   "hello",
+		Attributes:             map[string]string{"event": "todo.created"},
+		DelaySeconds:           30,
+		MessageGroupID:         "todo-1",
+		MessageDeduplicationID: "todo-1-created",
+	}}
+	if got := mockSQS.SentMessages("todo-queue"); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	// delay_seconds 超過 900
+	w, _ = HttpSendAndMarshalBody(http.MethodPost, "/sqs/send-message", map[string]interface{}{
+		"queue_name":    "todo-queue",
+		"message":       "hello",
+		"delay_seconds": 901,
+	}, nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d, body=%s", w.Code, w.Body.String())
+	}
+
+	// 超過 256 KB
+	w, _ = HttpSendAndMarshalBody(http.MethodPost, "/sqs/send-message", map[string]interface{}{
+		"queue_name": "todo-queue",
+		"message":    strings.Repeat("a", sqs.MaxBatchSize+1),
+	}, nil)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "5004") { // SQSMessageTooLarge
+		t.Errorf("expected 400 with 5004, got %d, body=%s", w.Code, w.Body.String())
+	}
+}
+
+func Test_SendMessages_Batches(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           map[string]interface{}
+		setup          func(mockSQS *sqs.MockSQSAPI)
+		wantBatchSizes []int
+		wantFailed     map[int]string // index -> part of the failure reason
+	}{
+		{
+			name: "chunks of ten",
+			body: map[string]interface{}{
+				"messages": numberedMessages(25),
+			},
+			wantBatchSizes: []int{10, 10, 5},
+		},
+		{
+			name: "batch size limit",
+			body: map[string]interface{}{
+				"messages": []string{strings.Repeat("a", 100*1024), strings.Repeat("b", 100*1024), strings.Repeat("c", 100*1024)},
+			},
+			wantBatchSizes: []int{2, 1},
+		},
+		{
+			name: "message too large",
+			body: map[string]interface{}{
+				"messages": []string{"small", strings.Repeat("a", sqs.MaxBatchSize+1)},
+			},
+			wantBatchSizes: []int{1},
+			wantFailed:     map[int]string{1: "exceeds 256 KB"},
+		},
+		{
+			name: "entries with options",
+			body: map[string]interface{}{
+				"messages": []string{"plain"},
+				"entries": []map[string]interface{}{
+					{"message": "with attributes", "attributes": map[string]string{"event": "todo.created"}},
+					{"message": "fifo", "message_group_id": "todo-1", "message_deduplication_id": "todo-1-created"},
+				},
+			},
+			wantBatchSizes: []int{3},
+		},
+		{
+			name: "retries only failed entries",
+			body: map[string]interface{}{
+				"messages": []string{"first", "flaky", "invalid", "last"},
+			},
+			setup: func(mockSQS *sqs.MockSQSAPI) {
+				mockSQS.FailMessage("flaky", &sqs.BatchEntryError{Code: "InternalError", Message: "try again"}, 1)
+				mockSQS.FailMessage("invalid", &sqs.BatchEntryError{Code: "InvalidParameterValue", Message: "bad attribute", SenderFault: true}, 0)
+			},
+			wantBatchSizes: []int{4, 1},
+			wantFailed:     map[int]string{2: "bad attribute"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSQS := setupMockSQS(t)
+			defer sqs.SetInstance(nil) // 清理
+			if tt.setup != nil {
+				tt.setup(mockSQS)
+			}
+
+			tt.body["queue_name"] = "todo-queue"
+			w, _ := HttpSendAndMarshalBody(http.MethodPost, "/sqs/send-messages", tt.body, nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+			}
+			var response modelHttp.SendMessagesResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to parse response: %v, body=%s", err, w.Body.String())
+			}
+
+			if got := mockSQS.BatchSizes("todo-queue"); !reflect.DeepEqual(got, tt.wantBatchSizes) {
+				t.Errorf("expected batches %v, got %v", tt.wantBatchSizes, got)
+			}
+			if response.Success != (len(tt.wantFailed) == 0) || response.SuccessCount != len(response.Results)-len(tt.wantFailed) {
+				t.Errorf("unexpected summary: success=%v success_count=%d", response.Success, response.SuccessCount)
+			}
+			messageIDs := map[string]bool{}
+			for i, result := range response.Results {
+				if result.Index != i {
+					t.Errorf("expected result %d to have index %d, got %d", i, i, result.Index)
+				}
+				if reason, failed := tt.wantFailed[i]; failed {
+					if result.MessageID != "" || !strings.Contains(result.Error, reason) {
+						t.Errorf("expected result %d to fail with %q, got %+v", i, reason, result)
+					}
+					continue
+				}
+				if result.MessageID == "" || result.Error != "" || messageIDs[result.MessageID] {
+					t.Errorf("expected result %d to have a unique message id, got %+v", i, result)
+				}
+				messageIDs[result.MessageID] = true
+			}
+		})
+	}
+}
+
+func numberedMessages(count int) []string {
+	messages := make([]string, count)
+	for i := range messages {
+		messages[i] = "message-" + strconv.Itoa(i)
+	}
+	return messages
+}
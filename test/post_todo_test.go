@@ -1,6 +1,7 @@
 package test
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -8,7 +9,10 @@ import (
 
 	"go-base/internal/app/router"
 	externalAccount "go-base/internal/app/service/external/account"
+	"go-base/internal/pkg/aws/s3"
 	"go-base/internal/pkg/config"
+	"go-base/internal/pkg/database"
+	modelDB "go-base/internal/pkg/model/db"
 
 	"github.com/jarcoal/httpmock"
 )
@@ -282,3 +286,56 @@ func Test_CreateTodo_Success(t *testing.T) {
 		t.Error("expected external API calls")
 	}
 }
+
+func Test_CreateTodo_Records_Attachment_Checksums(t *testing.T) {
+	WithDBCleanup(t)
+
+	mockS3 := &s3.MockS3API{}
+	mockS3.PutObject("attachment-bucket", "todo/spec.pdf", []byte("%PDF-1.4 spec"), "application/pdf")
+	s3.SetInstance(mockS3)
+	defer s3.SetInstance(nil) // 清理
+
+	httpmock.Reset()
+	externalAccount.ClearAuthCache()
+	authURL := config.Env.AuthServiceHost + "/$SS$/Services/OAuth/Token"
+	httpmock.RegisterResponder("POST", authURL,
+		httpmock.NewStringResponder(200, `{"access_token":"test-token-123"}`))
+	vendorURL := config.Env.VendorServiceHost + "/api/vendors/v1/vendors"
+	httpmock.RegisterResponder("POST", vendorURL,
+		httpmock.NewStringResponder(200, `{"vendor_id":"vendor-123"}`))
+	defer httpmock.Reset()
+
+	checksum := s3.ChecksumSHA256([]byte("%PDF-1.4 spec"))
+
+	// checksum 不符時不建立 todo
+	w, _ := HttpSendAndMarshalBody(http.MethodPost, "/todo", map[string]interface{}{
+		"title":       "t1",
+		"description": "d1",
+		"attachments": []map[string]string{{"bucket_name": "attachment-bucket", "object_key": "todo/spec.pdf", "checksum_sha256": s3.ChecksumSHA256([]byte("other"))}},
+	}, nil)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "4033") { // S3ChecksumMismatch
+		t.Fatalf("expected 400 with code 4033, got %d, body=%s", w.Code, w.Body.String())
+	}
+
+	w, _ = HttpSendAndMarshalBody(http.MethodPost, "/todo", map[string]interface{}{
+		"title":       "t1",
+		"description": "d1",
+		"attachments": []map[string]string{{"bucket_name": "attachment-bucket", "object_key": "todo/spec.pdf"}},
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+	var todo modelDB.Todo
+	if err := json.Unmarshal(w.Body.Bytes(), &todo); err != nil {
+		t.Fatalf("failed to parse response: %v, body=%s", err, w.Body.String())
+	}
+	if len(todo.Attachments) != 1 || todo.Attachments[0].ChecksumSHA256 != checksum || todo.Attachments[0].Size != int64(len("%PDF-1.4 spec")) {
+		t.Errorf("unexpected attachments: %+v", todo.Attachments)
+	}
+
+	// 記錄在資料庫中
+	stored, err := database.GetTodo(todo.ID)
+	if err != nil || len(stored.Attachments) != 1 || stored.Attachments[0].ChecksumSHA256 != checksum {
+		t.Errorf("expected the checksum to be stored, got %+v, %v", stored.Attachments, err)
+	}
+}
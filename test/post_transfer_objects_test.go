@@ -0,0 +1,207 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-base/internal/app/router"
+	"go-base/internal/pkg/aws/s3"
+	"go-base/internal/pkg/job"
+	modelHttp "go-base/internal/pkg/model/http"
+
+	"github.com/aws/smithy-go"
+)
+
+type transferJobResponse struct {
+	modelHttp.GetJobResponse
+	Result modelHttp.TransferObjectsResult `json:"result"`
+}
+
+// startTransferJob 呼叫 copy / move / sync 並等待 job 結束
+func startTransferJob(t *testing.T, target string, body map[string]interface{}) transferJobResponse {
+	t.Helper()
+
+	w, _ := HttpSendAndMarshalBody(http.MethodPost, target, body, nil)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d, body=%s", w.Code, w.Body.String())
+	}
+	var started modelHttp.TransferObjectsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &started); err != nil || started.JobID == "" {
+		t.Fatalf("expected a job id, got body=%s", w.Body.String())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	job.Wait(ctx, started.JobID)
+
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/s3/jobs/"+started.JobID, nil)
+	router.Router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+	var response transferJobResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v, body=%s", err, w.Body.String())
+	}
+	if response.Status != string(job.StatusSucceeded) {
+		t.Fatalf("expected job to succeed, got: %+v", response)
+	}
+	return response
+}
+
+func Test_CopyObjects_Between_Buckets(t *testing.T) {
+	mockS3 := &s3.MockS3API{}
+	mockS3.PutObject("source-bucket", "photos/a.jpg", []byte("a"), "image/jpeg")
+	mockS3.PutObject("source-bucket", "photos/b.jpg", []byte("b"), "image/jpeg")
+	mockS3.PutObject("source-bucket", "photos/2024/c.jpg", []byte("c"), "image/jpeg")
+	mockS3.PutObject("source-bucket", "videos/a.mp4", []byte("v"), "video/mp4")
+	mockS3.CreateBucket(context.Background(), "backup-bucket", "us-east-1")
+	s3.SetInstance(mockS3)
+	defer s3.SetInstance(nil) // 清理
+
+	response := startTransferJob(t, "/s3/copy-objects", map[string]interface{}{
+		"source_bucket":      "source-bucket",
+		"source_prefix":      "photos/",
+		"destination_bucket": "backup-bucket",
+		"destination_prefix": "backup/photos/",
+	})
+	if response.Result.Copied != 3 || response.Result.Failed != 0 || response.Total != 3 || response.Processed != 3 {
+		t.Errorf("unexpected job: %+v", response)
+	}
+
+	for _, key := range []string{"backup/photos/a.jpg", "backup/photos/b.jpg", "backup/photos/2024/c.jpg"} {
+		if _, ok := mockS3.Object("backup-bucket", key); !ok {
+			t.Errorf("expected %s to be copied", key)
+		}
+	}
+	if _, ok := mockS3.Object("backup-bucket", "backup/videos/a.mp4"); ok {
+		t.Error("expected objects outside the prefix not to be copied")
+	}
+	if _, ok := mockS3.Object("source-bucket", "photos/a.jpg"); !ok {
+		t.Error("expected copy to keep the source")
+	}
+}
+
+func Test_MoveObjects_Keeps_Sources_That_Failed(t *testing.T) {
+	mockS3 := &s3.MockS3API{}
+	mockS3.PutObject("move-bucket", "inbox/a.txt", []byte("a"), "text/plain")
+	mockS3.PutObject("move-bucket", "inbox/b.txt", []byte("b"), "text/plain")
+	mockS3.FailKey("inbox/b.txt", &smithy.GenericAPIError{Code: "AccessDenied", Message: "Access Denied"})
+	s3.SetInstance(mockS3)
+	defer s3.SetInstance(nil) // 清理
+
+	response := startTransferJob(t, "/s3/move-objects", map[string]interface{}{
+		"source_bucket":      "move-bucket",
+		"source_prefix":      "inbox/",
+		"destination_bucket": "move-bucket",
+		"destination_prefix": "archive/",
+	})
+	if response.Result.Copied != 1 || response.Result.Deleted != 1 || response.Result.Failed != 1 || response.Failed != 1 {
+		t.Errorf("unexpected result: %+v", response.Result)
+	}
+	if len(response.Result.Errors) != 1 || response.Result.Errors[0].SourceKey != "inbox/b.txt" {
+		t.Errorf("unexpected errors: %+v", response.Result.Errors)
+	}
+
+	if _, ok := mockS3.Object("move-bucket", "inbox/a.txt"); ok {
+		t.Error("expected the moved source to be deleted")
+	}
+	if _, ok := mockS3.Object("move-bucket", "archive/a.txt"); !ok {
+		t.Error("expected archive/a.txt to exist")
+	}
+	if _, ok := mockS3.Object("move-bucket", "inbox/b.txt"); !ok {
+		t.Error("expected the failed source to be kept")
+	}
+}
+
+func Test_SyncObjects_Dry_Run_Then_Sync(t *testing.T) {
+	mockS3 := &s3.MockS3API{}
+	mockS3.PutObject("sync-source", "site/index.html", []byte("index"), "text/html")
+	mockS3.PutObject("sync-source", "site/app.js", []byte("app v2"), "text/javascript")
+	mockS3.PutObject("sync-source", "site/new.css", []byte("css"), "text/css")
+	mockS3.PutObject("sync-dest", "site/index.html", []byte("index"), "text/html")
+	mockS3.PutObject("sync-dest", "site/app.js", []byte("app v1"), "text/javascript")
+	s3.SetInstance(mockS3)
+	defer s3.SetInstance(nil) // 清理
+
+	body := map[string]interface{}{
+		"source_bucket":      "sync-source",
+		"source_prefix":      "site/",
+		"destination_bucket": "sync-dest",
+		"destination_prefix": "site/",
+		"dry_run":            true,
+	}
+
+	// dry run 只回報計畫，不寫入任何物件
+	response := startTransferJob(t, "/s3/sync-objects", body)
+	if !response.Result.DryRun || response.Result.Copied != 2 || response.Result.Skipped != 1 || len(response.Result.Planned) != 3 {
+		t.Fatalf("unexpected dry run result: %+v", response.Result)
+	}
+	for _, item := range response.Result.Planned {
+		wantAction := "copy"
+		if item.SourceKey == "site/index.html" {
+			wantAction = "skip"
+		}
+		if item.Action != wantAction {
+			t.Errorf("expected %s for %s, got %s", wantAction, item.SourceKey, item.Action)
+		}
+	}
+	if _, ok := mockS3.Object("sync-dest", "site/new.css"); ok {
+		t.Fatal("expected dry run not to copy anything")
+	}
+
+	body["dry_run"] = false
+	response = startTransferJob(t, "/s3/sync-objects", body)
+	if response.Result.Copied != 2 || response.Result.Skipped != 1 || len(response.Result.Planned) != 0 {
+		t.Errorf("unexpected sync result: %+v", response.Result)
+	}
+	if data, ok := mockS3.Object("sync-dest", "site/app.js"); !ok || string(data) != "app v2" {
+		t.Errorf("expected site/app.js to be updated, got %q", data)
+	}
+	if _, ok := mockS3.Object("sync-dest", "site/new.css"); !ok {
+		t.Error("expected site/new.css to be copied")
+	}
+}
+
+func Test_TransferObjects_Invalid_Requests(t *testing.T) {
+	mockS3 := &s3.MockS3API{}
+	mockS3.PutObject("data-bucket", "data/a.txt", []byte("a"), "text/plain")
+	s3.SetInstance(mockS3)
+	defer s3.SetInstance(nil) // 清理
+
+	tests := []struct {
+		name       string
+		body       map[string]interface{}
+		wantStatus int
+	}{
+		{
+			name:       "destination inside source",
+			body:       map[string]interface{}{"source_bucket": "data-bucket", "source_prefix": "data/", "destination_bucket": "data-bucket", "destination_prefix": "data/backup/"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "missing destination bucket",
+			body:       map[string]interface{}{"source_bucket": "data-bucket", "source_prefix": "data/", "destination_bucket": "missing-bucket"},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "missing source bucket",
+			body:       map[string]interface{}{"source_prefix": "data/", "destination_bucket": "data-bucket"},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, _ := HttpSendAndMarshalBody(http.MethodPost, "/s3/copy-objects", tt.body, nil)
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected %d, got %d, body=%s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
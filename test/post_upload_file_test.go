@@ -0,0 +1,84 @@
+package test
+
+import (
+	"encoding/base64"
+	"net/http"
+	"testing"
+
+	"go-base/internal/pkg/aws/s3"
+)
+
+func Test_UploadFile_Object_Options(t *testing.T) {
+	mockS3 := &s3.MockS3API{}
+	mockS3.PutObject("attachment-bucket", "placeholder", nil, "")
+	s3.SetInstance(mockS3)
+	s3.SetBuckets(map[string]string{s3.BucketAttachments: "attachment-bucket"}, "default-bucket")
+	defer s3.SetInstance(nil)                  // 清理
+	defer s3.SetBuckets(nil, "default-bucket") // 清理
+
+	w, _ := HttpSendAndMarshalBody(http.MethodPost, "/s3/upload-file", map[string]interface{}{
+		"bucket_name":    "attachments", // 透過名稱對應到實際 bucket
+		"object_key":     "docs/report.pdf",
+		"file_data":      base64.StdEncoding.EncodeToString([]byte("%PDF-1.4")),
+		"content_type":   "application/pdf",
+		"storage_class":  "STANDARD_IA",
+		"acl":            "private",
+		"sse_kms_key_id": "alias/attachments",
+		"metadata":       map[string]string{"owner": "alice"},
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+
+	options, ok := mockS3.ObjectOptions("attachment-bucket", "docs/report.pdf")
+	if !ok {
+		t.Fatalf("expected object in the attachments bucket")
+	}
+	if options.ContentType != "application/pdf" || options.StorageClass != "STANDARD_IA" || options.ACL != "private" ||
+		options.SSEKMSKeyID != "alias/attachments" || options.Metadata["owner"] != "alice" {
+		t.Errorf("unexpected object options: %+v", options)
+	}
+}
+
+func Test_UploadFile_Invalid_Storage_Class(t *testing.T) {
+	mockS3 := &s3.MockS3API{}
+	s3.SetInstance(mockS3)
+	defer s3.SetInstance(nil) // 清理
+
+	w, _ := HttpSendAndMarshalBody(http.MethodPost, "/s3/upload-file", map[string]interface{}{
+		"bucket_name":   "attachment-bucket",
+		"object_key":    "docs/report.pdf",
+		"file_data":     base64.StdEncoding.EncodeToString([]byte("%PDF-1.4")),
+		"storage_class": "CHEAP",
+	}, nil)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d, body=%s", w.Code, w.Body.String())
+	}
+}
+
+func Test_CopyToFolder_Replaces_Metadata(t *testing.T) {
+	mockS3 := &s3.MockS3API{}
+	mockS3.PutObject("copy-bucket", "a.txt", []byte("a"), "text/plain")
+	s3.SetInstance(mockS3)
+	defer s3.SetInstance(nil) // 清理
+
+	w, _ := HttpSendAndMarshalBody(http.MethodPost, "/s3/copy-to-folder", map[string]interface{}{
+		"bucket_name":   "copy-bucket",
+		"object_key":    "a.txt",
+		"folder_name":   "archive",
+		"storage_class": "GLACIER_IR",
+		"metadata":      map[string]string{"archived": "true"},
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+
+	options, ok := mockS3.ObjectOptions("copy-bucket", "archive/a.txt")
+	if !ok {
+		t.Fatalf("expected copied object")
+	}
+	if options.StorageClass != "GLACIER_IR" || options.Metadata["archived"] != "true" {
+		t.Errorf("unexpected object options: %+v", options)
+	}
+}
@@ -0,0 +1,246 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-base/internal/app/router"
+	"go-base/internal/pkg/aws/s3"
+	model "go-base/internal/pkg/model"
+	modelHttp "go-base/internal/pkg/model/http"
+)
+
+func setupConfigurationBucket(t *testing.T) *s3.MockS3API {
+	t.Helper()
+
+	mockS3 := &s3.MockS3API{}
+	if err := mockS3.CreateBucket(context.Background(), "config-bucket", "us-east-1"); err != nil {
+		t.Fatalf("failed to create bucket: %v", err)
+	}
+	s3.SetInstance(mockS3)
+	return mockS3
+}
+
+func getBucketConfiguration(t *testing.T, target string, response interface{}) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	router.Router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), response); err != nil {
+		t.Fatalf("failed to parse response: %v, body=%s", err, w.Body.String())
+	}
+}
+
+func Test_BucketLifecycle_Put_Get_Remove(t *testing.T) {
+	setupConfigurationBucket(t)
+	defer s3.SetInstance(nil) // 清理
+
+	var response modelHttp.GetBucketLifecycleResponse
+	getBucketConfiguration(t, "/s3/get-bucket-lifecycle?bucket_name=config-bucket", &response)
+	if len(response.Rules) != 0 {
+		t.Fatalf("expected no rules before put, got: %+v", response.Rules)
+	}
+
+	w, _ := HttpSendAndMarshalBody(http.MethodPut, "/s3/put-bucket-lifecycle", map[string]interface{}{
+		"bucket_name": "config-bucket",
+		"rules": []map[string]interface{}{{
+			"id":                                     "archive-exports",
+			"prefix":                                 "exports/",
+			"status":                                 "Enabled",
+			"expiration_days":                        365,
+			"abort_incomplete_multipart_upload_days": 7,
+			"transitions": []map[string]interface{}{
+				{"days": 30, "storage_class": "STANDARD_IA"},
+				{"days": 90, "storage_class": "GLACIER"},
+			},
+		}},
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+
+	getBucketConfiguration(t, "/s3/get-bucket-lifecycle?bucket_name=config-bucket", &response)
+	if len(response.Rules) != 1 {
+		t.Fatalf("expected 1 rule, got: %+v", response.Rules)
+	}
+	rule := response.Rules[0]
+	if rule.ID != "archive-exports" || rule.Prefix != "exports/" || rule.Status != "Enabled" ||
+		rule.ExpirationDays != 365 || rule.AbortIncompleteMultipartUploadDays != 7 || len(rule.Transitions) != 2 {
+		t.Errorf("unexpected rule: %+v", rule)
+	}
+
+	// 空的 rules 會移除設定
+	w, _ = HttpSendAndMarshalBody(http.MethodPut, "/s3/put-bucket-lifecycle", map[string]interface{}{
+		"bucket_name": "config-bucket",
+		"rules":       []interface{}{},
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+	getBucketConfiguration(t, "/s3/get-bucket-lifecycle?bucket_name=config-bucket", &response)
+	if len(response.Rules) != 0 {
+		t.Errorf("expected no rules after remove, got: %+v", response.Rules)
+	}
+}
+
+func Test_BucketLifecycle_Invalid_Rules(t *testing.T) {
+	mockS3 := setupConfigurationBucket(t)
+	defer s3.SetInstance(nil) // 清理
+
+	tests := []struct {
+		name     string
+		rule     map[string]interface{}
+		wantCode string
+	}{
+		{
+			name:     "unknown status",
+			rule:     map[string]interface{}{"id": "r1", "status": "On", "expiration_days": 30},
+			wantCode: "",
+		},
+		{
+			name:     "unknown storage class",
+			rule:     map[string]interface{}{"id": "r1", "status": "Enabled", "transitions": []map[string]interface{}{{"days": 30, "storage_class": "TAPE"}}},
+			wantCode: "",
+		},
+		{
+			name:     "no action",
+			rule:     map[string]interface{}{"id": "r1", "status": "Enabled", "prefix": "tmp/"},
+			wantCode: model.S3InvalidLifecycleConfiguration,
+		},
+		{
+			name:     "infrequent access before 30 days",
+			rule:     map[string]interface{}{"id": "r1", "status": "Enabled", "transitions": []map[string]interface{}{{"days": 7, "storage_class": "STANDARD_IA"}}},
+			wantCode: model.S3InvalidLifecycleConfiguration,
+		},
+		{
+			name:     "expiration before transition",
+			rule:     map[string]interface{}{"id": "r1", "status": "Enabled", "expiration_days": 60, "transitions": []map[string]interface{}{{"days": 90, "storage_class": "GLACIER"}}},
+			wantCode: model.S3InvalidLifecycleConfiguration,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, _ := HttpSendAndMarshalBody(http.MethodPut, "/s3/put-bucket-lifecycle", map[string]interface{}{
+				"bucket_name": "config-bucket",
+				"rules":       []map[string]interface{}{tt.rule},
+			}, nil)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d, body=%s", w.Code, w.Body.String())
+			}
+			if tt.wantCode != "" && !strings.Contains(w.Body.String(), tt.wantCode) {
+				t.Errorf("expected error code %s, got body: %s", tt.wantCode, w.Body.String())
+			}
+		})
+	}
+
+	// 設定失敗時不應該寫入 bucket
+	rules, err := mockS3.GetBucketLifecycle(context.Background(), "config-bucket")
+	if err != nil || len(rules) != 0 {
+		t.Errorf("expected no rules to be stored, got: %+v, err=%v", rules, err)
+	}
+}
+
+func Test_BucketLifecycle_Bucket_Not_Found(t *testing.T) {
+	setupConfigurationBucket(t)
+	defer s3.SetInstance(nil) // 清理
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/s3/get-bucket-lifecycle?bucket_name=missing-bucket", nil)
+	router.Router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d, body=%s", w.Code, w.Body.String())
+	}
+}
+
+func Test_BucketCORS_Put_Get(t *testing.T) {
+	setupConfigurationBucket(t)
+	defer s3.SetInstance(nil) // 清理
+
+	w, _ := HttpSendAndMarshalBody(http.MethodPut, "/s3/put-bucket-cors", map[string]interface{}{
+		"bucket_name": "config-bucket",
+		"rules": []map[string]interface{}{{
+			"allowed_origins": []string{"https://*.example.com"},
+			"allowed_methods": []string{"GET", "PUT"},
+			"allowed_headers": []string{"*"},
+			"expose_headers":  []string{"ETag"},
+			"max_age_seconds": 3000,
+		}},
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+
+	var response modelHttp.GetBucketCORSResponse
+	getBucketConfiguration(t, "/s3/get-bucket-cors?bucket_name=config-bucket", &response)
+	if len(response.Rules) != 1 {
+		t.Fatalf("expected 1 rule, got: %+v", response.Rules)
+	}
+	rule := response.Rules[0]
+	if len(rule.AllowedMethods) != 2 || rule.AllowedOrigins[0] != "https://*.example.com" || rule.MaxAgeSeconds != 3000 {
+		t.Errorf("unexpected rule: %+v", rule)
+	}
+}
+
+func Test_BucketCORS_Invalid_Rules(t *testing.T) {
+	setupConfigurationBucket(t)
+	defer s3.SetInstance(nil) // 清理
+
+	tests := []struct {
+		name string
+		rule map[string]interface{}
+	}{
+		{name: "no origins", rule: map[string]interface{}{"allowed_methods": []string{"GET"}}},
+		{name: "unknown method", rule: map[string]interface{}{"allowed_origins": []string{"*"}, "allowed_methods": []string{"PATCH"}}},
+		{name: "two wildcards", rule: map[string]interface{}{"allowed_origins": []string{"https://*.*.example.com"}, "allowed_methods": []string{"GET"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, _ := HttpSendAndMarshalBody(http.MethodPut, "/s3/put-bucket-cors", map[string]interface{}{
+				"bucket_name": "config-bucket",
+				"rules":       []map[string]interface{}{tt.rule},
+			}, nil)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("expected 400, got %d, body=%s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func Test_PublicAccessBlock_Put_Get(t *testing.T) {
+	setupConfigurationBucket(t)
+	defer s3.SetInstance(nil) // 清理
+
+	var response modelHttp.GetPublicAccessBlockResponse
+	getBucketConfiguration(t, "/s3/get-public-access-block?bucket_name=config-bucket", &response)
+	if response.BlockPublicAcls || response.BlockPublicPolicy {
+		t.Fatalf("expected everything off before put, got: %+v", response)
+	}
+
+	w, _ := HttpSendAndMarshalBody(http.MethodPut, "/s3/put-public-access-block", map[string]interface{}{
+		"bucket_name":             "config-bucket",
+		"block_public_acls":       true,
+		"ignore_public_acls":      true,
+		"block_public_policy":     true,
+		"restrict_public_buckets": false,
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+
+	getBucketConfiguration(t, "/s3/get-public-access-block?bucket_name=config-bucket", &response)
+	if !response.BlockPublicAcls || !response.IgnorePublicAcls || !response.BlockPublicPolicy || response.RestrictPublicBuckets {
+		t.Errorf("unexpected public access block: %+v", response)
+	}
+}
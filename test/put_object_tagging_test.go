@@ -0,0 +1,151 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-base/internal/app/router"
+	"go-base/internal/pkg/aws/s3"
+	modelHttp "go-base/internal/pkg/model/http"
+)
+
+func getObjectTags(t *testing.T, query string) map[string]string {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/s3/get-object-tagging?"+query, nil)
+	router.Router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+	var response modelHttp.GetObjectTaggingResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v, body=%s", err, w.Body.String())
+	}
+	return response.Tags
+}
+
+func Test_ObjectTagging_Put_Get_Delete(t *testing.T) {
+	mockS3 := &s3.MockS3API{}
+	mockS3.PutObject("tag-bucket", "icons/a.png", []byte("png"), "image/png")
+	s3.SetInstance(mockS3)
+	defer s3.SetInstance(nil) // 清理
+
+	w, _ := HttpSendAndMarshalBody(http.MethodPut, "/s3/put-object-tagging", map[string]interface{}{
+		"bucket_name": "tag-bucket",
+		"object_key":  "icons/a.png",
+		"tags":        map[string]string{"lifecycle": "archive", "owner": "team-a"},
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+
+	tags := getObjectTags(t, "bucket_name=tag-bucket&object_key=icons/a.png")
+	if len(tags) != 2 || tags["lifecycle"] != "archive" || tags["owner"] != "team-a" {
+		t.Errorf("unexpected tags: %v", tags)
+	}
+
+	w, _ = HttpSendAndMarshalBody(http.MethodDelete, "/s3/delete-object-tagging", map[string]string{
+		"bucket_name": "tag-bucket",
+		"object_key":  "icons/a.png",
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+
+	if tags := getObjectTags(t, "bucket_name=tag-bucket&object_key=icons/a.png"); len(tags) != 0 {
+		t.Errorf("expected no tags after delete, got: %v", tags)
+	}
+}
+
+func Test_ObjectTagging_Too_Many_Tags(t *testing.T) {
+	mockS3 := &s3.MockS3API{}
+	mockS3.PutObject("tag-bucket", "icons/a.png", []byte("png"), "image/png")
+	s3.SetInstance(mockS3)
+	defer s3.SetInstance(nil) // 清理
+
+	// S3 最多允許 10 個 tag
+	tags := map[string]string{}
+	for i := 0; i < 11; i++ {
+		tags[fmt.Sprintf("key-%d", i)] = "value"
+	}
+	w, _ := HttpSendAndMarshalBody(http.MethodPut, "/s3/put-object-tagging", map[string]interface{}{
+		"bucket_name": "tag-bucket",
+		"object_key":  "icons/a.png",
+		"tags":        tags,
+	}, nil)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d, body=%s", w.Code, w.Body.String())
+	}
+}
+
+func Test_ObjectTagging_Not_Found(t *testing.T) {
+	mockS3 := &s3.MockS3API{}
+	mockS3.PutObject("tag-bucket", "icons/a.png", []byte("png"), "image/png")
+	s3.SetInstance(mockS3)
+	defer s3.SetInstance(nil) // 清理
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/s3/get-object-tagging?bucket_name=tag-bucket&object_key=icons/missing.png", nil)
+	router.Router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d, body=%s", w.Code, w.Body.String())
+	}
+}
+
+func Test_ReplaceObjectMetadata(t *testing.T) {
+	mockS3 := &s3.MockS3API{}
+	s3.SetInstance(mockS3)
+	defer s3.SetInstance(nil) // 清理
+
+	ctx := httptest.NewRequest(http.MethodGet, "/", nil).Context()
+	mockS3.PutObject("meta-bucket", "placeholder", nil, "")
+	if err := mockS3.UploadFile(ctx, "meta-bucket", "icons/a.png", []byte("png"), s3.ObjectOptions{
+		ContentType:  "image/png",
+		Metadata:     map[string]string{"old": "value"},
+		StorageClass: "STANDARD_IA",
+	}); err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+
+	w, _ := HttpSendAndMarshalBody(http.MethodPut, "/s3/replace-object-metadata", map[string]interface{}{
+		"bucket_name": "meta-bucket",
+		"object_key":  "icons/a.png",
+		"metadata":    map[string]string{"new": "value"},
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+
+	options, _ := mockS3.ObjectOptions("meta-bucket", "icons/a.png")
+	if options.Metadata["new"] != "value" || options.Metadata["old"] != "" {
+		t.Errorf("expected metadata to be replaced, got: %v", options.Metadata)
+	}
+	// 未指定的屬性沿用原本的值
+	if options.ContentType != "image/png" || options.StorageClass != "STANDARD_IA" {
+		t.Errorf("expected content type and storage class to be kept, got: %+v", options)
+	}
+}
+
+func Test_ReplaceObjectMetadata_Not_Found(t *testing.T) {
+	mockS3 := &s3.MockS3API{}
+	mockS3.PutObject("meta-bucket", "placeholder", nil, "")
+	s3.SetInstance(mockS3)
+	defer s3.SetInstance(nil) // 清理
+
+	w, _ := HttpSendAndMarshalBody(http.MethodPut, "/s3/replace-object-metadata", map[string]interface{}{
+		"bucket_name": "meta-bucket",
+		"object_key":  "icons/missing.png",
+		"metadata":    map[string]string{"new": "value"},
+	}, nil)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d, body=%s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,318 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"go-base/internal/pkg/queue"
+	"go-base/test/container"
+
+	"github.com/nats-io/nats.go"
+)
+
+// setupJetStream 啟動內嵌 NATS server，建立 EVENTS stream 並回傳連上的 queue.Manager
+func setupJetStream(t *testing.T) (*queue.Manager, nats.JetStreamContext) {
+	natsServer, err := container.SetupNats(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to start NATS: %v", err)
+	}
+	t.Cleanup(natsServer.Terminate) // 清理
+
+	manager := &queue.Manager{}
+	if err := manager.Setup(queue.Config{
+		Url:     natsServer.URI,
+		Streams: []nats.StreamConfig{{Name: "EVENTS", Subjects: []string{"events.>"}, Storage: nats.MemoryStorage}},
+	}); err != nil {
+		t.Fatalf("failed to set up queue: %v", err)
+	}
+	t.Cleanup(func() { manager.Close(context.Background()) }) // 清理
+
+	// 另一條連線用來檢查 stream 與 consumer 狀態
+	nc, err := nats.Connect(natsServer.URI)
+	if err != nil {
+		t.Fatalf("failed to connect to NATS: %v", err)
+	}
+	t.Cleanup(nc.Close) // 清理
+	js, err := nc.JetStream()
+	if err != nil {
+		t.Fatalf("failed to get JetStream: %v", err)
+	}
+	return manager, js
+}
+
+// receiver 收集 handler 處理過的訊息
+type receiver struct {
+	mu       sync.Mutex
+	received []string
+	notify   chan struct{}
+}
+
+func newReceiver() *receiver {
+	return &receiver{notify: make(chan struct{}, 100)}
+}
+
+func (r *receiver) add(data string) {
+	r.mu.Lock()
+	r.received = append(r.received, data)
+	r.mu.Unlock()
+	r.notify <- struct{}{}
+}
+
+// wait 等到收到 n 則訊息，回傳排序後的內容
+func (r *receiver) wait(t *testing.T, n int) []string {
+	t.Helper()
+	timeout := time.After(10 * time.Second)
+	for {
+		r.mu.Lock()
+		got := append([]string(nil), r.received...)
+		r.mu.Unlock()
+		if len(got) >= n {
+			sort.Strings(got)
+			return got
+		}
+		select {
+		case <-r.notify:
+		case <-timeout:
+			t.Fatalf("expected %d messages, got %v", n, got)
+		}
+	}
+}
+
+func decodeString(t *testing.T, msg *nats.Msg) string {
+	var data string
+	if err := json.Unmarshal(msg.Data, &data); err != nil {
+		t.Errorf("failed to decode %s: %v", msg.Data, err)
+	}
+	return data
+}
+
+func Test_JetStream_PublishMsg(t *testing.T) {
+	manager, js := setupJetStream(t)
+	ctx := context.Background()
+
+	ack, err := manager.PublishMsg(ctx, "events.created", "first", queue.PublishOptions{
+		MsgID:  "event-1",
+		Header: nats.Header{"trace-id": []string{"trace-1"}},
+	})
+	if err != nil || ack.Stream != "EVENTS" || ack.Duplicate {
+		t.Fatalf("failed to publish: %+v %v", ack, err)
+	}
+
+	// 同一個 MsgID 重複發布會被 stream 丟掉
+	ack, err = manager.PublishMsg(ctx, "events.created", "first again", queue.PublishOptions{MsgID: "event-1"})
+	if err != nil || !ack.Duplicate {
+		t.Errorf("expected a duplicate, got %+v %v", ack, err)
+	}
+	if _, err := manager.PublishMsg(ctx, "events.created", "second", queue.PublishOptions{}); err != nil {
+		t.Errorf("failed to publish without MsgID: %v", err)
+	}
+
+	info, err := js.StreamInfo("EVENTS")
+	if err != nil || info.State.Msgs != 2 {
+		t.Fatalf("expected 2 messages in the stream, got %+v %v", info, err)
+	}
+	msg, err := js.GetMsg("EVENTS", 1)
+	if err != nil {
+		t.Fatalf("failed to get the message: %v", err)
+	}
+	if msg.Header.Get(nats.MsgIdHdr) != "event-1" || msg.Header.Get(queue.EventIDHeader) != "event-1" || msg.Header.Get("trace-id") != "trace-1" {
+		t.Errorf("unexpected headers: %v", msg.Header)
+	}
+
+	// 沒有 stream 的 subject
+	if _, err := manager.PublishMsg(ctx, "unknown.created", "lost", queue.PublishOptions{}); err == nil {
+		t.Error("expected publishing without a stream to fail")
+	}
+}
+
+func Test_JetStream_Pull_Consumer(t *testing.T) {
+	manager, js := setupJetStream(t)
+	ctx := context.Background()
+
+	r := newReceiver()
+	attempts := map[string]int{}
+	var mu sync.Mutex
+	_, err := manager.SubscribePull(queue.ConsumerConfig{
+		Stream:  "EVENTS",
+		Durable: "pull-worker",
+		Subject: "events.created",
+	}, func(ctx context.Context, msg *nats.Msg) error {
+		data := decodeString(t, msg)
+		mu.Lock()
+		attempts[data]++
+		attempt := attempts[data]
+		mu.Unlock()
+		r.add(data)
+
+		switch {
+		case data == "flaky" && attempt == 1:
+			return errors.New("temporary failure")
+		case data == "delayed" && attempt == 1:
+			return queue.Nak(100*time.Millisecond, errors.New("not yet"))
+		case data == "invalid":
+			return queue.Term(errors.New("can never be handled"))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	for _, data := range []string{"ok", "flaky", "delayed", "invalid"} {
+		if _, err := manager.PublishMsg(ctx, "events.created", data, queue.PublishOptions{}); err != nil {
+			t.Fatalf("failed to publish: %v", err)
+		}
+	}
+	manager.PublishMsg(ctx, "events.deleted", "filtered", queue.PublishOptions{})
+
+	// 失敗的訊息重送一次，terminate 的不重送
+	want := []string{"delayed", "delayed", "flaky", "flaky", "invalid", "ok"}
+	if got := r.wait(t, len(want)); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	time.Sleep(300 * time.Millisecond)
+	if got := r.wait(t, len(want)); len(got) != len(want) {
+		t.Errorf("expected no more deliveries, got %v", got)
+	}
+
+	info, err := js.ConsumerInfo("EVENTS", "pull-worker")
+	if err != nil || info.NumAckPending != 0 || info.NumPending != 0 {
+		t.Errorf("expected every message settled, got %+v %v", info, err)
+	}
+}
+
+func Test_JetStream_Push_Consumer_Resumes(t *testing.T) {
+	manager, _ := setupJetStream(t)
+	ctx := context.Background()
+	config := queue.ConsumerConfig{Stream: "EVENTS", Durable: "push-worker"}
+
+	r := newReceiver()
+	handler := func(ctx context.Context, msg *nats.Msg) error {
+		r.add(decodeString(t, msg))
+		return nil
+	}
+	consumer, err := manager.SubscribePush(config, handler)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	manager.PublishMsg(ctx, "events.created", "first", queue.PublishOptions{})
+	manager.PublishMsg(ctx, "events.updated", "second", queue.PublishOptions{})
+	if got := r.wait(t, 2); !reflect.DeepEqual(got, []string{"first", "second"}) {
+		t.Fatalf("unexpected messages: %v", got)
+	}
+
+	// 停止後發布的訊息在重新訂閱時收到，已 ack 的不重送
+	if err := consumer.Stop(); err != nil {
+		t.Fatalf("failed to stop: %v", err)
+	}
+	manager.PublishMsg(ctx, "events.created", "third", queue.PublishOptions{})
+	if _, err := manager.SubscribePush(config, handler); err != nil {
+		t.Fatalf("failed to subscribe again: %v", err)
+	}
+	if got := r.wait(t, 3); !reflect.DeepEqual(got, []string{"first", "second", "third"}) {
+		t.Errorf("expected to resume with the third message, got %v", got)
+	}
+}
+
+func Test_JetStream_Max_Deliver(t *testing.T) {
+	manager, _ := setupJetStream(t)
+
+	type givenUp struct {
+		data       string
+		deliveries int
+	}
+	exhausted := make(chan givenUp, 1)
+	r := newReceiver()
+	_, err := manager.SubscribePull(queue.ConsumerConfig{
+		Stream:     "EVENTS",
+		Durable:    "failing-worker",
+		MaxDeliver: 2,
+		OnMaxDeliver: func(ctx context.Context, msg *nats.RawStreamMsg, deliveries int) {
+			var data string
+			json.Unmarshal(msg.Data, &data)
+			exhausted <- givenUp{data: data, deliveries: deliveries}
+		},
+	}, func(ctx context.Context, msg *nats.Msg) error {
+		r.add(decodeString(t, msg))
+		return errors.New("always failing")
+	})
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	manager.PublishMsg(context.Background(), "events.created", "poison", queue.PublishOptions{})
+	if got := r.wait(t, 2); !reflect.DeepEqual(got, []string{"poison", "poison"}) {
+		t.Errorf("expected 2 deliveries, got %v", got)
+	}
+	select {
+	case got := <-exhausted:
+		if got != (givenUp{data: "poison", deliveries: 2}) {
+			t.Errorf("unexpected advisory: %+v", got)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected the max deliveries advisory")
+	}
+}
+
+func Test_JetStream_Close_Drains(t *testing.T) {
+	manager, js := setupJetStream(t)
+
+	started := make(chan struct{})
+	var handled sync.WaitGroup
+	handled.Add(1)
+	_, err := manager.SubscribePull(queue.ConsumerConfig{Stream: "EVENTS", Durable: "slow-worker"}, func(ctx context.Context, msg *nats.Msg) error {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		handled.Done()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	manager.PublishMsg(context.Background(), "events.created", "slow", queue.PublishOptions{})
+	<-started
+
+	// 關閉時等處理中的訊息完成並 ack
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := manager.Close(ctx); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+	handled.Wait()
+
+	info, err := js.ConsumerInfo("EVENTS", "slow-worker")
+	if err != nil || info.NumAckPending != 0 || info.AckFloor.Stream != 1 {
+		t.Errorf("expected the message acked, got %+v %v", info, err)
+	}
+}
+
+func Test_JetStream_Setup_Stream_Fails(t *testing.T) {
+	natsServer, err := container.SetupNats(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to start NATS: %v", err)
+	}
+	t.Cleanup(natsServer.Terminate) // 清理
+
+	manager := &queue.Manager{}
+	err = manager.Setup(queue.Config{
+		Url:     natsServer.URI,
+		Streams: []nats.StreamConfig{{Name: "INVALID.NAME", Subjects: []string{"invalid.>"}}},
+	})
+	if err == nil {
+		t.Fatal("expected an invalid stream to fail")
+	}
+
+	// 失敗時不保留已關閉的連線
+	if err := manager.Ping(context.Background()); err == nil || err.Error() != "queue not set up" {
+		t.Errorf("expected the queue not set up, got %v", err)
+	}
+	if err := manager.Close(context.Background()); err != nil {
+		t.Errorf("expected closing to be a no-op, got %v", err)
+	}
+}
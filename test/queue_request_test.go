@@ -0,0 +1,215 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-base/internal/app/handler"
+	"go-base/internal/app/router"
+	externalAccount "go-base/internal/app/service/external/account"
+	"go-base/internal/pkg/config"
+	"go-base/internal/pkg/dedupe"
+	modelDB "go-base/internal/pkg/model/db"
+	modelHttp "go-base/internal/pkg/model/http"
+	"go-base/internal/pkg/queue"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/nats-io/nats.go"
+)
+
+type echoRequest struct {
+	Text string `json:"text"`
+}
+
+type echoResponse struct {
+	Echo string `json:"echo"`
+}
+
+func Test_Queue_QueueSubscribe(t *testing.T) {
+	manager, _ := setupJetStream(t)
+
+	// 同一個 queue group 的訊息只給其中一個 subscriber
+	var mu sync.Mutex
+	received := map[string]int{}
+	for _, name := range []string{"first", "second"} {
+		name := name
+		if _, err := manager.QueueSubscribe("jobs.run", "runners", func(sub string, msg []byte) {
+			mu.Lock()
+			defer mu.Unlock()
+			received[name]++
+		}); err != nil {
+			t.Fatalf("failed to subscribe: %v", err)
+		}
+	}
+	for i := 0; i < 20; i++ {
+		if err := manager.Publish("jobs.run", i); err != nil {
+			t.Fatalf("failed to publish: %v", err)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		total := received["first"] + received["second"]
+		mu.Unlock()
+		if total >= 20 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(100 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if received["first"]+received["second"] != 20 {
+		t.Errorf("expected every message received once, got %v", received)
+	}
+}
+
+func Test_Queue_Request_Respond(t *testing.T) {
+	manager, _ := setupJetStream(t)
+	ctx := context.Background()
+
+	_, err := manager.QueueSubscribeHandler("echo", "echoers", queue.Respond(func(ctx context.Context, request echoRequest) (echoResponse, error) {
+		switch request.Text {
+		case "missing":
+			return echoResponse{}, &queue.ReplyError{Status: http.StatusNotFound, Code: "1002"}
+		case "broken":
+			return echoResponse{}, errors.New("database is down")
+		case "slow":
+			time.Sleep(500 * time.Millisecond)
+		}
+		return echoResponse{Echo: request.Text}, nil
+	}))
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	msg, err := manager.Request(ctx, "echo", echoRequest{Text: "hello"})
+	if err != nil {
+		t.Fatalf("failed to request: %v", err)
+	}
+	var response echoResponse
+	if err := queue.DecodeReply(msg, &response); err != nil || response.Echo != "hello" {
+		t.Errorf("unexpected reply: %+v %v, body=%s", response, err, msg.Data)
+	}
+
+	tests := []struct {
+		name       string
+		payload    interface{}
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "handler error", payload: echoRequest{Text: "missing"}, wantStatus: http.StatusNotFound, wantCode: "1002"},
+		{name: "unexpected error", payload: echoRequest{Text: "broken"}, wantStatus: http.StatusInternalServerError, wantCode: "Internal Server Error"},
+		{name: "malformed request", payload: "not an object", wantStatus: http.StatusBadRequest, wantCode: "Bad Request"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := manager.Request(ctx, "echo", tt.payload)
+			if err != nil {
+				t.Fatalf("failed to request: %v", err)
+			}
+			var replyErr *queue.ReplyError
+			if err := queue.DecodeReply(msg, &response); !errors.As(err, &replyErr) || replyErr.Status != tt.wantStatus || replyErr.Code != tt.wantCode {
+				t.Errorf("expected %d %s, got %v", tt.wantStatus, tt.wantCode, err)
+			}
+			// 內部錯誤不回傳細節
+			if strings.Contains(string(msg.Data), "database is down") {
+				t.Errorf("expected no internal details, got %s", msg.Data)
+			}
+		})
+	}
+
+	// 逾時
+	timeoutCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if _, err := manager.Request(timeoutCtx, "echo", echoRequest{Text: "slow"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+	if _, err := manager.Request(ctx, "nobody.listens", echoRequest{}); !errors.Is(err, nats.ErrNoResponders) {
+		t.Errorf("expected no responders, got %v", err)
+	}
+}
+
+func Test_Todo_Over_Nats(t *testing.T) {
+	WithDBCleanup(t)
+	manager, _ := setupJetStream(t)
+	// 與 main 相同，經過 dedupe middleware；沒有 event id 的 request 每次都處理
+	if err := router.SetupNats(manager, queue.DedupeMiddleware(dedupe.NewMemoryStore(dedupe.Config{}))); err != nil {
+		t.Fatalf("failed to set up NATS routes: %v", err)
+	}
+	ctx := context.Background()
+
+	httpmock.Reset()
+	externalAccount.ClearAuthCache()
+	httpmock.RegisterResponder("POST", config.Env.AuthServiceHost+"/$SS$/Services/OAuth/Token",
+		httpmock.NewStringResponder(200, `{"access_token":"test-token-123"}`))
+	httpmock.RegisterResponder("POST", config.Env.VendorServiceHost+"/api/vendors/v1/vendors",
+		httpmock.NewStringResponder(200, `{"vendor_id":"vendor-123"}`))
+	defer httpmock.Reset()
+
+	request := func(subject string, payload interface{}, v interface{}) error {
+		t.Helper()
+		msg, err := manager.Request(ctx, subject, payload)
+		if err != nil {
+			t.Fatalf("failed to request %s: %v", subject, err)
+		}
+		return queue.DecodeReply(msg, v)
+	}
+
+	var created modelDB.Todo
+	if err := request(handler.TodoCreateSubject, modelHttp.CreateTodoRequest{Title: "t1", Description: "d1"}, &created); err != nil {
+		t.Fatalf("failed to create todo: %v", err)
+	}
+	if created.ID == "" || created.Title != "vendor-123" || created.Description != "d1" {
+		t.Errorf("unexpected todo: %+v", created)
+	}
+
+	var got modelDB.Todo
+	if err := request(handler.TodoGetSubject, modelHttp.TodoIDRequest{ID: created.ID}, &got); err != nil || got.ID != created.ID {
+		t.Errorf("expected the created todo, got %+v %v", got, err)
+	}
+
+	var todos []modelDB.Todo
+	if err := request(handler.TodoListSubject, nil, &todos); err != nil || len(todos) != 1 || todos[0].ID != created.ID {
+		t.Errorf("expected the created todo listed, got %+v %v", todos, err)
+	}
+
+	update := modelHttp.UpdateTodoByIDRequest{ID: created.ID, UpdateTodoRequest: modelHttp.UpdateTodoRequest{Title: "t2", Description: "d2", Completed: true}}
+	if err := request(handler.TodoUpdateSubject, update, nil); err != nil {
+		t.Errorf("failed to update todo: %v", err)
+	}
+	if err := request(handler.TodoGetSubject, modelHttp.TodoIDRequest{ID: created.ID}, &got); err != nil || got.Title != "t2" || !got.Completed {
+		t.Errorf("expected the updated todo, got %+v %v", got, err)
+	}
+
+	if err := request(handler.TodoDeleteSubject, modelHttp.TodoIDRequest{ID: created.ID}, nil); err != nil {
+		t.Errorf("failed to delete todo: %v", err)
+	}
+	todos = nil
+	if err := request(handler.TodoListSubject, nil, &todos); err != nil || len(todos) != 0 {
+		t.Errorf("expected no todo left, got %+v %v", todos, err)
+	}
+
+	// 與 HTTP 相同的驗證規則
+	var replyErr *queue.ReplyError
+	if err := request(handler.TodoCreateSubject, map[string]string{"title": "only-title"}, nil); !errors.As(err, &replyErr) || replyErr.Status != http.StatusBadRequest {
+		t.Errorf("expected 400, got %v", err)
+	}
+	if err := request(handler.TodoGetSubject, modelHttp.TodoIDRequest{}, nil); !errors.As(err, &replyErr) || replyErr.Status != http.StatusBadRequest {
+		t.Errorf("expected 400, got %v", err)
+	}
+	// 巢狀欄位也以同一個 validator 檢查
+	invalidAttachment := modelHttp.CreateTodoRequest{Title: "t1", Description: "d1", Attachments: []modelHttp.TodoAttachment{{ObjectKey: "a.txt"}}}
+	if err := request(handler.TodoCreateSubject, invalidAttachment, nil); !errors.As(err, &replyErr) || replyErr.Status != http.StatusBadRequest {
+		t.Errorf("expected 400 over NATS, got %v", err)
+	}
+	if w, _ := HttpSendAndMarshalBody(http.MethodPost, "/todo", invalidAttachment, nil); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 over HTTP, got %d, body=%s", w.Code, w.Body.String())
+	}
+}
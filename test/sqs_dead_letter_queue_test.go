@@ -0,0 +1,239 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"go-base/internal/pkg/aws/sqs"
+	"go-base/internal/pkg/config"
+	modelHttp "go-base/internal/pkg/model/http"
+)
+
+// setupDeadLetterQueue 建立含 todo-dlq 的 mock，並把 todo-dlq 設為 dead-letter queue
+func setupDeadLetterQueue(t *testing.T) *sqs.MockSQSAPI {
+	mockSQS := &sqs.MockSQSAPI{QueueNames: []string{"todo-queue", "notification-queue", "todo-dlq"}}
+	sqs.SetInstance(mockSQS)
+	previous := config.Env.AWSSQSDeadLetterQueueNames
+	config.Env.AWSSQSDeadLetterQueueNames = []string{"todo-dlq"}
+	t.Cleanup(func() { // 清理
+		sqs.SetInstance(nil)
+		config.Env.AWSSQSDeadLetterQueueNames = previous
+	})
+	return mockSQS
+}
+
+// deadLetter 以 worker 移動訊息的格式放進 todo-dlq
+func deadLetter(mockSQS *sqs.MockSQSAPI, body, sourceQueue string, attributes map[string]string) string {
+	message := sqs.DeadLetter(sqs.Message{Body: body, Attributes: attributes, ReceiveCount: 5}, sourceQueue, errors.New("processing failed"))
+	return mockSQS.SendTo("todo-dlq", message)
+}
+
+func Test_ListDeadLetterMessages(t *testing.T) {
+	mockSQS := setupDeadLetterQueue(t)
+	messageID := deadLetter(mockSQS, "first", "todo-queue", map[string]string{"event": "todo.created"})
+	deadLetter(mockSQS, "second", "todo-queue", nil)
+
+	w, _ := HttpGet("/sqs/dlq/messages?queue_name=todo-dlq", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+	var response modelHttp.ListDeadLetterMessagesResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil || len(response.Messages) != 2 {
+		t.Fatalf("expected 2 messages, got body=%s", w.Body.String())
+	}
+	message := response.Messages[0]
+	if message.MessageID != messageID || message.Body != "first" || message.SourceQueueName != "todo-queue" ||
+		message.Error != "processing failed" || message.FailedAt == "" ||
+		!reflect.DeepEqual(message.Attributes, map[string]string{"event": "todo.created"}) {
+		t.Errorf("unexpected message: %+v", message)
+	}
+
+	// 檢視不會把訊息藏起來
+	if got := mockSQS.Messages("todo-dlq"); len(got) != 2 || mockSQS.InFlight("todo-dlq") != 0 {
+		t.Errorf("expected messages to stay visible, got %v", got)
+	}
+
+	// 檢視後馬上 redrive 也拿得到全部訊息
+	w, _ = HttpSendAndMarshalBody(http.MethodPost, "/sqs/dlq/redrive", map[string]interface{}{"queue_name": "todo-dlq"}, nil)
+	var redrive modelHttp.RedriveDeadLetterMessagesResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &redrive); err != nil || w.Code != http.StatusOK || redrive.Redriven != 2 {
+		t.Errorf("expected 2 messages redriven after listing, got %d, body=%s", w.Code, w.Body.String())
+	}
+
+	// 不是 dead-letter queue
+	w, _ = HttpGet("/sqs/dlq/messages?queue_name=todo-queue", nil)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "5005") { // SQSNotDeadLetterQueue
+		t.Errorf("expected 400 with 5005, got %d, body=%s", w.Code, w.Body.String())
+	}
+}
+
+func Test_RedriveDeadLetterMessages(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         map[string]interface{}
+		wantRedriven map[string][]string // queue -> bodies
+		wantSkipped  int
+		wantFailed   int
+		wantLeft     []string // todo-dlq 剩下的訊息
+	}{
+		{
+			name: "all messages to their source queue",
+			body: map[string]interface{}{},
+			wantRedriven: map[string][]string{
+				"todo-queue":         {"created-1", "created-2", "updated"},
+				"notification-queue": {"notify"},
+			},
+			wantFailed: 1,
+			wantLeft:   []string{"unknown-source"},
+		},
+		{
+			name: "filtered by attributes and body",
+			body: map[string]interface{}{
+				"attributes":    map[string]string{"event": "todo.created"},
+				"body_contains": "-2",
+			},
+			wantRedriven: map[string][]string{"todo-queue": {"created-2"}},
+			wantSkipped:  4,
+			wantLeft:     []string{"created-1", "notify", "unknown-source", "updated"},
+		},
+		{
+			name: "to another queue",
+			body: map[string]interface{}{
+				"source_queue_name": "notification-queue",
+				"body_contains":     "created",
+			},
+			wantRedriven: map[string][]string{"notification-queue": {"created-1", "created-2"}},
+			wantSkipped:  3,
+			wantLeft:     []string{"notify", "unknown-source", "updated"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSQS := setupDeadLetterQueue(t)
+			deadLetter(mockSQS, "created-1", "todo-queue", map[string]string{"event": "todo.created"})
+			deadLetter(mockSQS, "created-2", "todo-queue", map[string]string{"event": "todo.created"})
+			deadLetter(mockSQS, "updated", "todo-queue", map[string]string{"event": "todo.updated"})
+			deadLetter(mockSQS, "notify", "notification-queue", nil)
+			mockSQS.SendTo("todo-dlq", sqs.OutgoingMessage{Body: "unknown-source"})
+
+			tt.body["queue_name"] = "todo-dlq"
+			w, _ := HttpSendAndMarshalBody(http.MethodPost, "/sqs/dlq/redrive", tt.body, nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+			}
+			var response modelHttp.RedriveDeadLetterMessagesResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to parse response: %v, body=%s", err, w.Body.String())
+			}
+
+			redriven := 0
+			for queueName, want := range tt.wantRedriven {
+				redriven += len(want)
+				if got := mockSQS.Messages(queueName); !reflect.DeepEqual(got, want) {
+					t.Errorf("expected %v in %s, got %v", want, queueName, got)
+				}
+				// 送回原 queue 時移除失敗資訊
+				for _, message := range mockSQS.SentMessages(queueName) {
+					for name := range message.Attributes {
+						if sqs.IsDeadLetterAttribute(name) {
+							t.Errorf("expected no failure metadata, got %+v", message)
+						}
+					}
+				}
+			}
+			if response.Redriven != redriven || response.Skipped != tt.wantSkipped || len(response.Failed) != tt.wantFailed {
+				t.Errorf("unexpected response: %s", w.Body.String())
+			}
+
+			// 沒有送回的訊息馬上可以再收到
+			left := mockSQS.Messages("todo-dlq")
+			sort.Strings(left)
+			if !reflect.DeepEqual(left, tt.wantLeft) || mockSQS.InFlight("todo-dlq") != 0 {
+				t.Errorf("expected %v left in todo-dlq, got %v with %d in flight", tt.wantLeft, left, mockSQS.InFlight("todo-dlq"))
+			}
+		})
+	}
+
+	t.Run("by message id", func(t *testing.T) {
+		mockSQS := setupDeadLetterQueue(t)
+		deadLetter(mockSQS, "first", "todo-queue", nil)
+		messageID := deadLetter(mockSQS, "second", "todo-queue", nil)
+
+		w, _ := HttpSendAndMarshalBody(http.MethodPost, "/sqs/dlq/redrive", map[string]interface{}{
+			"queue_name":  "todo-dlq",
+			"message_ids": []string{messageID},
+		}, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+		}
+		if got := mockSQS.Messages("todo-queue"); !reflect.DeepEqual(got, []string{"second"}) {
+			t.Errorf("expected only the selected message redriven, got %v", got)
+		}
+		if got := mockSQS.Messages("todo-dlq"); !reflect.DeepEqual(got, []string{"first"}) {
+			t.Errorf("expected the other message left, got %v", got)
+		}
+	})
+
+	t.Run("past empty receives", func(t *testing.T) {
+		mockSQS := setupDeadLetterQueue(t)
+		for _, body := range []string{"first", "second", "third"} {
+			deadLetter(mockSQS, body, "todo-queue", nil)
+		}
+		// SQS 取樣部分伺服器時可能回傳空的結果
+		mockSQS.EmptyReceives(2)
+
+		w, _ := HttpSendAndMarshalBody(http.MethodPost, "/sqs/dlq/redrive", map[string]interface{}{
+			"queue_name":   "todo-dlq",
+			"max_messages": 2,
+		}, nil)
+		var response modelHttp.RedriveDeadLetterMessagesResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil || w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+		}
+		if response.Redriven != 2 || response.Remaining == nil || *response.Remaining != 1 {
+			t.Errorf("expected 2 redriven and 1 remaining, got body=%s", w.Body.String())
+		}
+		if got := mockSQS.Messages("todo-queue"); !reflect.DeepEqual(got, []string{"first", "second"}) {
+			t.Errorf("expected the first two messages redriven, got %v", got)
+		}
+	})
+}
+
+func Test_PurgeDeadLetterQueue(t *testing.T) {
+	mockSQS := setupDeadLetterQueue(t)
+	deadLetter(mockSQS, "first", "todo-queue", nil)
+	deadLetter(mockSQS, "second", "todo-queue", nil)
+	dlq, _ := mockSQS.Queue(context.Background(), "todo-dlq")
+	dlq.ReceiveMessages(context.Background(), 1, 0, 30) // 處理中的訊息也一併刪除
+
+	w, _ := HttpDelete("/sqs/dlq/purge?queue_name=todo-dlq", "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+	if got := mockSQS.Messages("todo-dlq"); len(got) != 0 || mockSQS.InFlight("todo-dlq") != 0 {
+		t.Errorf("expected todo-dlq to be empty, got %v", got)
+	}
+
+	// 一般 queue 不能清空
+	mockSQS.SendTo("todo-queue", sqs.OutgoingMessage{Body: "keep"})
+	w, _ = HttpDelete("/sqs/dlq/purge?queue_name=todo-queue", "", nil)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "5005") { // SQSNotDeadLetterQueue
+		t.Errorf("expected 400 with 5005, got %d, body=%s", w.Code, w.Body.String())
+	}
+	if got := mockSQS.Messages("todo-queue"); len(got) != 1 {
+		t.Errorf("expected todo-queue to keep its message, got %v", got)
+	}
+
+	mockSQS.ShouldFail = true
+	w, _ = HttpDelete("/sqs/dlq/purge?queue_name=todo-dlq", "", nil)
+	if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), "5008") { // SQSPurgeQueueFail
+		t.Errorf("expected 500 with 5008, got %d, body=%s", w.Code, w.Body.String())
+	}
+}
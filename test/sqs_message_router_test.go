@@ -0,0 +1,252 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-base/internal/pkg/aws/sqs"
+	"go-base/internal/pkg/dedupe"
+	"go-base/internal/pkg/worker"
+)
+
+type todoCreatedV1 struct {
+	Title string `json:"title"`
+}
+
+type todoCreatedV2 struct {
+	Title    string `json:"title"`
+	Priority int    `json:"priority"`
+}
+
+// envelopeMessage 把 payload 包成 envelope 當作 SQS 訊息
+func envelopeMessage(t *testing.T, messageType string, version int, payload interface{}) sqs.Message {
+	envelope, err := worker.NewEnvelope(messageType, version, payload)
+	if err != nil {
+		t.Fatalf("failed to create envelope: %v", err)
+	}
+	body, err := envelope.Body()
+	if err != nil {
+		t.Fatalf("failed to encode envelope: %v", err)
+	}
+	return sqs.Message{Body: body, MessageID: "message-" + envelope.ID}
+}
+
+func Test_MessageRouter_Routes_By_Type_And_Version(t *testing.T) {
+	router := worker.NewMessageRouter(worker.MessageRouterConfig{})
+	var got []interface{}
+	worker.Handle(router, "todo.created", 1, func(ctx context.Context, payload todoCreatedV1, envelope worker.Envelope) error {
+		got = append(got, payload)
+		return nil
+	})
+	worker.Handle(router, "todo.created", 2, func(ctx context.Context, payload todoCreatedV2, envelope worker.Envelope) error {
+		if envelope.ID == "" || envelope.Timestamp.IsZero() {
+			t.Errorf("unexpected envelope: %+v", envelope)
+		}
+		got = append(got, payload)
+		return nil
+	})
+
+	messages := []sqs.Message{
+		envelopeMessage(t, "todo.created", 1, todoCreatedV1{Title: "first"}),
+		envelopeMessage(t, "todo.created", 2, todoCreatedV2{Title: "second", Priority: 3}),
+		{Body: `{"type": "todo.created", "id": "no-version", "payload": {"title": "third"}}`}, // 沒有 version 視為 1
+	}
+	for _, message := range messages {
+		if err := router.ProcessMessage(context.Background(), message); err != nil {
+			t.Errorf("failed to process %s: %v", message.Body, err)
+		}
+	}
+	want := []interface{}{
+		todoCreatedV1{Title: "first"},
+		todoCreatedV2{Title: "second", Priority: 3},
+		todoCreatedV1{Title: "third"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	// 無法解析的訊息
+	for _, body := range []string{
+		"not json",
+		`{"version": 1, "payload": {}}`,
+		`{"type": "todo.created", "version": 1, "payload": {"title": 1}}`,
+	} {
+		if err := router.ProcessMessage(context.Background(), sqs.Message{Body: body}); !errors.Is(err, worker.ErrMalformedMessage) {
+			t.Errorf("expected ErrMalformedMessage for %s, got %v", body, err)
+		}
+	}
+}
+
+func Test_MessageRouter_Unknown_Types(t *testing.T) {
+	message := envelopeMessage(t, "todo.archived", 1, todoCreatedV1{Title: "unknown"})
+	// 已註冊的 type 但沒有這個 version 也算未知
+	otherVersion := envelopeMessage(t, "todo.created", 3, todoCreatedV1{Title: "unknown"})
+
+	tests := []struct {
+		name        string
+		config      worker.MessageRouterConfig
+		wantErr     bool
+		wantDefault bool
+	}{
+		{
+			name:    "reject",
+			config:  worker.MessageRouterConfig{UnknownType: worker.RejectUnknown},
+			wantErr: true,
+		},
+		{
+			name:   "discard",
+			config: worker.MessageRouterConfig{UnknownType: worker.DiscardUnknown},
+		},
+		{
+			name:        "default handler",
+			config:      worker.MessageRouterConfig{UnknownType: worker.RejectUnknown},
+			wantDefault: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var defaulted []string
+			if tt.wantDefault {
+				tt.config.DefaultHandler = func(ctx context.Context, envelope worker.Envelope, message sqs.Message) error {
+					defaulted = append(defaulted, envelope.Type)
+					return nil
+				}
+			}
+			router := worker.NewMessageRouter(tt.config)
+			worker.Handle(router, "todo.created", 1, func(ctx context.Context, payload todoCreatedV1, envelope worker.Envelope) error {
+				t.Errorf("unexpected call for %s v%d", envelope.Type, envelope.Version)
+				return nil
+			})
+
+			for _, message := range []sqs.Message{message, otherVersion} {
+				err := router.ProcessMessage(context.Background(), message)
+				if (err != nil) != tt.wantErr {
+					t.Errorf("expected error=%v, got %v", tt.wantErr, err)
+				}
+			}
+			if tt.wantDefault && !reflect.DeepEqual(defaulted, []string{"todo.archived", "todo.created"}) {
+				t.Errorf("expected the default handler for both messages, got %v", defaulted)
+			}
+		})
+	}
+}
+
+// metricsRecorder 記錄 MetricsMiddleware 回報的結果
+type metricsRecorder struct {
+	mu      sync.Mutex
+	results []string
+}
+
+func (r *metricsRecorder) RecordMessage(messageType string, version int, duration time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := "ok"
+	if err != nil {
+		result = err.Error()
+	}
+	r.results = append(r.results, messageType+":"+result)
+}
+
+func Test_MessageRouter_Middleware(t *testing.T) {
+	var calls []string
+	trace := func(name string) worker.Middleware {
+		return func(next worker.HandlerFunc) worker.HandlerFunc {
+			return func(ctx context.Context, envelope worker.Envelope, message sqs.Message) error {
+				calls = append(calls, name+" before")
+				err := next(ctx, envelope, message)
+				calls = append(calls, name+" after")
+				return err
+			}
+		}
+	}
+	metrics := &metricsRecorder{}
+	router := worker.NewMessageRouter(worker.MessageRouterConfig{
+		Middleware: []worker.Middleware{trace("outer"), worker.MetricsMiddleware(metrics)},
+	})
+	router.Use(worker.LoggingMiddleware(), worker.RecoveryMiddleware(), trace("inner"))
+	worker.Handle(router, "todo.created", 1, func(ctx context.Context, payload todoCreatedV1, envelope worker.Envelope) error {
+		calls = append(calls, "handler")
+		if payload.Title == "panic" {
+			panic("boom")
+		}
+		return nil
+	})
+
+	if err := router.ProcessMessage(context.Background(), envelopeMessage(t, "todo.created", 1, todoCreatedV1{Title: "ok"})); err != nil {
+		t.Fatalf("failed to process message: %v", err)
+	}
+	want := []string{"outer before", "inner before", "handler", "inner after", "outer after"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("expected %v, got %v", want, calls)
+	}
+
+	// panic 轉成錯誤
+	err := router.ProcessMessage(context.Background(), envelopeMessage(t, "todo.created", 1, todoCreatedV1{Title: "panic"}))
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("expected the panic as an error, got %v", err)
+	}
+	if want := []string{"todo.created:ok", "todo.created:handler panicked: boom"}; !reflect.DeepEqual(metrics.results, want) {
+		t.Errorf("expected metrics %v, got %v", want, metrics.results)
+	}
+
+	// 格式錯誤與未知 type 的訊息也經過 middleware
+	metrics.results = nil
+	if err := router.ProcessMessage(context.Background(), sqs.Message{Body: "not json"}); !errors.Is(err, worker.ErrMalformedMessage) {
+		t.Errorf("expected ErrMalformedMessage, got %v", err)
+	}
+	if err := router.ProcessMessage(context.Background(), envelopeMessage(t, "todo.archived", 1, todoCreatedV1{})); err == nil {
+		t.Error("expected the unknown type to be rejected")
+	}
+	if len(metrics.results) != 2 || !strings.HasPrefix(metrics.results[0], ":malformed message") ||
+		metrics.results[1] != "todo.archived:no handler for todo.archived v1" {
+		t.Errorf("expected the failures in the metrics, got %v", metrics.results)
+	}
+}
+
+func Test_MessageRouter_Dedupe(t *testing.T) {
+	store := dedupe.NewMemoryStore(dedupe.Config{})
+	router := worker.NewMessageRouter(worker.MessageRouterConfig{
+		Middleware: []worker.Middleware{worker.DedupeMiddleware(store)},
+	})
+	handled := map[string]int{}
+	worker.Handle(router, "todo.created", 1, func(ctx context.Context, payload todoCreatedV1, envelope worker.Envelope) error {
+		handled[payload.Title]++
+		if payload.Title == "flaky" && handled[payload.Title] == 1 {
+			return errors.New("temporary failure")
+		}
+		return nil
+	})
+
+	message := envelopeMessage(t, "todo.created", 1, todoCreatedV1{Title: "once"})
+	flaky := envelopeMessage(t, "todo.created", 1, todoCreatedV1{Title: "flaky"})
+	for _, message := range []sqs.Message{message, message, flaky, flaky, flaky} {
+		router.ProcessMessage(context.Background(), message)
+	}
+
+	// 處理失敗的訊息不標記，重送時會再處理一次
+	if want := map[string]int{"once": 1, "flaky": 2}; !reflect.DeepEqual(handled, want) {
+		t.Errorf("expected %v, got %v", want, handled)
+	}
+
+	// 其他 consumer 處理中的訊息延後重送
+	busy := envelopeMessage(t, "todo.created", 1, todoCreatedV1{Title: "busy"})
+	var envelope worker.Envelope
+	if err := json.Unmarshal([]byte(busy.Body), &envelope); err != nil {
+		t.Fatalf("failed to parse envelope: %v", err)
+	}
+	store.Claim(context.Background(), envelope.ID)
+	var release *worker.ReleaseError
+	if err := router.ProcessMessage(context.Background(), busy); !errors.As(err, &release) || !errors.Is(err, dedupe.ErrInProgress) {
+		t.Errorf("expected the message to be released, got %v", err)
+	}
+	if handled["busy"] != 0 {
+		t.Errorf("expected the message not to be handled, got %d", handled["busy"])
+	}
+}
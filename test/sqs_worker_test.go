@@ -0,0 +1,548 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-base/internal/pkg/aws/sqs"
+	"go-base/internal/pkg/dedupe"
+	"go-base/internal/pkg/worker"
+)
+
+// recordingProcessor 記錄收到的訊息、同時處理的數量與已收到未刪除的數量
+type recordingProcessor struct {
+	mu          sync.Mutex
+	delay       time.Duration
+	fail        map[string]bool
+	inFlight    func() int
+	running     int
+	maxActive   int
+	maxInFlight int
+	received    map[string]sqs.Message
+}
+
+func (p *recordingProcessor) ProcessMessage(ctx context.Context, message sqs.Message) error {
+	p.mu.Lock()
+	p.running++
+	p.maxActive = max(p.maxActive, p.running)
+	if p.inFlight != nil {
+		p.maxInFlight = max(p.maxInFlight, p.inFlight())
+	}
+	p.received[message.Body] = message
+	p.mu.Unlock()
+
+	time.Sleep(p.delay)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.running--
+	if p.fail[message.Body] {
+		return errors.New("processing failed")
+	}
+	return nil
+}
+
+func (p *recordingProcessor) receivedCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.received)
+}
+
+func Test_SQSWorker_Processes_Batches_Concurrently(t *testing.T) {
+	mockSQS := &sqs.MockSQSAPI{QueueNames: []string{"worker-queue"}}
+	queue, _ := mockSQS.Queue(context.Background(), "worker-queue")
+
+	messages := make([]sqs.OutgoingMessage, 25)
+	for i := range messages {
+		messages[i] = sqs.OutgoingMessage{Body: "message-" + strconv.Itoa(i), Attributes: map[string]string{"index": strconv.Itoa(i)}}
+	}
+	for _, result := range queue.SendMessageBatch(context.Background(), messages) {
+		if result.Err != nil {
+			t.Fatalf("failed to send message: %v", result.Err)
+		}
+	}
+
+	processor := &recordingProcessor{
+		delay:    20 * time.Millisecond,
+		fail:     map[string]bool{"message-3": true},
+		inFlight: func() int { return mockSQS.InFlight("worker-queue") },
+		received: map[string]sqs.Message{},
+	}
+	sqsWorker, err := worker.NewSQSWorker(worker.SQSWorkerConfig{
+		QueueName:   "worker-queue",
+		Queue:       queue,
+		Processor:   processor,
+		MaxRetries:  1,
+		WorkerCount: 2,
+		Concurrency: 4,
+	})
+	if err != nil {
+		t.Fatalf("failed to create worker: %v", err)
+	}
+
+	sqsWorker.Start(context.Background())
+	deadline := time.Now().Add(5 * time.Second)
+	for processor.receivedCount() < len(messages) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	sqsWorker.Stop() // 等待處理中的批次刪除完成
+
+	if got := processor.receivedCount(); got != len(messages) {
+		t.Fatalf("expected %d messages to be processed, got %d", len(messages), got)
+	}
+	if processor.maxActive > 4 || processor.maxActive < 2 {
+		t.Errorf("expected between 2 and 4 messages processed at the same time, got %d", processor.maxActive)
+	}
+
+	message := processor.received["message-7"]
+	if message.MessageID == "" || message.ReceiveCount != 1 || message.SentTimestamp.IsZero() || message.Attributes["index"] != "7" {
+		t.Errorf("unexpected message: %+v", message)
+	}
+
+	// 處理失敗的訊息不刪除，等 visibility timeout 後重新出現
+	if got := mockSQS.InFlight("worker-queue"); got != 1 {
+		t.Errorf("expected only the failed message in flight, got %d", got)
+	}
+	if got := mockSQS.Messages("worker-queue"); len(got) != 0 {
+		t.Errorf("expected every message to be received, left %v", got)
+	}
+	// 只收取有空位處理的訊息，失敗的那一則之外不會多收
+	if processor.maxInFlight > 4+1 {
+		t.Errorf("expected at most 4 messages received besides the failed one, got %d", processor.maxInFlight)
+	}
+}
+
+func Test_SQSWorker_Deletes_Each_Message_When_Done(t *testing.T) {
+	mockSQS := &sqs.MockSQSAPI{QueueNames: []string{"worker-queue"}}
+	queue, _ := mockSQS.Queue(context.Background(), "worker-queue")
+	for _, result := range queue.SendMessageBatch(context.Background(), []sqs.OutgoingMessage{{Body: "fast"}, {Body: "slow"}}) {
+		if result.Err != nil {
+			t.Fatalf("failed to send message: %v", result.Err)
+		}
+	}
+
+	// slow 在 fast 被刪除之後才結束，不等整批處理完
+	fastDeleted := make(chan struct{})
+	sqsWorker, err := worker.NewSQSWorker(worker.SQSWorkerConfig{
+		QueueName: "worker-queue",
+		Queue:     queue,
+		Processor: processorFunc(func(ctx context.Context, message sqs.Message) error {
+			if message.Body == "fast" {
+				return nil
+			}
+			deadline := time.Now().Add(2 * time.Second)
+			for mockSQS.InFlight("worker-queue") > 1 && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			if mockSQS.InFlight("worker-queue") == 1 {
+				close(fastDeleted)
+			}
+			return nil
+		}),
+		MaxRetries:  1,
+		Concurrency: 2,
+	})
+	if err != nil {
+		t.Fatalf("failed to create worker: %v", err)
+	}
+
+	sqsWorker.Start(context.Background())
+	defer sqsWorker.Stop() // 清理
+	select {
+	case <-fastDeleted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected fast to be deleted while slow was still processed")
+	}
+}
+
+// processorFunc 讓測試直接以函式當作 MessageProcessor
+type processorFunc func(ctx context.Context, message sqs.Message) error
+
+func (f processorFunc) ProcessMessage(ctx context.Context, message sqs.Message) error {
+	return f(ctx, message)
+}
+
+func Test_SQSWorker_Visibility(t *testing.T) {
+	tests := []struct {
+		name string
+		// process 為處理邏輯，觀察到 ctx 被取消時關閉 cancelled
+		process        func(ctx context.Context, cancelled chan<- struct{}) error
+		failExtensions bool
+		wantDeleted    bool
+		wantCancelled  bool
+		wantChanges    func(changes []sqs.MockVisibilityChange) bool
+	}{
+		{
+			name: "heartbeat extends long running messages",
+			process: func(ctx context.Context, cancelled chan<- struct{}) error {
+				time.Sleep(200 * time.Millisecond)
+				return nil
+			},
+			wantDeleted: true,
+			wantChanges: func(changes []sqs.MockVisibilityChange) bool {
+				for _, change := range changes {
+					if change.VisibilityTimeout != 60 {
+						return false
+					}
+				}
+				return len(changes) >= 3
+			},
+		},
+		{
+			name: "failed extension cancels processing",
+			process: func(ctx context.Context, cancelled chan<- struct{}) error {
+				select {
+				case <-ctx.Done():
+					close(cancelled)
+					return ctx.Err()
+				case <-time.After(2 * time.Second):
+					return nil
+				}
+			},
+			failExtensions: true,
+			wantCancelled:  true,
+			wantChanges: func(changes []sqs.MockVisibilityChange) bool {
+				return len(changes) == 0
+			},
+		},
+		{
+			name: "release with delay",
+			process: func(ctx context.Context, cancelled chan<- struct{}) error {
+				return worker.Release(30*time.Second, errors.New("vendor unavailable"))
+			},
+			wantChanges: func(changes []sqs.MockVisibilityChange) bool {
+				return len(changes) == 1 && changes[0].VisibilityTimeout == 30
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSQS := &sqs.MockSQSAPI{QueueNames: []string{"worker-queue"}}
+			queue, _ := mockSQS.Queue(context.Background(), "worker-queue")
+			if _, err := queue.SendMessage(context.Background(), sqs.OutgoingMessage{Body: "slow"}); err != nil {
+				t.Fatalf("failed to send message: %v", err)
+			}
+			if tt.failExtensions {
+				mockSQS.FailVisibilityChanges(errors.New("receipt handle has expired"))
+			}
+
+			var mu sync.Mutex
+			attempts := 0
+			done := make(chan struct{})
+			cancelled := make(chan struct{})
+			sqsWorker, err := worker.NewSQSWorker(worker.SQSWorkerConfig{
+				QueueName: "worker-queue",
+				Queue:     queue,
+				Processor: processorFunc(func(ctx context.Context, message sqs.Message) error {
+					mu.Lock()
+					attempts++
+					mu.Unlock()
+					defer close(done)
+					return tt.process(ctx, cancelled)
+				}),
+				MaxRetries:        3,
+				VisibilityTimeout: 60,
+				HeartbeatInterval: 40 * time.Millisecond,
+			})
+			if err != nil {
+				t.Fatalf("failed to create worker: %v", err)
+			}
+
+			sqsWorker.Start(context.Background())
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("expected the message to be processed")
+			}
+			sqsWorker.Stop()
+
+			mu.Lock()
+			defer mu.Unlock()
+			if attempts != 1 {
+				t.Errorf("expected a single attempt, got %d", attempts)
+			}
+			select {
+			case <-cancelled:
+				if !tt.wantCancelled {
+					t.Error("expected processing not to be cancelled")
+				}
+			default:
+				if tt.wantCancelled {
+					t.Error("expected processing to be cancelled")
+				}
+			}
+			if deleted := mockSQS.InFlight("worker-queue") == 0; deleted != tt.wantDeleted {
+				t.Errorf("expected deleted=%v, got %v", tt.wantDeleted, deleted)
+			}
+			if changes := mockSQS.VisibilityChanges("worker-queue"); !tt.wantChanges(changes) {
+				t.Errorf("unexpected visibility changes: %+v", changes)
+			}
+		})
+	}
+}
+
+func Test_SQSWorker_Dead_Letter_Queue(t *testing.T) {
+	tests := []struct {
+		name            string
+		peeks           int // 先收幾次再放回，增加 ReceiveCount
+		maxReceiveCount int
+		processErr      error
+		maxRetries      int // 預設 1
+		failDeadLetter  bool
+		wantProcessed   bool
+		wantAttempts    int    // 0 表示不檢查
+		wantError       string // 移到 DLQ 時記錄的錯誤，空字串表示留在原 queue
+	}{
+		{
+			name:            "failed on the last delivery",
+			maxReceiveCount: 1,
+			processErr:      errors.New("corrupt image"),
+			wantProcessed:   true,
+			wantError:       "corrupt image",
+		},
+		{
+			name:            "failed before the last delivery",
+			maxReceiveCount: 2,
+			processErr:      errors.New("corrupt image"),
+			wantProcessed:   true,
+		},
+		{
+			name:            "malformed message is moved on the first delivery without retries",
+			maxReceiveCount: 5,
+			processErr:      fmt.Errorf("%w: type is required", worker.ErrMalformedMessage),
+			maxRetries:      3,
+			wantProcessed:   true,
+			wantAttempts:    1,
+			wantError:       "malformed message",
+		},
+		{
+			name:            "poison message is not processed",
+			peeks:           2,
+			maxReceiveCount: 2,
+			wantError:       "received 3 times, more than 2",
+		},
+		{
+			name:            "released messages are never moved",
+			maxReceiveCount: 1,
+			processErr:      worker.Release(time.Minute, errors.New("vendor unavailable")),
+			wantProcessed:   true,
+		},
+		{
+			name:            "stays in the queue when the move fails",
+			maxReceiveCount: 1,
+			processErr:      errors.New("corrupt image"),
+			failDeadLetter:  true,
+			wantProcessed:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockSQS := &sqs.MockSQSAPI{QueueNames: []string{"worker-queue", "worker-dlq"}}
+			queue, _ := mockSQS.Queue(ctx, "worker-queue")
+			deadLetterQueue, _ := mockSQS.Queue(ctx, "worker-dlq")
+			if _, err := queue.SendMessage(ctx, sqs.OutgoingMessage{Body: "icon", Attributes: map[string]string{"event": "icon.created"}}); err != nil {
+				t.Fatalf("failed to send message: %v", err)
+			}
+			for i := 0; i < tt.peeks; i++ {
+				// 收到後馬上放回
+				messages, _ := queue.ReceiveMessages(ctx, 1, 0, 30)
+				queue.ChangeMessageVisibility(ctx, messages[0].ReceiptHandle, 0)
+			}
+			if tt.failDeadLetter {
+				// 第一次送到原 queue 已完成，之後送往 DLQ 都失敗
+				mockSQS.FailMessage("icon", errors.New("dead-letter queue unavailable"), 0)
+			}
+
+			var mu sync.Mutex
+			attempts := 0
+			sqsWorker, err := worker.NewSQSWorker(worker.SQSWorkerConfig{
+				QueueName: "worker-queue",
+				Queue:     queue,
+				Processor: processorFunc(func(ctx context.Context, message sqs.Message) error {
+					mu.Lock()
+					defer mu.Unlock()
+					attempts++
+					return tt.processErr
+				}),
+				MaxRetries:      max(1, tt.maxRetries),
+				DeadLetterQueue: deadLetterQueue,
+				MaxReceiveCount: tt.maxReceiveCount,
+			})
+			if err != nil {
+				t.Fatalf("failed to create worker: %v", err)
+			}
+
+			sqsWorker.Start(ctx)
+			deadline := time.Now().Add(5 * time.Second)
+			for len(mockSQS.Messages("worker-queue")) > 0 && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			sqsWorker.Stop() // 等待收到的批次處理完成
+
+			mu.Lock()
+			defer mu.Unlock()
+			if processed := attempts > 0; processed != tt.wantProcessed {
+				t.Errorf("expected processed=%v, got %v", tt.wantProcessed, processed)
+			}
+			if tt.wantAttempts > 0 && attempts != tt.wantAttempts {
+				t.Errorf("expected %d attempts, got %d", tt.wantAttempts, attempts)
+			}
+
+			deadLetters := mockSQS.SentMessages("worker-dlq")
+			if tt.wantError == "" {
+				if len(deadLetters) != 0 || mockSQS.InFlight("worker-queue") != 1 {
+					t.Errorf("expected the message to stay in the queue, dead letters: %+v", deadLetters)
+				}
+				return
+			}
+			if len(deadLetters) != 1 || mockSQS.InFlight("worker-queue") != 0 {
+				t.Fatalf("expected the message to be moved, dead letters: %+v", deadLetters)
+			}
+			attributes := deadLetters[0].Attributes
+			if deadLetters[0].Body != "icon" || attributes["event"] != "icon.created" ||
+				attributes[sqs.DeadLetterSourceQueueAttribute] != "worker-queue" ||
+				!strings.Contains(attributes[sqs.DeadLetterErrorAttribute], tt.wantError) ||
+				attributes[sqs.DeadLetterReceiveCountAttribute] != strconv.Itoa(tt.peeks+1) ||
+				attributes[sqs.DeadLetterFailedAtAttribute] == "" {
+				t.Errorf("unexpected dead letter: %+v", deadLetters[0])
+			}
+		})
+	}
+}
+
+func Test_SQSWorker_Shutdown(t *testing.T) {
+	tests := []struct {
+		name        string
+		processTime time.Duration
+		deadline    time.Duration
+		wantErr     error
+		wantDeleted bool
+	}{
+		{
+			name:        "drains the messages being processed",
+			processTime: 100 * time.Millisecond,
+			deadline:    5 * time.Second,
+			wantDeleted: true,
+		},
+		{
+			name:        "cancels them after the deadline",
+			processTime: 5 * time.Second,
+			deadline:    100 * time.Millisecond,
+			wantErr:     context.DeadlineExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockSQS := &sqs.MockSQSAPI{QueueNames: []string{"worker-queue", "worker-dlq"}}
+			queue, _ := mockSQS.Queue(ctx, "worker-queue")
+			deadLetterQueue, _ := mockSQS.Queue(ctx, "worker-dlq")
+			if _, err := queue.SendMessage(ctx, sqs.OutgoingMessage{Body: "slow"}); err != nil {
+				t.Fatalf("failed to send message: %v", err)
+			}
+
+			started := make(chan struct{})
+			sqsWorker, err := worker.NewSQSWorker(worker.SQSWorkerConfig{
+				QueueName: "worker-queue",
+				Queue:     queue,
+				Processor: processorFunc(func(ctx context.Context, message sqs.Message) error {
+					close(started)
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case <-time.After(tt.processTime):
+						return nil
+					}
+				}),
+				MaxRetries: 1,
+				// 被取消的訊息不應該移到 DLQ
+				DeadLetterQueue: deadLetterQueue,
+				MaxReceiveCount: 1,
+			})
+			if err != nil {
+				t.Fatalf("failed to create worker: %v", err)
+			}
+
+			sqsWorker.Start(ctx)
+			select {
+			case <-started:
+			case <-time.After(5 * time.Second):
+				t.Fatal("expected the message to be processed")
+			}
+
+			shutdownCtx, cancel := context.WithTimeout(ctx, tt.deadline)
+			defer cancel()
+			start := time.Now()
+			if err := sqsWorker.Shutdown(shutdownCtx); !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("expected shutdown within the deadline, took %s", elapsed)
+			}
+			if sqsWorker.IsRunning() {
+				t.Error("expected the worker to be stopped")
+			}
+
+			if deleted := mockSQS.InFlight("worker-queue") == 0; deleted != tt.wantDeleted {
+				t.Errorf("expected deleted=%v, got %v", tt.wantDeleted, deleted)
+			}
+			if got := mockSQS.Messages("worker-dlq"); len(got) != 0 {
+				t.Errorf("expected nothing dead-lettered, got %v", got)
+			}
+		})
+	}
+}
+
+func Test_SQSWorker_Dedupe(t *testing.T) {
+	ctx := context.Background()
+	mockSQS := &sqs.MockSQSAPI{QueueNames: []string{"worker-queue"}}
+	queue, _ := mockSQS.Queue(ctx, "worker-queue")
+	for _, body := range []string{"handled", "new"} {
+		if _, err := queue.SendMessage(ctx, sqs.OutgoingMessage{Body: body}); err != nil {
+			t.Fatalf("failed to send message: %v", err)
+		}
+	}
+
+	// 模擬 handled 已被處理過後又重送，MessageId 不變
+	store := dedupe.NewMemoryStore(dedupe.Config{})
+	messages, _ := queue.ReceiveMessages(ctx, 1, 0, 30)
+	store.Claim(ctx, messages[0].MessageID)
+	store.Complete(ctx, messages[0].MessageID)
+	queue.ChangeMessageVisibility(ctx, messages[0].ReceiptHandle, 0)
+
+	processor := &recordingProcessor{received: map[string]sqs.Message{}}
+	sqsWorker, err := worker.NewSQSWorker(worker.SQSWorkerConfig{
+		QueueName:   "worker-queue",
+		Queue:       queue,
+		Processor:   processor,
+		DedupeStore: store,
+	})
+	if err != nil {
+		t.Fatalf("failed to create worker: %v", err)
+	}
+
+	sqsWorker.Start(ctx)
+	deadline := time.Now().Add(5 * time.Second)
+	for (len(mockSQS.Messages("worker-queue")) > 0 || mockSQS.InFlight("worker-queue") > 0) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	sqsWorker.Stop()
+
+	// 已處理過的訊息直接刪除，不再處理
+	if _, ok := processor.received["handled"]; ok || processor.receivedCount() != 1 {
+		t.Errorf("expected only the new message processed, got %v", processor.received)
+	}
+	if mockSQS.InFlight("worker-queue") != 0 || len(mockSQS.Messages("worker-queue")) != 0 {
+		t.Errorf("expected both messages deleted")
+	}
+}
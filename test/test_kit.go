@@ -22,12 +22,17 @@ func HttpPost(path string, body string, headers map[string]string) (resp *httpte
 }
 
 func HttpPostAndMarshalBody(path string, body interface{}, headers map[string]string) (resp *httptest.ResponseRecorder, err error) {
+	resp, err = HttpSendAndMarshalBody("POST", path, body, headers)
+	return
+}
+
+func HttpSendAndMarshalBody(method string, path string, body interface{}, headers map[string]string) (resp *httptest.ResponseRecorder, err error) {
 	bodyInByte, err := json.Marshal(body)
 	if err != nil {
 		return
 	}
 
-	resp, err = sendHttp("POST", path, string(bodyInByte), headers)
+	resp, err = sendHttp(method, path, string(bodyInByte), headers)
 	return
 }
 